@@ -1,12 +1,125 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"text/tabwriter"
 
 	paseto "aidanwoods.dev/go-paseto"
+	"github.com/hfleury/bk_globalshot/pkg/token"
 )
 
+const usage = `Usage: paseto [-file keyring.json] <command> [arguments]
+
+Without a command a standalone key pair is printed, for TOKEN_PRIVATE_KEY.
+
+Commands managing the key ring the server reads from TOKEN_KEYRING_FILE:
+  generate [-inactive]  add a key and sign new tokens with it, -inactive only adds it
+  import [private-key]  add an existing hex key, TOKEN_PRIVATE_KEY by default
+  activate <kid>        sign new tokens with a key of the ring
+  list                  show the keys of the ring
+  retire <kid>          stop accepting tokens signed with a key
+
+Rotating: generate a key, restart the servers, and retire the previous key once
+the access tokens it signed have expired (TOKEN_EXPIRY).
+`
+
 func main() {
+	defaultFile := os.Getenv("TOKEN_KEYRING_FILE")
+	if defaultFile == "" {
+		defaultFile = "keyring.json"
+	}
+	file := flag.String("file", defaultFile, "key ring file")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		printKeyPair()
+		return
+	}
+
+	if err := run(*file, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(file, command string, args []string) error {
+	ring, err := token.LoadKeyRing(file)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "generate":
+		flags := flag.NewFlagSet("generate", flag.ExitOnError)
+		inactive := flags.Bool("inactive", false, "add the key without signing with it yet")
+		flags.Parse(args)
+
+		key := ring.Generate(!*inactive || ring.ActiveID == "")
+		if err := ring.Save(file); err != nil {
+			return err
+		}
+		fmt.Printf("Generated key %s\n", key.ID)
+	case "import":
+		privateKey := os.Getenv("TOKEN_PRIVATE_KEY")
+		if len(args) > 0 {
+			privateKey = args[0]
+		}
+		if privateKey == "" {
+			return fmt.Errorf("no private key given and TOKEN_PRIVATE_KEY is not set")
+		}
+		key, err := ring.Import(privateKey)
+		if err != nil {
+			return err
+		}
+		if err := ring.Save(file); err != nil {
+			return err
+		}
+		fmt.Printf("Imported key %s\n", key.ID)
+	case "activate", "retire":
+		if len(args) != 1 {
+			return fmt.Errorf("%s expects a key id", command)
+		}
+		apply := ring.Activate
+		if command == "retire" {
+			apply = ring.Retire
+		}
+		if err := apply(args[0]); err != nil {
+			return err
+		}
+		if err := ring.Save(file); err != nil {
+			return err
+		}
+		fmt.Printf("Key %s %sd, restart the servers to apply\n", args[0], command)
+	case "list":
+		listKeys(ring)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+	return nil
+}
+
+func listKeys(ring *token.KeyRing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tSTATUS\tCREATED\tRETIRED")
+	for _, key := range ring.Keys {
+		status, retired := "verify", "-"
+		switch {
+		case key.ID == ring.ActiveID:
+			status = "active"
+		case key.IsRetired():
+			status = "retired"
+			retired = key.RetiredAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.ID, status, key.CreatedAt.Format("2006-01-02 15:04"), retired)
+	}
+	w.Flush()
+}
+
+func printKeyPair() {
 	// Generate new V4 secret key
 	secretKey := paseto.NewV4AsymmetricSecretKey()
 
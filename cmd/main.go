@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
 	"github.com/hfleury/bk_globalshot/internal/repository/psql"
 	"github.com/hfleury/bk_globalshot/internal/router"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 	"github.com/hfleury/bk_globalshot/pkg/config"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/mailer"
+	"github.com/hfleury/bk_globalshot/pkg/oidc"
+	"github.com/hfleury/bk_globalshot/pkg/ratelimit"
+	"github.com/hfleury/bk_globalshot/pkg/secretbox"
+	"github.com/hfleury/bk_globalshot/pkg/storage"
 	"github.com/hfleury/bk_globalshot/pkg/token"
 )
 
@@ -21,25 +28,74 @@ func main() {
 		panic(err)
 	}
 
-	pasetoMaker, err := token.NewPasetoMaker(cfg.CfgToken.TokenKey)
+	pasetoMaker, err := token.New(cfg.CfgToken)
 	if err != nil {
 		panic(err)
 	}
 
+	blobStorage, err := storage.New(cfg.CfgStorage)
+	if err != nil {
+		panic(err)
+	}
+
+	mail, err := mailer.New(cfg.CfgMailer)
+	if err != nil {
+		panic(err)
+	}
+
+	limiter, err := ratelimit.New(cfg.CfgRateLimit, dbPsql)
+	if err != nil {
+		panic(err)
+	}
+	if pgLimiter, ok := limiter.(*ratelimit.PostgresLimiter); ok {
+		pgLimiter.Start(context.Background(), time.Hour)
+	}
+
 	// Init repositories
 	userRepo := psql.NewPostgresUserRepository(dbPsql)
 	companyRepo := psql.NewPostgresCompanyRepository(dbPsql)
 	roomRepo := psql.NewPostgresRoomRepository(dbPsql)
 	siteRepo := psql.NewSiteRepository(dbPsql)
 	unitRepo := psql.NewUnitRepository(dbPsql)
+	mediaRepo := psql.NewMediaRepository(dbPsql)
+	authSessionRepo := psql.NewAuthSessionRepository(dbPsql)
+	passwordResetRepo := psql.NewPasswordResetRepository(dbPsql)
+	tenantRepo := psql.NewTenantRepository(dbPsql)
+	invitationRepo := psql.NewInvitationRepository(dbPsql)
+	mfaRepo := psql.NewMFARepository(dbPsql)
+	loginLockoutRepo := psql.NewLoginLockoutRepository(dbPsql)
+	apiKeyRepo := psql.NewAPIKeyRepository(dbPsql)
+	ssoRepo := psql.NewSSORepository(dbPsql)
+	impersonationRepo := psql.NewImpersonationRepository(dbPsql)
+	auditRepo := psql.NewAuditRepository(dbPsql)
+	searchRepo := psql.NewSearchRepository(dbPsql)
+	trashRepo := psql.NewTrashRepository(dbPsql)
 
 	// Initi servies
-	authService := service.NewAuthService(userRepo, pasetoMaker, &cfg.CfgToken)
-	companyService := service.NewCompanyService(dbPsql, companyRepo, userRepo)
-	roomService := service.NewRoomService(roomRepo)
-	siteService := service.NewSiteService(dbPsql, siteRepo)
-	unitService := service.NewUnitService(dbPsql, unitRepo)
-	userService := service.NewUserService(userRepo)
+	authorizer := service.NewAuthorizer(authz.Default, tenantRepo)
+	auditService := service.NewAuditService(auditRepo, tenantRepo, authorizer)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, &cfg.CfgMFA)
+	authService := service.NewAuthService(userRepo, authSessionRepo, passwordResetRepo, mfaRepo, mfaService, loginLockoutRepo, pasetoMaker, mail, &cfg.CfgToken, &cfg.CfgMFA, &cfg.CfgRateLimit)
+	invitationService := service.NewInvitationService(dbPsql, invitationRepo, userRepo, unitRepo, tenantRepo, mail, &cfg.CfgToken, authorizer, auditService)
+	companyService := service.NewCompanyService(dbPsql, companyRepo, userRepo, invitationService, authorizer, auditService)
+	roomService := service.NewRoomService(roomRepo, authorizer, auditService)
+	siteService := service.NewSiteService(dbPsql, siteRepo, authorizer, auditService)
+	unitService := service.NewUnitService(dbPsql, unitRepo, userRepo, tenantRepo, authorizer, auditService)
+	userService := service.NewUserService(userRepo, authorizer, auditService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, companyRepo, authorizer, authz.Default)
+	impersonationService := service.NewImpersonationService(impersonationRepo, userRepo, authSessionRepo, pasetoMaker, authorizer, &cfg.CfgToken)
+	oidcSecrets, err := secretbox.New(cfg.CfgOIDC.SecretKey)
+	if err != nil {
+		panic(err)
+	}
+	ssoService := service.NewSSOService(dbPsql, ssoRepo, companyRepo, userRepo, authService, authorizer, auditService, &cfg.CfgOIDC, oidcSecrets, oidc.NewHTTPClient(cfg.CfgOIDC.HTTPTimeout))
+	accountService := service.NewAccountService(userRepo, authSessionRepo, loginLockoutRepo, authz.Default, auditService, &cfg.CfgRateLimit)
+	mediaProcessor := service.NewMediaProcessor(mediaRepo, blobStorage, &cfg.CfgMedia)
+	mediaProcessor.Start(context.Background())
+	searchService := service.NewSearchService(searchRepo, authorizer)
+	trashService := service.NewTrashService(trashRepo, blobStorage, authorizer, auditService, &cfg.CfgTrash)
+	trashService.Start(context.Background())
+	mediaService := service.NewMediaService(mediaRepo, roomRepo, blobStorage, mediaProcessor, authorizer, auditService, &cfg.CfgMedia)
 	dbHealthService := service.NewDBHealthService(func(ctx context.Context) error {
 		return dbPsql.PingContext(ctx)
 	})
@@ -51,12 +107,25 @@ func main() {
 	siteHandler := handler.NewSiteHandler(siteService)
 	unitHandler := handler.NewUnitHandler(unitService)
 	userHandler := handler.NewUserHandler(userService)
+	mediaHandler := handler.NewMediaHandler(mediaService, cfg.CfgMedia.MaxUploadSize)
+	meHandler := handler.NewMeHandler(accountService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	ssoHandler := handler.NewSSOHandler(ssoService)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	trashHandler := handler.NewTrashHandler(trashService)
 	healthHandler := handler.NewHealthHandler(dbHealthService)
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
 
 	router := router.NewRouter(r)
-	router.SetupRouter(authHandler, healthHandler, companyHandler, roomHandler, siteHandler, unitHandler, userHandler, pasetoMaker)
+	router.SetupRouter(authHandler, healthHandler, companyHandler, roomHandler, siteHandler, unitHandler, userHandler, mediaHandler, meHandler, mfaHandler, invitationHandler, apiKeyHandler, ssoHandler, impersonationHandler, auditHandler, searchHandler, trashHandler, pasetoMaker, authService, apiKeyService, limiter, &cfg.CfgRateLimit)
 
 	port := cfg.ServerPort
 	if port == "" {
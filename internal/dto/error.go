@@ -10,16 +10,18 @@ type ErrorResponse struct {
 type ErrorCode string
 
 const (
-	ErrorCodeRequiredField     ErrorCode = "REQUIRED_FIELD"
-	ErrorCodeInvalidFormat     ErrorCode = "INVALID_FORMAT"
-	ErrorCodeUnauthorized      ErrorCode = "UNAUTHORIZED"
-	ErrorCodeForbidden         ErrorCode = "FORBIDDEN"
-	ErrorCodeNotFound          ErrorCode = "NOT_FOUND"
-	ErrorCodeValidationFailed  ErrorCode = "VALIDATION_FAILED"
-	ErrorCodeInternalServer    ErrorCode = "INTERNAL_SERVER_ERROR"
-	ErrorCodeDuplicateEntry    ErrorCode = "DUPLICATE_ENTRY"
-	ErrorCodeRateLimitExceeded ErrorCode = "RATE_LIMIT_EXCEEDED"
-	ErrorCodeTimeout           ErrorCode = "TIMEOUT"
+	ErrorCodeRequiredField      ErrorCode = "REQUIRED_FIELD"
+	ErrorCodeInvalidFormat      ErrorCode = "INVALID_FORMAT"
+	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrorCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrorCodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeInternalServer     ErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrorCodeDuplicateEntry     ErrorCode = "DUPLICATE_ENTRY"
+	ErrorCodeRateLimitExceeded  ErrorCode = "RATE_LIMIT_EXCEEDED"
+	ErrorCodeTimeout            ErrorCode = "TIMEOUT"
+	ErrorCodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+	ErrorCodeInvalidReference   ErrorCode = "INVALID_REFERENCE"
 )
 
 func (ec ErrorCode) DefaultMessage() string {
@@ -44,6 +46,10 @@ func (ec ErrorCode) DefaultMessage() string {
 		return "Too many requests. Please try again later."
 	case ErrorCodeTimeout:
 		return "The request timed out."
+	case ErrorCodePreconditionFailed:
+		return "The resource was modified since it was read."
+	case ErrorCodeInvalidReference:
+		return "The referenced record does not exist."
 	default:
 		return "An unexpected error occurred."
 	}
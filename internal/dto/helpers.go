@@ -9,6 +9,13 @@ func ResponseSuccess(message string, data interface{}) Response {
 	}
 }
 
+// ResponseSuccessWithMeta is ResponseSuccess for a page of a listing paged with cursors.
+func ResponseSuccessWithMeta(message string, data interface{}, meta *Meta) Response {
+	response := ResponseSuccess(message, data)
+	response.Meta = meta
+	return response
+}
+
 func ResponseError(message string, errors []ErrorResponse) Response {
 	return Response{
 		Success: false,
@@ -19,14 +26,21 @@ func ResponseError(message string, errors []ErrorResponse) Response {
 }
 
 func ValidationError(field, message string, code ErrorCode) Response {
-	return ResponseError("Validation failed.", []ErrorResponse{
-		{
-			Type:    "validation_error",
-			Field:   field,
-			Message: message,
-			Code:    code,
-		},
-	})
+	return ValidationErrors([]ErrorResponse{FieldError(field, message, code)})
+}
+
+// ValidationErrors reports every field of the input that failed at once.
+func ValidationErrors(errors []ErrorResponse) Response {
+	return ResponseError("Validation failed.", errors)
+}
+
+func FieldError(field, message string, code ErrorCode) ErrorResponse {
+	return ErrorResponse{
+		Type:    "validation_error",
+		Field:   field,
+		Message: message,
+		Code:    code,
+	}
 }
 
 func UnauthorizedResponse(message string) Response {
@@ -78,3 +92,29 @@ func FromError(err error) Response {
 		},
 	})
 }
+
+func TooManyRequestsResponse(message string) Response {
+	if message == "" {
+		message = ErrorCodeRateLimitExceeded.DefaultMessage()
+	}
+	return ResponseError(message, []ErrorResponse{
+		{
+			Type:    "rate_limit_error",
+			Message: message,
+			Code:    ErrorCodeRateLimitExceeded,
+		},
+	})
+}
+
+func PreconditionFailedResponse(message string) Response {
+	if message == "" {
+		message = ErrorCodePreconditionFailed.DefaultMessage()
+	}
+	return ResponseError(message, []ErrorResponse{
+		{
+			Type:    "conflict_error",
+			Message: message,
+			Code:    ErrorCodePreconditionFailed,
+		},
+	})
+}
@@ -3,6 +3,14 @@ package dto
 type Response struct {
 	Success bool            `json:"success"`
 	Message string          `json:"message"`
-	Data    any             `json:"data"`
+	Data    any             `json:"data,omitempty"`
+	Meta    *Meta           `json:"meta,omitempty"`
 	Errors  []ErrorResponse `json:"error,omitempty"`
 }
+
+// Meta describes a page of a listing paged with cursors. NextCursor is null on the last page,
+// Total is only there when the count was asked for.
+type Meta struct {
+	NextCursor *string `json:"next_cursor"`
+	Total      *int64  `json:"total,omitempty"`
+}
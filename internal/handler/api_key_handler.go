@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyHandler(service service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	CompanyID string     `json:"company_id"` // Required from admins, company users issue keys for their own company
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional, the key never expires without it
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(c.Request.Context(), req.Name, req.CompanyID, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrInvalidAPIKeyScope):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("scopes", "Scopes must be permissions a company user holds", dto.ErrorCodeInvalidFormat))
+		case errors.Is(err, service.ErrInvalidAPIKeyExpiry):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("expires_at", "Expiry must be in the future", dto.ErrorCodeInvalidFormat))
+		case errors.Is(err, service.ErrInvalidAPIKeyCompany):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("company_id", "Company does not exist", dto.ErrorCodeValidationFailed))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseSuccess("API key created, store it safely, it is not shown again", key))
+}
+
+func (h *APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
+
+	keys, total, err := h.service.GetAllAPIKeys(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.Header("Content-Range", query.ContentRange("api-keys", len(keys), total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("API keys retrieved successfully", keys))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, err := h.service.RevokeAPIKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrAPIKeyRevoked):
+			c.JSON(http.StatusConflict, dto.ResponseError("API key was already revoked", nil))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+	if key == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("API key not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("API key revoked successfully", key))
+}
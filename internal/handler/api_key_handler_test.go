@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyMocks struct {
+	keys      *mock_repository.MockAPIKeyRepository
+	companies *mock_repository.MockCompanyRepository
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+	revokedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		targetID       string
+		call           func(h *APIKeyHandler, c *gin.Context)
+		setupRepos     func(m apiKeyMocks)
+		expectedStatus int
+		checkBody      func(t *testing.T, body string)
+	}{
+		{
+			name:   "Company user creating a key for own company - Created",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Drone pipeline","scopes":["media:upload","room:read","media:upload"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+				m.companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(&model.Company{ID: "company-123"}, nil)
+				m.keys.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, k *model.APIKey) error {
+					assert.Equal(t, "company-123", k.CompanyID)
+					assert.Equal(t, "user-1", *k.CreatedBy)
+					assert.Equal(t, []string{"media:upload", "room:read"}, k.Scopes)
+					assert.True(t, strings.HasPrefix(k.Prefix, service.APIKeyPrefix))
+					assert.NotEmpty(t, k.KeyHash)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			checkBody: func(t *testing.T, body string) {
+				var resp struct {
+					Data struct {
+						Key    string `json:"key"`
+						Prefix string `json:"prefix"`
+					} `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(body), &resp))
+				assert.True(t, strings.HasPrefix(resp.Data.Key, resp.Data.Prefix+"_"))
+				assert.NotContains(t, body, "key_hash")
+			},
+		},
+		{
+			name:   "Scope beyond a company user - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Greedy","scopes":["company:create"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Key able to mint keys - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Minter","scopes":["api_key:create"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Expiry in the past - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Old","scopes":["room:read"],"expires_at":"2020-01-01T00:00:00Z"}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Company user creating a key for other company - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Foreign","company_id":"company-456","scopes":["room:read"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Customer creating a key - Forbidden",
+			user:   customer,
+			method: http.MethodPost,
+			body:   `{"name":"Mine","scopes":["room:read"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Admin creating a key without company - Bad Request",
+			user:   admin,
+			method: http.MethodPost,
+			body:   `{"name":"Nowhere","scopes":["room:read"]}`,
+			call:   (*APIKeyHandler).CreateAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Company user listing keys - Scoped to own company",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*APIKeyHandler).GetAllAPIKeys,
+			setupRepos: func(m apiKeyMocks) {
+				m.keys.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), "company-123").Return([]*model.APIKey{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Company user revoking other company's key - Forbidden",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "key-2",
+			call:     (*APIKeyHandler).RevokeAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+				m.keys.EXPECT().FindByID(gomock.Any(), "key-2").Return(&model.APIKey{ID: "key-2", CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Revoking a revoked key - Conflict",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "key-1",
+			call:     (*APIKeyHandler).RevokeAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+				m.keys.EXPECT().FindByID(gomock.Any(), "key-1").Return(&model.APIKey{ID: "key-1", CompanyID: "company-123", RevokedAt: &revokedAt}, nil)
+				m.keys.EXPECT().Revoke(gomock.Any(), "key-1").Return(false, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:     "Revoking a missing key - Not Found",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "key-3",
+			call:     (*APIKeyHandler).RevokeAPIKey,
+			setupRepos: func(m apiKeyMocks) {
+				m.keys.EXPECT().FindByID(gomock.Any(), "key-3").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := apiKeyMocks{
+				keys:      mock_repository.NewMockAPIKeyRepository(ctrl),
+				companies: mock_repository.NewMockCompanyRepository(ctrl),
+			}
+			tt.setupRepos(m)
+
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			svc := service.NewAPIKeyService(m.keys, m.companies, service.NewAuthorizer(authz.Default, tenants), authz.Default)
+			handler := NewAPIKeyHandler(svc)
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/api-keys/"+tt.targetID, tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.targetID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := mock_repository.NewMockAPIKeyRepository(ctrl)
+	companies := mock_repository.NewMockCompanyRepository(ctrl)
+	svc := service.NewAPIKeyService(keys, companies, service.NewAuthorizer(authz.Default, nil), authz.Default)
+
+	// Issue a real key to learn what gets stored for it
+	var stored *model.APIKey
+	companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(&model.Company{ID: "company-123"}, nil)
+	keys.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, k *model.APIKey) error {
+		stored = k
+		return nil
+	})
+	ctx := model.ContextWithUser(httptest.NewRequest(http.MethodGet, "/", nil).Context(),
+		&model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"})
+	created, err := svc.CreateAPIKey(ctx, "Scheduler", "", []string{"unit:read"}, nil)
+	require.NoError(t, err)
+
+	keys.EXPECT().FindByPrefix(gomock.Any(), stored.Prefix).Return(stored, nil).Times(2)
+	keys.EXPECT().TouchLastUsed(gomock.Any(), stored.ID, gomock.Any()).Return(nil)
+
+	payload, err := svc.AuthenticateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+	assert.Equal(t, "user-1", payload.UserID)
+	assert.Equal(t, string(model.RoleCompany), payload.Role)
+	assert.Equal(t, "company-123", payload.CompanyID)
+	assert.Equal(t, stored.ID, payload.APIKeyID)
+	assert.Equal(t, []string{"unit:read"}, payload.Scopes)
+
+	// Right prefix, wrong secret
+	payload, err = svc.AuthenticateAPIKey(ctx, stored.Prefix+"_guessed")
+	require.NoError(t, err)
+	assert.Nil(t, payload)
+
+	// Malformed keys never reach the database
+	payload, err = svc.AuthenticateAPIKey(ctx, "gsk_short")
+	require.NoError(t, err)
+	assert.Nil(t, payload)
+}
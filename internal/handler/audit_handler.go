@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+}
+
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// GetAuditEvents lists the audit trail, filtered by actor_id, action, resource_type,
+// resource_id, company_id and a from/to time range in RFC 3339, next to the usual range, sort
+// and filter parameters.
+func (h *AuditHandler) GetAuditEvents(c *gin.Context) {
+	query, ok := keysetListQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := repository.AuditFilter{
+		CompanyID:    c.Query("company_id"),
+		ActorID:      c.Query("actor_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+	if filter.Action != "" && !model.IsValidAuditAction(filter.Action) {
+		c.JSON(http.StatusBadRequest, dto.ValidationError("action", "Action must be create, update, delete or restore", dto.ErrorCodeInvalidFormat))
+		return
+	}
+	if filter.From, ok = h.timeQuery(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = h.timeQuery(c, "to"); !ok {
+		return
+	}
+
+	events, page, err := h.service.GetAuditEvents(c.Request.Context(), query, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	if query.Keyset {
+		c.JSON(http.StatusOK, dto.ResponseSuccessWithMeta("Audit events retrieved successfully", events, pageMeta(page)))
+		return
+	}
+	c.Header("Content-Range", query.ContentRange("audit", len(events), *page.Total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Audit events retrieved successfully", events))
+}
+
+// timeQuery reads an optional RFC 3339 time from the query, answering 400 when it is malformed.
+func (h *AuditHandler) timeQuery(c *gin.Context, field string) (*time.Time, bool) {
+	value := c.Query(field)
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ValidationError(field, "Time must be in RFC 3339 format", dto.ErrorCodeInvalidFormat))
+		return nil, false
+	}
+	return &t, true
+}
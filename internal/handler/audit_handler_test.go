@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	mock_services "github.com/hfleury/bk_globalshot/mock/services"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditRecorder stands in for the audit trail in tests that are not about it.
+func newAuditRecorder(ctrl *gomock.Controller) service.AuditService {
+	audit := mock_services.NewMockAuditService(ctrl)
+	audit.EXPECT().Record(gomock.Any(), gomock.Any()).AnyTimes()
+	return audit
+}
+
+func TestAuditHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer)}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		query          string
+		setupRepo      func(r *mock_repository.MockAuditRepository)
+		expectedStatus int
+	}{
+		{
+			name:  "Admin filtering by company and time - Allowed",
+			user:  admin,
+			query: "?company_id=company-456&action=update&from=2026-01-01T00:00:00Z",
+			setupRepo: func(r *mock_repository.MockAuditRepository) {
+				from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				r.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), repository.AuditFilter{CompanyID: "company-456", Action: "update", From: &from}).Return([]*model.AuditEvent{}, listquery.PageInfo{Total: new(int64)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Company user asking for another company - Scoped to own company",
+			user:  companyUser,
+			query: "?company_id=company-456&resource_type=site",
+			setupRepo: func(r *mock_repository.MockAuditRepository) {
+				r.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), repository.AuditFilter{CompanyID: "company-123", ResourceType: "site"}).Return([]*model.AuditEvent{}, listquery.PageInfo{Total: new(int64)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Admin paging and sorting - Passed on",
+			user:  admin,
+			query: `?range=[20,44]&sort=["occurred_at","ASC"]&filter={"resource_type":"unit"}`,
+			setupRepo: func(r *mock_repository.MockAuditRepository) {
+				query := listquery.Query{Offset: 20, Limit: 25, Sort: "occurred_at", Filter: map[string]interface{}{"resource_type": "unit"}}
+				r.EXPECT().FindAll(gomock.Any(), query, repository.AuditFilter{}).Return([]*model.AuditEvent{}, listquery.PageInfo{Total: new(int64)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Range ending before it starts - Bad Request",
+			user:           admin,
+			query:          "?range=[10,0]",
+			setupRepo:      func(r *mock_repository.MockAuditRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Customer reading the trail - Forbidden",
+			user:           customer,
+			setupRepo:      func(r *mock_repository.MockAuditRepository) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Unknown action - Bad Request",
+			user:           admin,
+			query:          "?action=read",
+			setupRepo:      func(r *mock_repository.MockAuditRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Malformed time - Bad Request",
+			user:           admin,
+			query:          "?to=yesterday",
+			setupRepo:      func(r *mock_repository.MockAuditRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockAuditRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepo(repo)
+			handler := NewAuditHandler(service.NewAuditService(repo, tenants, service.NewAuthorizer(authz.Default, tenants)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, http.MethodGet, "/audit"+tt.query, "")
+
+			handler.GetAuditEvents(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAuditTrailOfRoomUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rooms := mock_repository.NewMockRoomRepository(ctrl)
+	tenants := mock_repository.NewMockTenantRepository(ctrl)
+	audits := mock_repository.NewMockAuditRepository(ctrl)
+
+	tenants.EXPECT().RoomOwnership(gomock.Any(), "room-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+	rooms.EXPECT().FindByID(gomock.Any(), "room-1").Return(&model.Room{ID: "room-1", Name: "Kitchen", UnitID: "unit-1", UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+	rooms.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+
+	var recorded *model.AuditEvent
+	audits.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, e *model.AuditEvent) error {
+		recorded = e
+		return nil
+	})
+
+	authorizer := service.NewAuthorizer(authz.Default, tenants)
+	handler := NewRoomHandler(service.NewRoomService(rooms, authorizer, service.NewAuditService(audits, tenants, authorizer)))
+
+	w := httptest.NewRecorder()
+	user := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	c := newTenantContext(w, user, http.MethodPut, "/rooms/room-1", `{"name":"Living room","unit_id":"unit-1"}`)
+	c.Request = c.Request.WithContext(model.ContextWithClient(c.Request.Context(), model.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "test-agent"}))
+	c.Params = []gin.Param{{Key: "id", Value: "room-1"}}
+
+	handler.UpdateRoom(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, recorded)
+	assert.Equal(t, model.AuditActionUpdate, recorded.Action)
+	assert.Equal(t, model.AuditResourceRoom, recorded.ResourceType)
+	assert.Equal(t, "room-1", recorded.ResourceID)
+	assert.Equal(t, "user-1", *recorded.ActorID)
+	assert.Equal(t, "company-123", *recorded.CompanyID)
+	assert.Equal(t, "203.0.113.7", *recorded.IPAddress)
+	assert.Equal(t, "test-agent", *recorded.UserAgent)
+	// Only the fields that changed are kept, the bumped updated_at aside
+	assert.Equal(t, map[string]model.AuditChange{
+		"name": {Before: "Kitchen", After: "Living room"},
+	}, recorded.Changes)
+}
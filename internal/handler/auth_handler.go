@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
 	"github.com/hfleury/bk_globalshot/internal/service"
 )
 
@@ -24,20 +26,17 @@ type LoginRequest struct {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, dto.ValidationError(
-			"email or password",
-			"Invalid request format.",
-			dto.ErrorCodeValidationFailed,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	ctx := c.Request.Context()
 
-	token, role, success, err := h.authService.Login(ctx, req.Email, req.Password)
+	result, success, err := h.authService.Login(ctx, req.Email, req.Password, clientInfo(c))
 	if err != nil {
+		if abortIfLocked(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -48,12 +47,172 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ResponseSuccess("Login successful", gin.H{
-		"token": token,
-		"role":  role,
-	}))
+	if result.Challenge != nil {
+		c.JSON(http.StatusAccepted, dto.ResponseSuccess("Two-factor authentication required", result.Challenge))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Login successful", result.Tokens))
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type EnrollMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// VerifyMFA finishes a login challenged for a second factor.
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, success, err := h.authService.VerifyMFA(c.Request.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		if abortIfLocked(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if !success {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse("Invalid code or expired challenge"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Login successful", tokens))
+}
+
+// EnrollMFA hands out a secret to users whose role requires a second factor they have not set up.
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req EnrollMFARequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	enrollment, success, err := h.authService.EnrollMFA(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusConflict, dto.ResponseError("Two-factor authentication is already enabled", nil))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if !success {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse("Invalid or expired challenge"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Scan the code with your authenticator app, then verify with a code", enrollment))
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, success, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if !success {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse("Invalid or expired refresh token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Token refreshed successfully", tokens))
+}
+
+// Logout revokes the session of the given refresh token, access tokens issued for it stop working at once.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Logout successful", nil))
+}
+
+// abortIfLocked answers 429 for a locked account, it reports whether it did.
+func abortIfLocked(c *gin.Context, err error) bool {
+	var locked *service.AccountLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	middleware.AbortTooManyRequests(c, locked.RetryAfter, "Too many failed attempts, the account is temporarily locked")
+	return true
+}
+
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+type ResetPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConfirmResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ResetPassword emails a reset link. It answers the same whether or not the account exists.
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
-	// TODO implement reset password logic
+	var req ResetPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.ResponseSuccess("If an account exists for this email, a reset link has been sent", nil))
+}
+
+// ConfirmResetPassword sets the new password from a reset link.
+func (h *AuthHandler) ConfirmResetPassword(c *gin.Context) {
+	var req ConfirmResetPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, dto.ValidationError("token", "Reset link is invalid or has expired", dto.ErrorCodeInvalidFormat))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Password has been reset, please log in again", nil))
 }
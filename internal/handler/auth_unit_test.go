@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/service"
 	mock_services "github.com/hfleury/bk_globalshot/mock/services"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +26,8 @@ func TestLoging(t *testing.T) {
 	authHandler := NewAuthHandler(mockedAuthService)
 	email := gofakeit.Email()
 	password := gofakeit.Password(true, true, true, true, true, 26)
+	expiresAt := time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+	refreshExpiresAt := time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC)
 
 	// Using table-driven test for easy HHTP status check
 	tests := []struct {
@@ -40,16 +45,25 @@ func TestLoging(t *testing.T) {
 			},
 			onAuthService: func() {
 				mockedAuthService.EXPECT().
-					Login(gomock.Any(), email, password).
-					Return("valid-token", "customer", true, nil)
+					Login(gomock.Any(), email, password, gomock.Any()).
+					Return(&service.LoginResult{Tokens: &service.AuthTokens{
+						AccessToken:           "valid-token",
+						AccessTokenExpiresAt:  expiresAt,
+						RefreshToken:          "refresh-token",
+						RefreshTokenExpiresAt: refreshExpiresAt,
+						Role:                  "customer",
+					}}, true, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: map[string]interface{}{
 				"success": true,
 				"message": "Login successful",
 				"data": map[string]interface{}{
-					"token": "valid-token",
-					"role":  "customer",
+					"token":              "valid-token",
+					"expires_at":         "2026-01-01T10:15:00Z",
+					"refresh_token":      "refresh-token",
+					"refresh_expires_at": "2026-01-31T10:00:00Z",
+					"role":               "customer",
 				},
 			},
 		},
@@ -68,9 +82,9 @@ func TestLoging(t *testing.T) {
 				"message": "Validation failed.",
 				"error": []interface{}{
 					map[string]interface{}{
-						"code":    "VALIDATION_FAILED",
-						"field":   "email or password",
-						"message": "Invalid request format.",
+						"code":    "INVALID_FORMAT",
+						"field":   "email",
+						"message": "Must be a valid email address",
 						"type":    "validation_error",
 					},
 				},
@@ -84,8 +98,8 @@ func TestLoging(t *testing.T) {
 			},
 			onAuthService: func() {
 				mockedAuthService.EXPECT().
-					Login(gomock.Any(), email, password).
-					Return("", "", false, nil)
+					Login(gomock.Any(), email, password, gomock.Any()).
+					Return(nil, false, nil)
 			},
 			expectedStatusCode: http.StatusUnauthorized,
 			expectedResponse: map[string]interface{}{
@@ -109,8 +123,8 @@ func TestLoging(t *testing.T) {
 			},
 			onAuthService: func() {
 				mockedAuthService.EXPECT().
-					Login(gomock.Any(), email, password).
-					Return("", "", false, assert.AnError)
+					Login(gomock.Any(), email, password, gomock.Any()).
+					Return(nil, false, assert.AnError)
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: map[string]interface{}{
@@ -151,3 +165,316 @@ func TestLoging(t *testing.T) {
 		})
 	}
 }
+
+func TestRefresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		body               string
+		onAuthService      func(m *mock_services.MockAuthService)
+		expectedStatusCode int
+	}{
+		{
+			name: "SUCCESS - Token rotated",
+			body: `{"refresh_token": "old-refresh-token"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					Refresh(gomock.Any(), "old-refresh-token", gomock.Any()).
+					Return(&service.AuthTokens{AccessToken: "new-token", RefreshToken: "new-refresh-token"}, true, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "FAIL - Missing refresh token",
+			body:               `{}`,
+			onAuthService:      func(m *mock_services.MockAuthService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Revoked, expired or replayed refresh token",
+			body: `{"refresh_token": "old-refresh-token"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					Refresh(gomock.Any(), "old-refresh-token", gomock.Any()).
+					Return(nil, false, nil)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "FAIL - Internal server error",
+			body: `{"refresh_token": "old-refresh-token"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					Refresh(gomock.Any(), "old-refresh-token", gomock.Any()).
+					Return(nil, false, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockedAuthService := mock_services.NewMockAuthService(ctrl)
+			tt.onAuthService(mockedAuthService)
+			authHandler := NewAuthHandler(mockedAuthService)
+
+			r := gin.New()
+			r.POST("/v1/auth/refresh", authHandler.Refresh)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/auth/refresh", bytes.NewBufferString(tt.body))
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedAuthService := mock_services.NewMockAuthService(ctrl)
+	mockedAuthService.EXPECT().Logout(gomock.Any(), "refresh-token").Return(nil)
+	authHandler := NewAuthHandler(mockedAuthService)
+
+	r := gin.New()
+	r.POST("/v1/auth/logout", authHandler.Logout)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/auth/logout", bytes.NewBufferString(`{"refresh_token": "refresh-token"}`))
+	r.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		body               string
+		onAuthService      func(m *mock_services.MockAuthService)
+		expectedStatusCode int
+	}{
+		{
+			name: "SUCCESS - Reset link requested",
+			body: `{"email": "jane@example.com"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().RequestPasswordReset(gomock.Any(), "jane@example.com").Return(nil)
+			},
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			name:               "FAIL - Invalid email",
+			body:               `{"email": "not-an-email"}`,
+			onAuthService:      func(m *mock_services.MockAuthService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockedAuthService := mock_services.NewMockAuthService(ctrl)
+			tt.onAuthService(mockedAuthService)
+			authHandler := NewAuthHandler(mockedAuthService)
+
+			r := gin.New()
+			r.POST("/v1/auth/reset-password", authHandler.ResetPassword)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/auth/reset-password", bytes.NewBufferString(tt.body))
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestConfirmResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		body               string
+		onAuthService      func(m *mock_services.MockAuthService)
+		expectedStatusCode int
+	}{
+		{
+			name: "SUCCESS - Password reset",
+			body: `{"token": "reset-token", "password": "n3w-passw0rd"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "n3w-passw0rd").Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "FAIL - Password too short",
+			body:               `{"token": "reset-token", "password": "123"}`,
+			onAuthService:      func(m *mock_services.MockAuthService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Used or expired token",
+			body: `{"token": "reset-token", "password": "n3w-passw0rd"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "n3w-passw0rd").Return(service.ErrInvalidResetToken)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Internal server error",
+			body: `{"token": "reset-token", "password": "n3w-passw0rd"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "n3w-passw0rd").Return(assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockedAuthService := mock_services.NewMockAuthService(ctrl)
+			tt.onAuthService(mockedAuthService)
+			authHandler := NewAuthHandler(mockedAuthService)
+
+			r := gin.New()
+			r.POST("/v1/auth/reset-password/confirm", authHandler.ConfirmResetPassword)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/auth/reset-password/confirm", bytes.NewBufferString(tt.body))
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestLoginChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedAuthService := mock_services.NewMockAuthService(ctrl)
+	mockedAuthService.EXPECT().
+		Login(gomock.Any(), "admin@example.com", "secret1", gomock.Any()).
+		Return(&service.LoginResult{Challenge: &service.LoginChallenge{ChallengeToken: "challenge-token", EnrollmentRequired: true}}, true, nil)
+	authHandler := NewAuthHandler(mockedAuthService)
+
+	r := gin.New()
+	r.POST("/v1/auth/login", authHandler.Login)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/auth/login", bytes.NewBufferString(`{"email": "admin@example.com", "password": "secret1"}`))
+	r.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"challenge_token":"challenge-token"`)
+	assert.Contains(t, recorder.Body.String(), `"enrollment_required":true`)
+	assert.NotContains(t, recorder.Body.String(), `"refresh_token"`)
+}
+
+func TestVerifyMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		body               string
+		onAuthService      func(m *mock_services.MockAuthService)
+		expectedStatusCode int
+	}{
+		{
+			name: "SUCCESS - Code accepted",
+			body: `{"challenge_token": "challenge-token", "code": "123456"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					VerifyMFA(gomock.Any(), "challenge-token", "123456", gomock.Any()).
+					Return(&service.AuthTokens{AccessToken: "token", RefreshToken: "refresh-token"}, true, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "FAIL - Missing code",
+			body:               `{"challenge_token": "challenge-token"}`,
+			onAuthService:      func(m *mock_services.MockAuthService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Wrong code or expired challenge",
+			body: `{"challenge_token": "challenge-token", "code": "000000"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					VerifyMFA(gomock.Any(), "challenge-token", "000000", gomock.Any()).
+					Return(nil, false, nil)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "FAIL - Account locked",
+			body: `{"challenge_token": "challenge-token", "code": "000000"}`,
+			onAuthService: func(m *mock_services.MockAuthService) {
+				m.EXPECT().
+					VerifyMFA(gomock.Any(), "challenge-token", "000000", gomock.Any()).
+					Return(nil, false, &service.AccountLockedError{RetryAfter: time.Minute})
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockedAuthService := mock_services.NewMockAuthService(ctrl)
+			tt.onAuthService(mockedAuthService)
+			authHandler := NewAuthHandler(mockedAuthService)
+
+			r := gin.New()
+			r.POST("/v1/auth/mfa/verify", authHandler.VerifyMFA)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/auth/mfa/verify", bytes.NewBufferString(tt.body))
+			r.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestLoginLocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedAuthService := mock_services.NewMockAuthService(ctrl)
+	mockedAuthService.EXPECT().
+		Login(gomock.Any(), "admin@example.com", "secret1", gomock.Any()).
+		Return(nil, false, fmt.Errorf("login: %w", &service.AccountLockedError{RetryAfter: 90 * time.Second}))
+	authHandler := NewAuthHandler(mockedAuthService)
+
+	r := gin.New()
+	r.POST("/v1/auth/login", authHandler.Login)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/auth/login", bytes.NewBufferString(`{"email": "admin@example.com", "password": "secret1"}`))
+	r.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "90", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), `"rate_limit_error"`)
+}
@@ -1,16 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
-	"github.com/hfleury/bk_globalshot/internal/model"
-	"github.com/hfleury/bk_globalshot/internal/router/middleware"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 	"github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
@@ -25,18 +22,25 @@ func NewCompanyHandler(service service.CompanyService) *CompanyHandler {
 type CreateCompanyRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password"` // Optional, the company user is invited when left out
+}
+
+type UpdateCompanyRequest struct {
+	Name string `json:"name" binding:"required"`
 }
 
 func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 	var req CreateCompanyRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name", "Name is required", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	company, err := h.service.CreateCompany(c.Request.Context(), req.Name, req.Email, req.Password)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
 		if errors.Is(err, repository.ErrEmailAlreadyExists) {
 			c.JSON(http.StatusConflict, dto.ResponseError("Email already exists", []dto.ErrorResponse{
 				{
@@ -57,73 +61,38 @@ func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 }
 
 func (h *CompanyHandler) GetAllCompanies(c *gin.Context) {
-	limit := 10
-	offset := 0
-
-	rangeParam := c.Query("range")
-	if rangeParam != "" {
-		var rangeSlice []int
-		if err := json.Unmarshal([]byte(rangeParam), &rangeSlice); err == nil && len(rangeSlice) == 2 {
-			offset = rangeSlice[0]
-			limit = rangeSlice[1] - rangeSlice[0] + 1
-		}
+	query, ok := listQuery(c)
+	if !ok {
+		return
 	}
 
-	payload := middleware.GetAuthPayload(c)
-	if payload != nil && model.Role(payload.Role) == model.RoleCompany {
-		company, err := h.service.GetCompanyByID(c.Request.Context(), payload.CompanyID)
-		if err != nil {
+	companies, total, err := h.service.GetAllCompanies(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
-			return
-		}
-
-		companies := make([]*model.Company, 0)
-		var total int64 = 0
-		if company != nil {
-			companies = append(companies, company)
-			total = 1
 		}
-
-		c.Header("Content-Range", fmt.Sprintf("companies 0-0/%d", total))
-		c.JSON(http.StatusOK, dto.ResponseSuccess("Companies retrieved successfully", companies))
-		return
-	}
-
-	companies, total, err := h.service.GetAllCompanies(c.Request.Context(), limit, offset)
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
 	}
 
-	// Set Content-Range header for React Admin
-	// Format: companies start-end/total
-	end := offset + len(companies) - 1
-	if len(companies) == 0 {
-		end = offset // or 0? usually if empty, start-start/total where start usually > total, or */total
-		// React Admin handles empty content info gracefully usually if we say 0-0/0 or similar.
-		// Let's set it to: companies offset-(offset + length)/total
-	}
-	// Content-Range: companies 0-9/100
-	c.Header("Content-Range", fmt.Sprintf("companies %d-%d/%d", offset, end, total))
-
+	c.Header("Content-Range", query.ContentRange("companies", len(companies), total))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Companies retrieved successfully", companies))
 }
 
 func (h *CompanyHandler) GetCompanyByID(c *gin.Context) {
 	id := c.Param("id")
 
-	payload := middleware.GetAuthPayload(c)
-	if payload != nil && model.Role(payload.Role) == model.RoleCompany {
-		if payload.CompanyID != id {
+	company, err := h.service.GetCompanyByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
 			return
 		}
-	}
-
-	company, err := h.service.GetCompanyByID(c.Request.Context(), id)
-	if err != nil {
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -133,28 +102,61 @@ func (h *CompanyHandler) GetCompanyByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, company.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Company retrieved successfully", company))
 }
 
 func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 	id := c.Param("id")
 
-	payload := middleware.GetAuthPayload(c)
-	if payload != nil && model.Role(payload.Role) == model.RoleCompany {
-		if payload.CompanyID != id {
+	var req UpdateCompanyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	company, err := h.service.UpdateCompany(c.Request.Context(), id, req.Name, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
 			return
 		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if company == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Company not found", nil))
+		return
 	}
 
-	var req CreateCompanyRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name", "Name is required", dto.ErrorCodeValidationFailed))
+	c.Header("ETag", etag(company.Version))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Company updated successfully", company))
+}
+
+func (h *CompanyHandler) PatchCompany(c *gin.Context) {
+	id := c.Param("id")
+
+	var patch service.CompanyPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	company, err := h.service.UpdateCompany(c.Request.Context(), id, req.Name)
+	company, err := h.service.PatchCompany(c.Request.Context(), id, patch, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -164,25 +166,23 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(company.Version))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Company updated successfully", company))
 }
 
 func (h *CompanyHandler) DeleteCompany(c *gin.Context) {
 	id := c.Param("id")
 
-	payload := middleware.GetAuthPayload(c)
-	if payload != nil && model.Role(payload.Role) == model.RoleCompany {
-		// Company cannot delete itself? Or allows it?
-		// Usually deletion is admin only or restricted.
-		// Let's allow strictly verification but arguably companies shouldn't delete themselves easily.
-		if payload.CompanyID != id {
+	err := h.service.DeleteCompany(c.Request.Context(), id, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
 			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
 			return
 		}
-	}
-
-	err := h.service.DeleteCompany(c.Request.Context(), id)
-	if err != nil {
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
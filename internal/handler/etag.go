@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+)
+
+// etag is the validator of a resource at a version, "3" for version 3.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch reads the If-Match header into the versions an update or delete may apply to.
+// Without the header, or with "*", any version will do. Weak tags and tags that are not ours
+// never match, If-Match compares strongly.
+func ifMatch(c *gin.Context) service.Precondition {
+	values := c.Request.Header.Values("If-Match")
+	if len(values) == 0 {
+		return nil
+	}
+	pre := service.Precondition{}
+	for _, tag := range entityTags(values) {
+		if tag == "*" {
+			return nil
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			pre = append(pre, version)
+		}
+	}
+	return pre
+}
+
+// notModified sets the ETag of a resource read at version and answers 304 when the
+// If-None-Match header already holds it, the caller's copy being current.
+func notModified(c *gin.Context, version int64) bool {
+	current := etag(version)
+	c.Header("ETag", current)
+	for _, tag := range entityTags(c.Request.Header.Values("If-None-Match")) {
+		// If-None-Match compares weakly, W/"3" stands for "3" too
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			c.AbortWithStatus(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed answers 412 to a change sent with the ETag of a version that is gone.
+func preconditionFailed(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, dto.PreconditionFailedResponse("Resource was modified, fetch it again"))
+}
+
+func entityTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	updateBody := `{"name":"Flat 2B","type":"FLAT","site_id":"site-1"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		header         string
+		value          string
+		call           func(h *UnitHandler, c *gin.Context)
+		setupRepo      func(units *mock_repository.MockUnitRepository)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name:           "Read - ETag of the version",
+			method:         http.MethodGet,
+			call:           (*UnitHandler).GetUnitByID,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "Read with the current ETag - Not Modified",
+			method:         http.MethodGet,
+			header:         "If-None-Match",
+			value:          `"2", "3"`,
+			call:           (*UnitHandler).GetUnitByID,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "Read with a weak current ETag - Not Modified",
+			method:         http.MethodGet,
+			header:         "If-None-Match",
+			value:          `W/"3"`,
+			call:           (*UnitHandler).GetUnitByID,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "Read with a stale ETag - Sent again",
+			method:         http.MethodGet,
+			header:         "If-None-Match",
+			value:          `"2"`,
+			call:           (*UnitHandler).GetUnitByID,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:   "Update with the current ETag - Updated",
+			method: http.MethodPut,
+			body:   updateBody,
+			header: "If-Match",
+			value:  `"3"`,
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *model.Unit) error {
+					u.Version++
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:   "Update with any ETag - Updated",
+			method: http.MethodPut,
+			body:   updateBody,
+			header: "If-Match",
+			value:  "*",
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Update with a stale ETag - Precondition Failed",
+			method:         http.MethodPut,
+			body:           updateBody,
+			header:         "If-Match",
+			value:          `"2"`,
+			call:           (*UnitHandler).UpdateUnit,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "Update with a weak ETag - Precondition Failed",
+			method:         http.MethodPut,
+			body:           updateBody,
+			header:         "If-Match",
+			value:          `W/"3"`,
+			call:           (*UnitHandler).UpdateUnit,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "Update racing another one - Precondition Failed",
+			method: http.MethodPut,
+			body:   updateBody,
+			header: "If-Match",
+			value:  `"3"`,
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Update(gomock.Any(), gomock.Any()).Return(pkgRepository.ErrVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "Delete with a stale ETag - Precondition Failed",
+			method:         http.MethodDelete,
+			header:         "If-Match",
+			value:          `"1"`,
+			call:           (*UnitHandler).DeleteUnit,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "Delete with the current ETag - Deleted",
+			method: http.MethodDelete,
+			header: "If-Match",
+			value:  `"3"`,
+			call:   (*UnitHandler).DeleteUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Delete(gomock.Any(), "unit-1", int64(3)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Delete racing an update after the ETag was checked - Precondition Failed",
+			method: http.MethodDelete,
+			header: "If-Match",
+			value:  `"3"`,
+			call:   (*UnitHandler).DeleteUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Delete(gomock.Any(), "unit-1", int64(3)).Return(pkgRepository.ErrVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			units := mock_repository.NewMockUnitRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+			units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1", Name: "Flat 2A", Type: model.UnitTypeFlat, SiteID: "site-1", Version: 3}, nil)
+			if tt.setupRepo != nil {
+				tt.setupRepo(units)
+			}
+			handler := NewUnitHandler(service.NewUnitService(nil, units, mock_repository.NewMockUserRepository(ctrl), tenants, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, companyUser, tt.method, "/units/unit-1", tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: "unit-1"}}
+			if tt.header != "" {
+				c.Request.Header.Set(tt.header, tt.value)
+			}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedETag != "" {
+				assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type ImpersonationHandler struct {
+	service service.ImpersonationService
+}
+
+func NewImpersonationHandler(service service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: service}
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required"` // Kept with the record, a ticket reference for instance
+}
+
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	var req ImpersonateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.service.Impersonate(c.Request.Context(), c.Param("userId"), req.Reason, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrImpersonationReason):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("reason", "A reason is required", dto.ErrorCodeRequiredField))
+		case errors.Is(err, service.ErrImpersonationTarget):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Admins cannot be impersonated"))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+	if tokens == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseSuccess("Impersonation started", tokens))
+}
+
+func (h *ImpersonationHandler) GetAllImpersonations(c *gin.Context) {
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.With("user_id", userID)
+	}
+
+	impersonations, total, err := h.service.GetAllImpersonations(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.Header("Content-Range", query.ContentRange("impersonations", len(impersonations), total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Impersonations retrieved successfully", impersonations))
+}
+
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	impersonation, err := h.service.EndImpersonation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrImpersonationEnded):
+			c.JSON(http.StatusConflict, dto.ResponseError("Impersonation has already ended", nil))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+	if impersonation == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Impersonation not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Impersonation ended", impersonation))
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	paseto "aidanwoods.dev/go-paseto"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type impersonationMocks struct {
+	impersonations *mock_repository.MockImpersonationRepository
+	users          *mock_repository.MockUserRepository
+	sessions       *mock_repository.MockAuthSessionRepository
+}
+
+func TestImpersonationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	maker, err := token.NewPasetoMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	admin := &model.User{ID: "admin-1", Email: "support@globalshot.example", Role: string(model.RoleAdmin)}
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Email: "owner@home.example", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+	endedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		targetID       string
+		call           func(h *ImpersonationHandler, c *gin.Context)
+		setupRepos     func(m impersonationMocks)
+		expectedStatus int
+		checkBody      func(t *testing.T, body string)
+	}{
+		{
+			name:     "Admin impersonating a customer - Created",
+			user:     admin,
+			method:   http.MethodPost,
+			body:     `{"reason":"Ticket 4521, room missing"}`,
+			targetID: "customer-1",
+			call:     (*ImpersonationHandler).Impersonate,
+			setupRepos: func(m impersonationMocks) {
+				m.users.EXPECT().FindByID(gomock.Any(), "customer-1").Return(customer, nil)
+				m.sessions.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, s *model.AuthSession) error {
+					assert.Equal(t, "admin-1", s.UserID)
+					return nil
+				})
+				m.impersonations.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, i *model.Impersonation) error {
+					assert.Equal(t, "admin-1", *i.AdminID)
+					assert.Equal(t, "customer-1", *i.UserID)
+					assert.Equal(t, "Ticket 4521, room missing", i.Reason)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			checkBody: func(t *testing.T, body string) {
+				var resp struct {
+					Data service.ImpersonationTokens `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(body), &resp))
+
+				payload, err := maker.VerifyToken(resp.Data.AccessToken)
+				require.NoError(t, err)
+				assert.Equal(t, "customer-1", payload.UserID)
+				assert.Equal(t, string(model.RoleCustomer), payload.Role)
+				assert.Equal(t, "admin-1", payload.ImpersonatorID)
+				assert.Equal(t, "support@globalshot.example", payload.ImpersonatorEmail)
+				assert.Equal(t, resp.Data.Impersonation.ID, payload.ImpersonationID)
+				assert.Equal(t, *resp.Data.Impersonation.SessionID, payload.SessionID)
+			},
+		},
+		{
+			name:     "Admin impersonating an admin - Forbidden",
+			user:     admin,
+			method:   http.MethodPost,
+			body:     `{"reason":"Curious"}`,
+			targetID: "admin-2",
+			call:     (*ImpersonationHandler).Impersonate,
+			setupRepos: func(m impersonationMocks) {
+				m.users.EXPECT().FindByID(gomock.Any(), "admin-2").Return(&model.User{ID: "admin-2", Role: string(model.RoleAdmin)}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user impersonating a customer - Forbidden",
+			user:     companyUser,
+			method:   http.MethodPost,
+			body:     `{"reason":"Checking"}`,
+			targetID: "customer-1",
+			call:     (*ImpersonationHandler).Impersonate,
+			setupRepos: func(m impersonationMocks) {
+				m.users.EXPECT().FindByID(gomock.Any(), "customer-1").Return(customer, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Impersonating without a reason - Bad Request",
+			user:     admin,
+			method:   http.MethodPost,
+			body:     `{"reason":"  "}`,
+			targetID: "customer-1",
+			call:     (*ImpersonationHandler).Impersonate,
+			setupRepos: func(m impersonationMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "Impersonating a missing user - Not Found",
+			user:     admin,
+			method:   http.MethodPost,
+			body:     `{"reason":"Ticket 4522"}`,
+			targetID: "nobody",
+			call:     (*ImpersonationHandler).Impersonate,
+			setupRepos: func(m impersonationMocks) {
+				m.users.EXPECT().FindByID(gomock.Any(), "nobody").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "Admin ending an impersonation - Session revoked",
+			user:     admin,
+			method:   http.MethodDelete,
+			targetID: "impersonation-1",
+			call:     (*ImpersonationHandler).EndImpersonation,
+			setupRepos: func(m impersonationMocks) {
+				sessionID := "session-1"
+				m.impersonations.EXPECT().FindByID(gomock.Any(), "impersonation-1").Return(&model.Impersonation{ID: "impersonation-1", SessionID: &sessionID}, nil)
+				m.impersonations.EXPECT().End(gomock.Any(), "impersonation-1", gomock.Any()).Return(true, nil)
+				m.sessions.EXPECT().Revoke(gomock.Any(), "session-1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Ending an ended impersonation - Conflict",
+			user:     admin,
+			method:   http.MethodDelete,
+			targetID: "impersonation-2",
+			call:     (*ImpersonationHandler).EndImpersonation,
+			setupRepos: func(m impersonationMocks) {
+				m.impersonations.EXPECT().FindByID(gomock.Any(), "impersonation-2").Return(&model.Impersonation{ID: "impersonation-2", EndedAt: &endedAt}, nil)
+				m.impersonations.EXPECT().End(gomock.Any(), "impersonation-2", gomock.Any()).Return(false, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "Company user listing impersonations - Forbidden",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*ImpersonationHandler).GetAllImpersonations,
+			setupRepos: func(m impersonationMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := impersonationMocks{
+				impersonations: mock_repository.NewMockImpersonationRepository(ctrl),
+				users:          mock_repository.NewMockUserRepository(ctrl),
+				sessions:       mock_repository.NewMockAuthSessionRepository(ctrl),
+			}
+			tt.setupRepos(m)
+
+			cfgToken := &config.ConfigToken{ImpersonationExpiry: 30 * time.Minute}
+			svc := service.NewImpersonationService(m.impersonations, m.users, m.sessions, maker, service.NewAuthorizer(authz.Default, nil), cfgToken)
+			handler := NewImpersonationHandler(svc)
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/admin/impersonate/"+tt.targetID, tt.body)
+			c.Params = []gin.Param{{Key: "userId", Value: tt.targetID}, {Key: "id", Value: tt.targetID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
+)
+
+type InvitationHandler struct {
+	service service.InvitationService
+}
+
+func NewInvitationHandler(service service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+type CreateInvitationRequest struct {
+	Email     string  `json:"email" binding:"required,email"`
+	Role      string  `json:"role" binding:"required"`
+	CompanyID string  `json:"company_id"`
+	UnitID    *string `json:"unit_id"` // Optional, only for customers, the unit is theirs once they accept
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invitation, err := h.service.CreateInvitation(c.Request.Context(), req.Email, req.Role, req.CompanyID, req.UnitID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrInvalidInvitationRole):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("role", "Role must be admin, company or customer", dto.ErrorCodeInvalidFormat))
+		case errors.Is(err, service.ErrInvalidInvitationUnit):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("unit_id", "Unit cannot be assigned by this invitation", dto.ErrorCodeValidationFailed))
+		case errors.Is(err, repository.ErrEmailAlreadyExists):
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "This email address is already registered.", dto.ErrorCodeDuplicateEntry))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseSuccess("Invitation sent successfully", invitation))
+}
+
+func (h *InvitationHandler) GetAllInvitations(c *gin.Context) {
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
+
+	invitations, total, err := h.service.GetAllInvitations(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.Header("Content-Range", query.ContentRange("invitations", len(invitations), total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Invitations retrieved successfully", invitations))
+}
+
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	invitation, err := h.service.ResendInvitation(c.Request.Context(), c.Param("id"))
+	if h.handleManageError(c, err) {
+		return
+	}
+	if invitation == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Invitation not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Invitation resent successfully", invitation))
+}
+
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	invitation, err := h.service.RevokeInvitation(c.Request.Context(), c.Param("id"))
+	if h.handleManageError(c, err) {
+		return
+	}
+	if invitation == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Invitation not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Invitation revoked successfully", invitation))
+}
+
+// AcceptInvitation creates the invitee's account. It is public, the token is the credential.
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.AcceptInvitation(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInvitation):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("token", "Invitation is invalid or has expired", dto.ErrorCodeInvalidFormat))
+		case errors.Is(err, repository.ErrEmailAlreadyExists):
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "This email address is already registered.", dto.ErrorCodeDuplicateEntry))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseSuccess("Invitation accepted, you can now log in", user))
+}
+
+// handleManageError answers the errors shared by resend and revoke, it reports whether it did.
+func (h *InvitationHandler) handleManageError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+	case errors.Is(err, service.ErrInvitationNotPending):
+		c.JSON(http.StatusConflict, dto.ResponseError("Invitation was already accepted or revoked", nil))
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+	}
+	return true
+}
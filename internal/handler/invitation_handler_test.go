@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type invitationMocks struct {
+	invitations *mock_repository.MockInvitationRepository
+	users       *mock_repository.MockUserRepository
+	tenants     *mock_repository.MockTenantRepository
+}
+
+func TestInvitationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+	otherCompany := "company-456"
+	acceptedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		targetID       string
+		call           func(h *InvitationHandler, c *gin.Context)
+		setupRepos     func(m invitationMocks)
+		expectedStatus int
+		expectedMails  int
+	}{
+		{
+			name:   "Company user inviting a customer to a unit of own company - Sent",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"client@example.com","role":"customer","unit_id":"unit-1"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+				// Once to authorize the unit, once to learn the company the customer joins
+				m.tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil).Times(2)
+				m.users.EXPECT().FindByEmail(gomock.Any(), "client@example.com").Return(nil, nil)
+				m.invitations.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, i *model.Invitation) error {
+					assert.Equal(t, "company-123", *i.CompanyID)
+					assert.Equal(t, "user-1", *i.InvitedBy)
+					assert.NotEmpty(t, i.TokenHash)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedMails:  1,
+		},
+		{
+			name:   "Company user inviting into other company - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"staff@example.com","role":"company","company_id":"company-456"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+				// Nothing should be stored
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user inviting an admin - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"root@example.com","role":"admin","company_id":"company-123"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user offering other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"client@example.com","role":"customer","unit_id":"unit-2"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Unit offered to company staff - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"staff@example.com","role":"company","unit_id":"unit-1"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Inviting a registered email - Conflict",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"taken@example.com","role":"company","company_id":"company-123"}`,
+			call:   (*InvitationHandler).CreateInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.users.EXPECT().FindByEmail(gomock.Any(), "taken@example.com").Return(&model.User{ID: "user-9"}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "Company user listing invitations - Scoped to own company",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*InvitationHandler).GetAllInvitations,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), "company-123").Return([]*model.Invitation{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer listing invitations - Forbidden",
+			user:   customer,
+			method: http.MethodGet,
+			call:   (*InvitationHandler).GetAllInvitations,
+			setupRepos: func(m invitationMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user resending own company's invitation - Sent",
+			user:     companyUser,
+			method:   http.MethodPost,
+			targetID: "invitation-1",
+			call:     (*InvitationHandler).ResendInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByID(gomock.Any(), "invitation-1").Return(&model.Invitation{ID: "invitation-1", Email: "client@example.com", Role: "customer", CompanyID: &companyUser.CompanyID, TokenHash: "old-hash"}, nil)
+				m.invitations.EXPECT().RenewToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, i *model.Invitation) (bool, error) {
+					assert.NotEqual(t, "old-hash", i.TokenHash)
+					assert.True(t, i.ExpiresAt.After(time.Now()))
+					return true, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedMails:  1,
+		},
+		{
+			name:     "Company user revoking other company's invitation - Forbidden",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "invitation-2",
+			call:     (*InvitationHandler).RevokeInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByID(gomock.Any(), "invitation-2").Return(&model.Invitation{ID: "invitation-2", Role: "company", CompanyID: &otherCompany}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Revoking an accepted invitation - Conflict",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "invitation-1",
+			call:     (*InvitationHandler).RevokeInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByID(gomock.Any(), "invitation-1").Return(&model.Invitation{ID: "invitation-1", Role: "customer", CompanyID: &companyUser.CompanyID, AcceptedAt: &acceptedAt}, nil)
+				m.invitations.EXPECT().Revoke(gomock.Any(), "invitation-1").Return(false, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:     "Revoking a missing invitation - Not Found",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "invitation-3",
+			call:     (*InvitationHandler).RevokeInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByID(gomock.Any(), "invitation-3").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Accepting an unknown token - Bad Request",
+			user:   &model.User{},
+			method: http.MethodPost,
+			body:   `{"token":"unknown","password":"secret1"}`,
+			call:   (*InvitationHandler).AcceptInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Accepting an expired invitation - Bad Request",
+			user:   &model.User{},
+			method: http.MethodPost,
+			body:   `{"token":"expired","password":"secret1"}`,
+			call:   (*InvitationHandler).AcceptInvitation,
+			setupRepos: func(m invitationMocks) {
+				m.invitations.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(&model.Invitation{ID: "invitation-1", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Accepting with a short password - Bad Request",
+			user:   &model.User{},
+			method: http.MethodPost,
+			body:   `{"token":"token","password":"123"}`,
+			call:   (*InvitationHandler).AcceptInvitation,
+			setupRepos: func(m invitationMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := invitationMocks{
+				invitations: mock_repository.NewMockInvitationRepository(ctrl),
+				users:       mock_repository.NewMockUserRepository(ctrl),
+				tenants:     mock_repository.NewMockTenantRepository(ctrl),
+			}
+			tt.setupRepos(m)
+
+			outbox := t.TempDir()
+			mail, err := mailer.NewOutboxMailer("GlobalShot <no-reply@globalshot.local>", outbox)
+			require.NoError(t, err)
+			cfgToken := &config.ConfigToken{InviteTokenExpiry: 24 * time.Hour, InviteURL: "http://localhost:5173/accept-invitation"}
+			svc := service.NewInvitationService(nil, m.invitations, m.users, nil, m.tenants, mail, cfgToken,
+				service.NewAuthorizer(authz.Default, m.tenants), newAuditRecorder(ctrl))
+			handler := NewInvitationHandler(svc)
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/invitations/"+tt.targetID, tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.targetID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			sent, err := os.ReadDir(outbox)
+			require.NoError(t, err)
+			assert.Len(t, sent, tt.expectedMails)
+			for _, entry := range sent {
+				body, err := os.ReadFile(outbox + "/" + entry.Name())
+				require.NoError(t, err)
+				assert.True(t, strings.Contains(string(body), "http://localhost:5173/accept-invitation?token="))
+			}
+		})
+	}
+}
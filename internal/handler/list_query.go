@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// listQuery reads the range, sort and filter parameters React Admin sends with a listing.
+// It answers 400 and returns false when they are malformed.
+func listQuery(c *gin.Context) (listquery.Query, bool) {
+	query, err := listquery.Parse(c.Query("range"), c.Query("sort"), c.Query("filter"))
+	if err != nil {
+		invalidListQuery(c, err)
+		return query, false
+	}
+	return query, true
+}
+
+// invalidListQuery answers 400 for a listing sorted or filtered on a field it does not allow.
+func invalidListQuery(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, dto.ValidationError("range/sort/filter", err.Error(), dto.ErrorCodeInvalidFormat))
+}
+
+// keysetListQuery is listQuery for listings that can also page with cursors: ?cursor= starts
+// with the newest items, ?cursor=<next_cursor> goes on from there and ?count=true adds the
+// total, which is left out otherwise.
+func keysetListQuery(c *gin.Context) (listquery.Query, bool) {
+	query, ok := listQuery(c)
+	if !ok {
+		return query, false
+	}
+	cursor, keyset := c.GetQuery("cursor")
+	if !keyset {
+		return query, true
+	}
+	query, err := query.WithCursor(cursor, c.Query("count") == "true")
+	if err != nil {
+		invalidListQuery(c, err)
+		return query, false
+	}
+	return query, true
+}
+
+// pageMeta tells clients paging with cursors where the next page starts.
+func pageMeta(info listquery.PageInfo) *dto.Meta {
+	meta := &dto.Meta{Total: info.Total}
+	if info.Next != nil {
+		next := info.Next.Encode()
+		meta.NextCursor = &next
+	}
+	return meta
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
+)
+
+// MeHandler serves the account of the signed in user, whatever their role.
+type MeHandler struct {
+	service service.AccountService
+}
+
+func NewMeHandler(service service.AccountService) *MeHandler {
+	return &MeHandler{service: service}
+}
+
+type UpdateMeRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+func (h *MeHandler) GetMe(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	user, err := h.service.GetProfile(c.Request.Context(), payload.UserID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Profile retrieved successfully", user))
+}
+
+// PermissionsResponse is what the frontend needs to decide which actions to offer.
+type PermissionsResponse struct {
+	Role        string        `json:"role"`
+	Permissions []authz.Grant `json:"permissions"`
+}
+
+func (h *MeHandler) GetPermissions(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	grants, err := h.service.Permissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Permissions retrieved successfully", PermissionsResponse{
+		Role:        payload.Role,
+		Permissions: grants,
+	}))
+}
+
+func (h *MeHandler) UpdateMe(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	var req UpdateMeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.UpdateEmail(c.Request.Context(), payload.UserID, req.Email, req.CurrentPassword)
+	if err != nil {
+		if abortIfLocked(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCurrentPassword) {
+			c.JSON(http.StatusBadRequest, dto.ValidationError("current_password", "Current password is incorrect", dto.ErrorCodeValidationFailed))
+			return
+		}
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "Email is already in use", dto.ErrorCodeDuplicateEntry))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Profile updated successfully", user))
+}
+
+func (h *MeHandler) ChangePassword(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	var req ChangePasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	err := h.service.ChangePassword(c.Request.Context(), payload.UserID, payload.SessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if abortIfLocked(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCurrentPassword) {
+			c.JSON(http.StatusBadRequest, dto.ValidationError("current_password", "Current password is incorrect", dto.ErrorCodeValidationFailed))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Password changed successfully, other sessions have been signed out", nil))
+}
+
+func (h *MeHandler) GetSessions(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), payload.UserID, payload.SessionID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Sessions retrieved successfully", sessions))
+}
+
+// DeleteSessions signs the user out of every device but the one making the request.
+func (h *MeHandler) DeleteSessions(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	if err := h.service.RevokeOtherSessions(c.Request.Context(), payload.UserID, payload.SessionID); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Other sessions revoked successfully", nil))
+}
+
+func (h *MeHandler) DeleteSession(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	found, err := h.service.RevokeSession(c.Request.Context(), payload.UserID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Session not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Session revoked successfully", nil))
+}
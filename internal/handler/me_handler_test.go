@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	mock_services "github.com/hfleury/bk_globalshot/mock/services"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newMeContext(method, path, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("authorization_payload", &token.Payload{UserID: "user-123", SessionID: "session-1", Role: string(model.RoleCustomer)})
+	c.Request = httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func TestGetMe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_services.NewMockAccountService(ctrl)
+	mockService.EXPECT().GetProfile(gomock.Any(), "user-123").Return(&model.User{ID: "user-123", Email: "jane@example.com"}, nil)
+	handler := NewMeHandler(mockService)
+
+	c, w := newMeContext("GET", "/me", "")
+	handler.GetMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "jane@example.com")
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestGetPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewMeHandler(service.NewAccountService(nil, nil, nil, authz.Default, nil, nil))
+
+	w := httptest.NewRecorder()
+	c := newTenantContext(w, &model.User{ID: "user-123", Role: string(model.RoleCustomer)}, "GET", "/me/permissions", "")
+	handler.GetPermissions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"permission":"unit:read","scope":"own"}`)
+	assert.NotContains(t, w.Body.String(), "unit:delete")
+}
+
+func TestUpdateMe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupService   func(s *mock_services.MockAccountService)
+		expectedStatus int
+	}{
+		{
+			name: "SUCCESS - Email changed",
+			body: `{"email": "new@example.com", "current_password": "secret"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().UpdateEmail(gomock.Any(), "user-123", "new@example.com", "secret").Return(&model.User{ID: "user-123", Email: "new@example.com"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "FAIL - Invalid email",
+			body:           `{"email": "nope", "current_password": "secret"}`,
+			setupService:   func(s *mock_services.MockAccountService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "FAIL - Current password missing",
+			body:           `{"email": "new@example.com"}`,
+			setupService:   func(s *mock_services.MockAccountService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Wrong current password",
+			body: `{"email": "new@example.com", "current_password": "wrong"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().UpdateEmail(gomock.Any(), "user-123", "new@example.com", "wrong").Return(nil, service.ErrInvalidCurrentPassword)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "FAIL - Account locked",
+			body: `{"email": "new@example.com", "current_password": "secret"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().UpdateEmail(gomock.Any(), "user-123", "new@example.com", "secret").Return(nil, &service.AccountLockedError{RetryAfter: time.Minute})
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "FAIL - Email taken",
+			body: `{"email": "taken@example.com", "current_password": "secret"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().UpdateEmail(gomock.Any(), "user-123", "taken@example.com", "secret").Return(nil, repository.ErrEmailAlreadyExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockAccountService(ctrl)
+			tt.setupService(mockService)
+			handler := NewMeHandler(mockService)
+
+			c, w := newMeContext("PATCH", "/me", tt.body)
+			handler.UpdateMe(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupService   func(s *mock_services.MockAccountService)
+		expectedStatus int
+	}{
+		{
+			name: "SUCCESS - Password changed",
+			body: `{"current_password": "old-secret", "new_password": "new-secret"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().ChangePassword(gomock.Any(), "user-123", "session-1", "old-secret", "new-secret").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "FAIL - Wrong current password",
+			body: `{"current_password": "wrong", "new_password": "new-secret"}`,
+			setupService: func(s *mock_services.MockAccountService) {
+				s.EXPECT().ChangePassword(gomock.Any(), "user-123", "session-1", "wrong", "new-secret").Return(service.ErrInvalidCurrentPassword)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "FAIL - New password too short",
+			body:           `{"current_password": "old-secret", "new_password": "123"}`,
+			setupService:   func(s *mock_services.MockAccountService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockAccountService(ctrl)
+			tt.setupService(mockService)
+			handler := NewMeHandler(mockService)
+
+			c, w := newMeContext("POST", "/me/password", tt.body)
+			handler.ChangePassword(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestGetSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userAgent := "Mozilla/5.0"
+	mockService := mock_services.NewMockAccountService(ctrl)
+	mockService.EXPECT().ListSessions(gomock.Any(), "user-123", "session-1").Return([]*model.AuthSession{
+		{ID: "session-1", UserID: "user-123", TokenHash: "secret-hash", UserAgent: &userAgent, Current: true},
+	}, nil)
+	handler := NewMeHandler(mockService)
+
+	c, w := newMeContext("GET", "/me/sessions", "")
+	handler.GetSessions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), userAgent)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestDeleteSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		found          bool
+		expectedStatus int
+	}{
+		{"SUCCESS - Session revoked", true, http.StatusOK},
+		{"FAIL - Session of another user", false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockAccountService(ctrl)
+			mockService.EXPECT().RevokeSession(gomock.Any(), "user-123", "session-2").Return(tt.found, nil)
+			handler := NewMeHandler(mockService)
+
+			c, w := newMeContext("DELETE", "/me/sessions/session-2", "")
+			c.Params = []gin.Param{{Key: "id", Value: "session-2"}}
+			handler.DeleteSession(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUpdateMeAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users := mock_repository.NewMockUserRepository(ctrl)
+	users.EXPECT().FindByID(gomock.Any(), "user-123").Return(&model.User{ID: "user-123", Email: "old@example.com", Password: string(hash), CompanyID: "company-123"}, nil)
+	lockouts := mock_repository.NewMockLoginLockoutRepository(ctrl)
+	lockouts.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(nil, nil)
+	users.EXPECT().UpdateEmail(gomock.Any(), "user-123", "new@example.com").Return(nil)
+	audit := mock_services.NewMockAuditService(ctrl)
+	audit.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ interface{}, entry service.AuditEntry) {
+		assert.Equal(t, model.AuditActionUpdate, entry.Action)
+		assert.Equal(t, model.AuditResourceUser, entry.ResourceType)
+		assert.Equal(t, "company-123", entry.CompanyID)
+		assert.Equal(t, "old@example.com", entry.Before.(*model.User).Email)
+		assert.Equal(t, "new@example.com", entry.After.(*model.User).Email)
+	})
+	handler := NewMeHandler(service.NewAccountService(users, nil, lockouts, authz.Default, audit, &config.ConfigRateLimit{}))
+
+	c, w := newMeContext("PATCH", "/me", `{"email": "new@example.com", "current_password": "secret"}`)
+	handler.UpdateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestChangePasswordLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users := mock_repository.NewMockUserRepository(ctrl)
+	users.EXPECT().FindByID(gomock.Any(), "user-123").Return(&model.User{ID: "user-123", Password: string(hash)}, nil).Times(2)
+
+	// The second wrong password reaches the threshold and locks the account
+	lockedUntil := time.Now().Add(time.Minute)
+	lockouts := mock_repository.NewMockLoginLockoutRepository(ctrl)
+	gomock.InOrder(
+		lockouts.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(nil, nil),
+		lockouts.EXPECT().RecordFailure(gomock.Any(), "user-123", time.Hour).Return(2, nil),
+		lockouts.EXPECT().Lock(gomock.Any(), "user-123", gomock.Any()).Return(nil),
+		lockouts.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.LoginLockout{UserID: "user-123", LockedUntil: &lockedUntil}, nil),
+	)
+	cfg := &config.ConfigRateLimit{LockoutThreshold: 2, LockoutBase: time.Minute, LockoutMax: time.Hour, LockoutWindow: time.Hour}
+	handler := NewMeHandler(service.NewAccountService(users, nil, lockouts, authz.Default, nil, cfg))
+
+	c, w := newMeContext("POST", "/me/password", `{"current_password": "wrong", "new_password": "new-secret"}`)
+	handler.ChangePassword(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Locked out, even with the right password
+	c, w = newMeContext("POST", "/me/password", `{"current_password": "secret", "new_password": "new-secret"}`)
+	handler.ChangePassword(c)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
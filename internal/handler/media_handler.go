@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/imaging"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type MediaHandler struct {
+	service       service.MediaService
+	maxUploadSize int64
+}
+
+func NewMediaHandler(service service.MediaService, maxUploadSize int64) *MediaHandler {
+	return &MediaHandler{service: service, maxUploadSize: maxUploadSize}
+}
+
+type UploadMediaRequest struct {
+	RoomID  string `form:"room_id" binding:"required"`
+	TakenAt string `form:"taken_at"`
+}
+
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	if h.maxUploadSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+	}
+
+	var req UploadMediaRequest
+	if err := c.ShouldBind(&req); err != nil {
+		if uploadTooLarge(c, err) {
+			return
+		}
+		invalidBody(c, &req, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if uploadTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ValidationError("file", "File is required", dto.ErrorCodeRequiredField))
+		return
+	}
+
+	var takenAt *time.Time
+	if req.TakenAt != "" {
+		parsed, err := parseTakenAt(req.TakenAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ValidationError("taken_at", "Expected RFC3339 or YYYY-MM-DD date", dto.ErrorCodeInvalidFormat))
+			return
+		}
+		takenAt = &parsed
+	}
+
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	defer file.Close()
+
+	media, err := h.service.UploadMedia(c.Request.Context(), service.UploadMediaInput{
+		RoomID:     req.RoomID,
+		UploadedBy: payload.UserID,
+		TakenAt:    takenAt,
+		FileName:   fileHeader.Filename,
+		File:       file,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedMediaType) {
+			c.JSON(http.StatusUnsupportedMediaType, dto.ValidationError("file", "Only JPEG and PNG images are supported", dto.ErrorCodeInvalidFormat))
+			return
+		}
+		if errors.Is(err, service.ErrNotEquirectangular) {
+			c.JSON(http.StatusUnprocessableEntity, dto.ValidationError("file", "This room expects 360° equirectangular captures", dto.ErrorCodeValidationFailed))
+			return
+		}
+		if errors.Is(err, service.ErrImageTooLarge) {
+			c.JSON(http.StatusUnprocessableEntity, dto.ValidationError("file", "Image dimensions exceed the allowed size", dto.ErrorCodeValidationFailed))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Room not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseSuccess("Media uploaded successfully", media))
+}
+
+// uploadTooLarge answers 413 when parsing the form stopped at the upload size limit.
+func uploadTooLarge(c *gin.Context, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	c.JSON(http.StatusRequestEntityTooLarge, dto.ValidationError("file", fmt.Sprintf("Uploads are limited to %d bytes", maxErr.Limit), dto.ErrorCodeValidationFailed))
+	return true
+}
+
+func (h *MediaHandler) GetRoomMedia(c *gin.Context) {
+	roomID := c.Param("id")
+	query, ok := keysetListQuery(c)
+	if !ok {
+		return
+	}
+
+	media, page, err := h.service.GetRoomMedia(c.Request.Context(), roomID, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	if query.Keyset {
+		c.JSON(http.StatusOK, dto.ResponseSuccessWithMeta("Media retrieved successfully", media, pageMeta(page)))
+		return
+	}
+	c.Header("Content-Range", query.ContentRange("media", len(media), *page.Total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Media retrieved successfully", media))
+}
+
+func (h *MediaHandler) GetMediaByID(c *gin.Context) {
+	id := c.Param("id")
+	media, err := h.service.GetMediaByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Media not found", nil))
+		return
+	}
+
+	if notModified(c, media.Version) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Media retrieved successfully", media))
+}
+
+func (h *MediaHandler) GetMediaFile(c *gin.Context) {
+	h.serveMediaVariant(c, model.MediaVariantOriginal)
+}
+
+func (h *MediaHandler) GetMediaThumbnail(c *gin.Context) {
+	h.serveMediaVariant(c, model.MediaVariantThumbnail)
+}
+
+func (h *MediaHandler) GetMediaPreview(c *gin.Context) {
+	h.serveMediaVariant(c, model.MediaVariantPreview)
+}
+
+func (h *MediaHandler) serveMediaVariant(c *gin.Context, variant model.MediaVariant) {
+	id := c.Param("id")
+	file, err := h.service.OpenMediaFile(c.Request.Context(), id, variant)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if file == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Media not found", nil))
+		return
+	}
+	defer file.Reader.Close()
+
+	if variant == model.MediaVariantOriginal {
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", file.Media.FileName))
+	}
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, file.Reader, nil)
+}
+
+// GetTileManifest returns the cube map tile pyramid of a panorama for the 360° viewer.
+func (h *MediaHandler) GetTileManifest(c *gin.Context) {
+	id := c.Param("id")
+	manifest, err := h.service.GetTileManifest(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrMediaNotTiled) {
+			c.JSON(http.StatusNotFound, dto.ResponseError("Media is not a panorama and has no tiles", nil))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if manifest == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Media not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Tile manifest retrieved successfully", manifest))
+}
+
+func (h *MediaHandler) GetMediaTile(c *gin.Context) {
+	level, errLevel := strconv.Atoi(c.Param("level"))
+	y, errY := strconv.Atoi(c.Param("y"))
+	x, errX := strconv.Atoi(c.Param("x"))
+	face := c.Param("face")
+	if errLevel != nil || errY != nil || errX != nil || !imaging.IsCubeFace(face) {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Tile not found", nil))
+		return
+	}
+
+	file, err := h.service.OpenTile(c.Request.Context(), c.Param("id"), level, imaging.CubeFace(face), x, y)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if file == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Tile not found", nil))
+		return
+	}
+	defer file.Reader.Close()
+
+	// Viewers request the same tiles over and over while panning, let the browser keep them
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, file.Reader, nil)
+}
+
+// RegenerateRenditions re-runs thumbnail, preview and tile generation for a media item.
+func (h *MediaHandler) RegenerateRenditions(c *gin.Context) {
+	id := c.Param("id")
+	media, err := h.service.RegenerateRenditions(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Media not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.ResponseSuccess("Media processing scheduled", media))
+}
+
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	id := c.Param("id")
+	err := h.service.DeleteMedia(c.Request.Context(), id, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Media deleted successfully", nil))
+}
+
+// parseTakenAt accepts a full RFC3339 timestamp or a plain calendar date.
+func parseTakenAt(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
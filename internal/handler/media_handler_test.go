@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_services "github.com/hfleury/bk_globalshot/mock/services"
+	"github.com/hfleury/bk_globalshot/pkg/imaging"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUploadRequest(t *testing.T, fields map[string]string, withFile bool) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		assert.NoError(t, writer.WriteField(key, value))
+	}
+	if withFile {
+		part, err := writer.CreateFormFile("file", "living-room.jpg")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte("\xff\xd8\xff\xe0fake-jpeg"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/media/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadMedia(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	takenAt := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		fields         map[string]string
+		withFile       bool
+		setupService   func(*mock_services.MockMediaService)
+		expectedStatus int
+	}{
+		{
+			name:     "SUCCESS - Upload with taken_at",
+			fields:   map[string]string{"room_id": "room-123", "taken_at": "2025-03-14"},
+			withFile: true,
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().UploadMedia(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, input service.UploadMediaInput) (*model.Media, error) {
+						assert.Equal(t, "room-123", input.RoomID)
+						assert.Equal(t, "user-123", input.UploadedBy)
+						assert.Equal(t, "living-room.jpg", input.FileName)
+						assert.Equal(t, takenAt, *input.TakenAt)
+						return &model.Media{ID: "media-123", RoomID: input.RoomID, TakenAt: *input.TakenAt}, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "FAIL - Missing file",
+			fields:         map[string]string{"room_id": "room-123"},
+			withFile:       false,
+			setupService:   func(s *mock_services.MockMediaService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "FAIL - Invalid taken_at",
+			fields:         map[string]string{"room_id": "room-123", "taken_at": "yesterday"},
+			withFile:       true,
+			setupService:   func(s *mock_services.MockMediaService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "FAIL - Room not found",
+			fields:   map[string]string{"room_id": "room-404"},
+			withFile: true,
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().UploadMedia(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "FAIL - Unsupported media type",
+			fields:   map[string]string{"room_id": "room-123"},
+			withFile: true,
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().UploadMedia(gomock.Any(), gomock.Any()).Return(nil, service.ErrUnsupportedMediaType)
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "FAIL - Flat photo uploaded to a 360° room",
+			fields:   map[string]string{"room_id": "room-123"},
+			withFile: true,
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().UploadMedia(gomock.Any(), gomock.Any()).Return(nil, service.ErrNotEquirectangular)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "FAIL - Image dimensions over the limit",
+			fields:   map[string]string{"room_id": "room-123"},
+			withFile: true,
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().UploadMedia(gomock.Any(), gomock.Any()).Return(nil, service.ErrImageTooLarge)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockMediaService(ctrl)
+			tt.setupService(mockService)
+			handler := NewMediaHandler(mockService, 1<<20)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("authorization_payload", &token.Payload{UserID: "user-123", Role: string(model.RoleCompany)})
+			c.Request = newUploadRequest(t, tt.fields, tt.withFile)
+
+			handler.UploadMedia(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUploadMediaTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The service is never reached
+	mockService := mock_services.NewMockMediaService(ctrl)
+	handler := NewMediaHandler(mockService, 64)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("authorization_payload", &token.Payload{UserID: "user-123", Role: string(model.RoleCompany)})
+	c.Request = newUploadRequest(t, map[string]string{"room_id": "room-123"}, true)
+
+	handler.UploadMedia(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"file"`)
+}
+
+func TestGetRoomMedia(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_services.NewMockMediaService(ctrl)
+	handler := NewMediaHandler(mockService, 1<<20)
+
+	media := []*model.Media{
+		{ID: "2", RoomID: "room-123", TakenAt: time.Now()},
+		{ID: "1", RoomID: "room-123", TakenAt: time.Now().Add(-24 * time.Hour)},
+	}
+
+	total := int64(2)
+	mockService.EXPECT().GetRoomMedia(gomock.Any(), "room-123", listquery.New(listquery.DefaultLimit)).Return(media, listquery.PageInfo{Total: &total}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "room-123"}}
+	c.Request = httptest.NewRequest("GET", "/rooms/room-123/media", nil)
+
+	handler.GetRoomMedia(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Range"), "media 0-1/2")
+}
+
+func TestGetRoomMediaWithCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_services.NewMockMediaService(ctrl)
+	handler := NewMediaHandler(mockService, 1<<20)
+
+	after := listquery.Cursor{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "3b241101-e2bb-4255-8caf-4136c566a963"}
+	next := listquery.Cursor{Time: time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC), ID: "3b241101-e2bb-4255-8caf-4136c566a961"}
+	media := []*model.Media{{ID: "media-2", RoomID: "room-123"}, {ID: "media-1", RoomID: "room-123"}}
+
+	query := listquery.Query{Limit: 2, Keyset: true, After: &after}
+	mockService.EXPECT().GetRoomMedia(gomock.Any(), "room-123", query).Return(media, listquery.PageInfo{Next: &next}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "room-123"}}
+	c.Request = httptest.NewRequest("GET", "/rooms/room-123/media?range=[0,1]&cursor="+after.Encode(), nil)
+
+	handler.GetRoomMedia(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Range"))
+
+	var response struct {
+		Meta struct {
+			NextCursor *string `json:"next_cursor"`
+			Total      *int64  `json:"total"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Meta.NextCursor)
+	assert.Equal(t, next.Encode(), *response.Meta.NextCursor)
+	assert.Nil(t, response.Meta.Total)
+}
+
+func TestGetRoomMediaTamperedCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The service is never reached
+	mockService := mock_services.NewMockMediaService(ctrl)
+	handler := NewMediaHandler(mockService, 1<<20)
+
+	tampered := listquery.Cursor{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "' OR 1=1 --"}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "room-123"}}
+	c.Request = httptest.NewRequest("GET", "/rooms/room-123/media?cursor="+tampered.Encode(), nil)
+
+	handler.GetRoomMedia(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTileManifest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		setupService   func(s *mock_services.MockMediaService)
+		expectedStatus int
+	}{
+		{
+			name: "SUCCESS - Manifest of a tiled panorama",
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().GetTileManifest(gomock.Any(), "media-123").Return(&model.TileManifest{
+					MediaID:     "media-123",
+					Status:      model.MediaProcessingReady,
+					Projection:  "cubemap",
+					Faces:       []string{"f", "r", "b", "l", "u", "d"},
+					Levels:      []model.TileLevel{{Level: 0, Size: 512, TileSize: 512, Tiles: 1}},
+					URLTemplate: "/v1/media/media-123/tiles/{z}/{f}/{y}/{x}",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "FAIL - Media is not a panorama",
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().GetTileManifest(gomock.Any(), "media-123").Return(nil, service.ErrMediaNotTiled)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "FAIL - Media not found",
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().GetTileManifest(gomock.Any(), "media-123").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockMediaService(ctrl)
+			tt.setupService(mockService)
+			handler := NewMediaHandler(mockService, 1<<20)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: "media-123"}}
+			c.Request = httptest.NewRequest("GET", "/media/media-123/tiles", nil)
+
+			handler.GetTileManifest(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestGetMediaTile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		face           string
+		setupService   func(s *mock_services.MockMediaService)
+		expectedStatus int
+	}{
+		{
+			name: "SUCCESS - Tile streamed",
+			face: "u",
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().OpenTile(gomock.Any(), "media-123", 1, imaging.CubeUp, 2, 0).Return(&service.MediaFile{
+					Reader:      io.NopCloser(strings.NewReader("tile")),
+					ContentType: "image/jpeg",
+					Size:        4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "FAIL - Unknown face",
+			face:           "x",
+			setupService:   func(s *mock_services.MockMediaService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "FAIL - Tile not generated",
+			face: "f",
+			setupService: func(s *mock_services.MockMediaService) {
+				s.EXPECT().OpenTile(gomock.Any(), "media-123", 1, imaging.CubeFront, 2, 0).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_services.NewMockMediaService(ctrl)
+			tt.setupService(mockService)
+			handler := NewMediaHandler(mockService, 1<<20)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{
+				{Key: "id", Value: "media-123"},
+				{Key: "level", Value: "1"},
+				{Key: "face", Value: tt.face},
+				{Key: "y", Value: "0"},
+				{Key: "x", Value: "2"},
+			}
+			c.Request = httptest.NewRequest("GET", "/media/media-123/tiles/1/"+tt.face+"/0/2", nil)
+
+			handler.GetMediaTile(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
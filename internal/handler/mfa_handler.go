@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/internal/service"
+)
+
+// MFAHandler lets a signed in user manage their own TOTP factor.
+type MFAHandler struct {
+	service service.MFAService
+}
+
+func NewMFAHandler(service service.MFAService) *MFAHandler {
+	return &MFAHandler{service: service}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse is only ever returned right after the codes are generated.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), payload.UserID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Two-factor status retrieved successfully", status))
+}
+
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	enrollment, err := h.service.BeginEnrollment(c.Request.Context(), payload.UserID)
+	if err != nil {
+		handleMFAError(c, err)
+		return
+	}
+	if enrollment == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Scan the code with your authenticator app, then confirm with a code", enrollment))
+}
+
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	h.withCode(c, func(userID, code string) {
+		codes, err := h.service.ConfirmEnrollment(c.Request.Context(), userID, code)
+		if err != nil {
+			handleMFAError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.ResponseSuccess("Two-factor authentication enabled, store the recovery codes safely", RecoveryCodesResponse{RecoveryCodes: codes}))
+	})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	h.withCode(c, func(userID, code string) {
+		codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), userID, code)
+		if err != nil {
+			handleMFAError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.ResponseSuccess("Recovery codes regenerated, the previous ones no longer work", RecoveryCodesResponse{RecoveryCodes: codes}))
+	})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	h.withCode(c, func(userID, code string) {
+		if err := h.service.Disable(c.Request.Context(), userID, code); err != nil {
+			handleMFAError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.ResponseSuccess("Two-factor authentication disabled", nil))
+	})
+}
+
+// withCode binds the code every change to an enabled factor has to come with.
+func (h *MFAHandler) withCode(c *gin.Context, next func(userID, code string)) {
+	payload := middleware.GetAuthPayload(c)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse(""))
+		return
+	}
+
+	var req MFACodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	next(payload.UserID, req.Code)
+}
+
+func handleMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, dto.ValidationError("code", "Code is invalid", dto.ErrorCodeValidationFailed))
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, dto.ResponseError("Two-factor authentication is already enabled", nil))
+	case errors.Is(err, service.ErrMFANotEnabled), errors.Is(err, service.ErrMFAEnrollmentNotStarted):
+		c.JSON(http.StatusConflict, dto.ResponseError("Two-factor authentication is not enabled", nil))
+	case errors.Is(err, service.ErrMFARequired):
+		c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Two-factor authentication is required for your role"))
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+	}
+}
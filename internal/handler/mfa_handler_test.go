@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	validCode, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	enabledAt := time.Now().Add(-time.Hour)
+	customer := &model.User{ID: "user-123", Email: "jane@example.com", Role: string(model.RoleCustomer)}
+	companyUser := &model.User{ID: "user-123", Email: "jane@example.com", Role: string(model.RoleCompany)}
+
+	tests := []struct {
+		name           string
+		body           string
+		call           func(h *MFAHandler, c *gin.Context)
+		setupRepos     func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository)
+		expectedStatus int
+		check          func(t *testing.T, body []byte)
+	}{
+		{
+			name: "Beginning enrollment - Secret and provisioning URI",
+			call: (*MFAHandler).BeginEnrollment,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(customer, nil)
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(nil, nil)
+				mfa.EXPECT().SavePending(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "otpauth://totp/GlobalShot:jane@example.com")
+			},
+		},
+		{
+			name: "Beginning enrollment when enabled - Conflict",
+			call: (*MFAHandler).BeginEnrollment,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(customer, nil)
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret, EnabledAt: &enabledAt}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Confirming with a valid code - Recovery codes",
+			body: `{"code":"` + validCode + `"}`,
+			call: (*MFAHandler).ConfirmEnrollment,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret}, nil)
+				mfa.EXPECT().Enable(gomock.Any(), "user-123", gomock.Any()).Return(true, nil)
+				mfa.EXPECT().ReplaceRecoveryCodes(gomock.Any(), "user-123", gomock.Len(10)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data RecoveryCodesResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Len(t, resp.Data.RecoveryCodes, 10)
+				assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, resp.Data.RecoveryCodes[0])
+			},
+		},
+		{
+			name: "Confirming with a wrong code - Bad Request",
+			body: `{"code":"000000"}`,
+			call: (*MFAHandler).ConfirmEnrollment,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Disabling with a recovery code - Disabled",
+			body: `{"code":"ABCDE-FGHIJ"}`,
+			call: (*MFAHandler).Disable,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(customer, nil)
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret, EnabledAt: &enabledAt}, nil).Times(2)
+				mfa.EXPECT().UseRecoveryCode(gomock.Any(), "user-123", gomock.Any()).Return(true, nil)
+				mfa.EXPECT().Delete(gomock.Any(), "user-123").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Disabling a replayed code - Bad Request",
+			body: `{"code":"` + validCode + `"}`,
+			call: (*MFAHandler).Disable,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(customer, nil)
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret, EnabledAt: &enabledAt}, nil).Times(2)
+				mfa.EXPECT().UseStep(gomock.Any(), "user-123", gomock.Any()).Return(false, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Disabling when the role requires it - Forbidden",
+			body: `{"code":"` + validCode + `"}`,
+			call: (*MFAHandler).Disable,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(companyUser, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Status of a required role - Required",
+			call: (*MFAHandler).GetStatus,
+			setupRepos: func(mfa *mock_repository.MockMFARepository, users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-123").Return(companyUser, nil)
+				mfa.EXPECT().FindByUserID(gomock.Any(), "user-123").Return(&model.UserMFA{Secret: secret, EnabledAt: &enabledAt}, nil)
+				mfa.EXPECT().CountRecoveryCodes(gomock.Any(), "user-123").Return(7, nil)
+			},
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), `"enabled":true,"required":true,"recovery_codes_left":7`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mfa := mock_repository.NewMockMFARepository(ctrl)
+			users := mock_repository.NewMockUserRepository(ctrl)
+			tt.setupRepos(mfa, users)
+			cfgMFA := &config.ConfigMFA{Issuer: "GlobalShot", RequiredRoles: []string{string(model.RoleCompany)}}
+			handler := NewMFAHandler(service.NewMFAService(mfa, users, cfgMFA))
+
+			c, w := newMeContext("POST", "/me/mfa", tt.body)
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.check != nil {
+				tt.check(t, w.Body.Bytes())
+			}
+		})
+	}
+}
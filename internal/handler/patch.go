@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
+)
+
+func init() {
+	// Patch members are validated only when sent, see mergepatch.ValidationValue
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterCustomTypeFunc(mergepatch.ValidationValue,
+			mergepatch.Value[string]{}, mergepatch.Value[bool]{}, mergepatch.Nullable[string]{})
+	}
+}
+
+// bindPatch reads a JSON merge patch into patch. It answers 415 for a body that is not JSON,
+// 400 for a patch that is not an object or whose members do not validate, and returns false then.
+func bindPatch(c *gin.Context, patch interface{}) bool {
+	if contentType := c.ContentType(); contentType != mergepatch.ContentType && contentType != binding.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, dto.ValidationError("Content-Type", "Send the patch as "+mergepatch.ContentType, dto.ErrorCodeInvalidFormat))
+		return false
+	}
+
+	// null, an array or a scalar would bind as a patch changing nothing, the resource would
+	// still get a new version and an audit event
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ValidationError("body", "Body is malformed", dto.ErrorCodeInvalidFormat))
+		return false
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '{' {
+		c.JSON(http.StatusBadRequest, dto.ValidationError("body", "Send the patch as a JSON object", dto.ErrorCodeInvalidFormat))
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return bindJSON(c, patch)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchUnit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	clientID := "customer-1"
+	current := func() *model.Unit {
+		return &model.Unit{ID: "unit-1", Name: "Flat 2A", Type: model.UnitTypeFlat, SiteID: "site-1", ClientID: &clientID, Version: 3}
+	}
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		setupRepos     func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository)
+		expected       *model.Unit // What is saved, nil when nothing is
+		expectedStatus int
+	}{
+		{
+			name:           "Unassigning the customer - Only the customer changes",
+			contentType:    mergepatch.ContentType,
+			body:           `{"client_id":null}`,
+			expected:       &model.Unit{ID: "unit-1", Name: "Flat 2A", Type: model.UnitTypeFlat, SiteID: "site-1", Version: 3},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Renaming as plain JSON - Only the name changes",
+			contentType:    "application/json",
+			body:           `{"name":"Flat 2B"}`,
+			expected:       &model.Unit{ID: "unit-1", Name: "Flat 2B", Type: model.UnitTypeFlat, SiteID: "site-1", ClientID: &clientID, Version: 3},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Moving to another company's site - Forbidden",
+			contentType: mergepatch.ContentType,
+			body:        `{"site_id":"site-2"}`,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Emptying the name - Bad Request",
+			contentType:    mergepatch.ContentType,
+			body:           `{"name":""}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Removing the name - Bad Request",
+			contentType:    mergepatch.ContentType,
+			body:           `{"name":null}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Null patch - Bad Request",
+			contentType:    mergepatch.ContentType,
+			body:           `null`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Array patch - Bad Request",
+			contentType:    mergepatch.ContentType,
+			body:           ` [{"name":"Flat 2B"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Scalar patch - Bad Request",
+			contentType:    "application/json",
+			body:           `"Flat 2B"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty body - Bad Request",
+			contentType:    mergepatch.ContentType,
+			body:           ``,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not a JSON body - Unsupported Media Type",
+			contentType:    "text/plain",
+			body:           `name=Flat 2B`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			units := mock_repository.NewMockUnitRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			if tt.expectedStatus != http.StatusBadRequest && tt.expectedStatus != http.StatusUnsupportedMediaType {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(current(), nil)
+			}
+			if tt.setupRepos != nil {
+				tt.setupRepos(units, tenants)
+			}
+			var saved *model.Unit
+			if tt.expected != nil {
+				units.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *model.Unit) error {
+					saved = u
+					return nil
+				})
+			}
+			handler := NewUnitHandler(service.NewUnitService(nil, units, mock_repository.NewMockUserRepository(ctrl), tenants, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, companyUser, http.MethodPatch, "/units/unit-1", tt.body)
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			c.Params = []gin.Param{{Key: "id", Value: "unit-1"}}
+
+			handler.PatchUnit(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expected != nil {
+				require.NotNil(t, saved)
+				saved.UpdatedAt = tt.expected.UpdatedAt
+				assert.Equal(t, tt.expected, saved)
+			}
+		})
+	}
+}
@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 type RoomHandler struct {
@@ -19,24 +19,32 @@ func NewRoomHandler(service service.RoomService) *RoomHandler {
 }
 
 type CreateRoomRequest struct {
-	Name   string `json:"name" binding:"required"`
-	UnitID string `json:"unit_id" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	UnitID    string `json:"unit_id" binding:"required"`
+	Panoramic *bool  `json:"panoramic"`
 }
 
 type UpdateRoomRequest struct {
-	Name   string `json:"name" binding:"required"`
-	UnitID string `json:"unit_id" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	UnitID    string `json:"unit_id" binding:"required"`
+	Panoramic *bool  `json:"panoramic"`
 }
 
 func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	var req CreateRoomRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name/unit_id", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	room, err := h.service.CreateRoom(c.Request.Context(), req.Name, req.UnitID)
+	room, err := h.service.CreateRoom(c.Request.Context(), req.Name, req.UnitID, req.Panoramic)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -46,42 +54,26 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 }
 
 func (h *RoomHandler) GetAllRooms(c *gin.Context) {
-	limit := 10
-	offset := 0
-
-	rangeParam := c.Query("range")
-	if rangeParam != "" {
-		var rangeSlice []int
-		if err := json.Unmarshal([]byte(rangeParam), &rangeSlice); err == nil && len(rangeSlice) == 2 {
-			offset = rangeSlice[0]
-			limit = rangeSlice[1] - rangeSlice[0] + 1
-		}
-	}
-
-	filterParam := c.Query("filter")
-	var unitID string
-	if filterParam != "" {
-		var filter map[string]interface{}
-		if err := json.Unmarshal([]byte(filterParam), &filter); err == nil {
-			if val, ok := filter["unit_id"].(string); ok {
-				unitID = val
-			}
-		}
+	query, ok := listQuery(c)
+	if !ok {
+		return
 	}
 
-	rooms, total, err := h.service.GetAllRooms(c.Request.Context(), limit, offset, unitID)
+	rooms, total, err := h.service.GetAllRooms(c.Request.Context(), query)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
 		return
 	}
 
-	end := offset + len(rooms) - 1
-	if len(rooms) == 0 {
-		end = offset
-	}
-	c.Header("Content-Range", fmt.Sprintf("rooms %d-%d/%d", offset, end, total))
-
+	c.Header("Content-Range", query.ContentRange("rooms", len(rooms), total))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Rooms retrieved successfully", rooms))
 }
 
@@ -89,6 +81,10 @@ func (h *RoomHandler) GetRoomByID(c *gin.Context) {
 	id := c.Param("id")
 	room, err := h.service.GetRoomByID(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -98,19 +94,65 @@ func (h *RoomHandler) GetRoomByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, room.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Room retrieved successfully", room))
 }
 
 func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 	id := c.Param("id")
 	var req UpdateRoomRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name/unit_id", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.service.UpdateRoom(c.Request.Context(), id, req.Name, req.UnitID, req.Panoramic, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if room == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Room not found", nil))
+		return
+	}
+
+	c.Header("ETag", etag(room.Version))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Room updated successfully", room))
+}
+
+func (h *RoomHandler) PatchRoom(c *gin.Context) {
+	id := c.Param("id")
+	var patch service.RoomPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	room, err := h.service.UpdateRoom(c.Request.Context(), id, req.Name, req.UnitID)
+	room, err := h.service.PatchRoom(c.Request.Context(), id, patch, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -120,13 +162,22 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(room.Version))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Room updated successfully", room))
 }
 
 func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 	id := c.Param("id")
-	err := h.service.DeleteRoom(c.Request.Context(), id)
+	err := h.service.DeleteRoom(c.Request.Context(), id, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
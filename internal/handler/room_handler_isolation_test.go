@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomHandler_Isolation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer)}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+	clientID := "customer-1"
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		roomID         string
+		call           func(h *RoomHandler, c *gin.Context)
+		setupRepos     func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository)
+		expectedStatus int
+	}{
+		{
+			name:   "Company user reading own room - Allowed",
+			user:   companyUser,
+			method: http.MethodGet,
+			roomID: "room-1",
+			call:   (*RoomHandler).GetRoomByID,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				rooms.EXPECT().FindByID(gomock.Any(), "room-1").Return(&model.Room{ID: "room-1"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Company user reading other company's room - Forbidden",
+			user:   companyUser,
+			method: http.MethodGet,
+			roomID: "room-2",
+			call:   (*RoomHandler).GetRoomByID,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+				// Room should NOT be loaded
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user updating other company's room - Forbidden",
+			user:   companyUser,
+			method: http.MethodPut,
+			body:   `{"name":"Kitchen","unit_id":"unit-1"}`,
+			roomID: "room-2",
+			call:   (*RoomHandler).UpdateRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user moving own room to other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodPut,
+			body:   `{"name":"Kitchen","unit_id":"unit-2"}`,
+			roomID: "room-1",
+			call:   (*RoomHandler).UpdateRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				rooms.EXPECT().FindByID(gomock.Any(), "room-1").Return(&model.Room{ID: "room-1", UnitID: "unit-1"}, nil)
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user deleting other company's room - Forbidden",
+			user:   companyUser,
+			method: http.MethodDelete,
+			roomID: "room-2",
+			call:   (*RoomHandler).DeleteRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user creating room in other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Bathroom","unit_id":"unit-2"}`,
+			call:   (*RoomHandler).CreateRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user creating room in a trashed unit - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Bathroom","unit_id":"unit-3"}`,
+			call:   (*RoomHandler).CreateRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				// A trashed unit is left out of the lookup and refused on insert like a missing one
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-3").Return(nil, nil)
+				rooms.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&pkgRepository.ReferenceError{Field: "unit_id"})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Company user listing rooms - Scoped to own company",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*RoomHandler).GetAllRooms,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				rooms.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), repository.TenantScope{CompanyID: "company-123"}).Return([]*model.Room{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer reading room of assigned unit - Allowed",
+			user:   customer,
+			method: http.MethodGet,
+			roomID: "room-1",
+			call:   (*RoomHandler).GetRoomByID,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-1").Return(&repository.Ownership{CompanyID: "company-123", ClientID: &clientID}, nil)
+				rooms.EXPECT().FindByID(gomock.Any(), "room-1").Return(&model.Room{ID: "room-1"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer reading room of someone else's unit - Forbidden",
+			user:   customer,
+			method: http.MethodGet,
+			roomID: "room-2",
+			call:   (*RoomHandler).GetRoomByID,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().RoomOwnership(gomock.Any(), "room-2").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Admin user deleting any room - Allowed",
+			user:   admin,
+			method: http.MethodDelete,
+			roomID: "room-2",
+			call:   (*RoomHandler).DeleteRoom,
+			setupRepos: func(rooms *mock_repository.MockRoomRepository, tenants *mock_repository.MockTenantRepository) {
+				rooms.EXPECT().FindByID(gomock.Any(), "room-2").Return(&model.Room{ID: "room-2"}, nil)
+				rooms.EXPECT().Delete(gomock.Any(), "room-2", int64(0)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rooms := mock_repository.NewMockRoomRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepos(rooms, tenants)
+			handler := NewRoomHandler(service.NewRoomService(rooms, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/rooms/"+tt.roomID, tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.roomID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/hfleury/bk_globalshot/internal/model"
 	mock_services "github.com/hfleury/bk_globalshot/mock/services"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,7 +33,7 @@ func TestCreateRoom(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	mockService.EXPECT().CreateRoom(gomock.Any(), "Living Room", "unit-123").Return(room, nil)
+	mockService.EXPECT().CreateRoom(gomock.Any(), "Living Room", "unit-123", nil).Return(room, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -63,7 +64,7 @@ func TestGetAllRooms(t *testing.T) {
 		{ID: "2", Name: "Room 2", UnitID: "u1"},
 	}
 
-	mockService.EXPECT().GetAllRooms(gomock.Any(), 10, 0, "").Return(rooms, int64(2), nil)
+	mockService.EXPECT().GetAllRooms(gomock.Any(), listquery.New(listquery.DefaultLimit)).Return(rooms, int64(2), nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
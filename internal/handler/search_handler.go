@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// searchMinLength keeps single letters from matching half the tenant.
+const searchMinLength = 2
+
+type SearchHandler struct {
+	service service.SearchService
+}
+
+func NewSearchHandler(service service.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search answers ?q=riverside 101, optionally narrowed to ?type=unit,room and capped with
+// ?limit=, best matches first.
+func (h *SearchHandler) Search(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if utf8.RuneCountInString(text) < searchMinLength {
+		c.JSON(http.StatusBadRequest, dto.ValidationError("q", "Search text must be at least 2 characters long", dto.ErrorCodeValidationFailed))
+		return
+	}
+
+	var types []model.SearchResultType
+	if value := c.Query("type"); value != "" {
+		for _, t := range strings.Split(value, ",") {
+			t = strings.TrimSpace(t)
+			if !model.IsValidSearchResultType(t) {
+				c.JSON(http.StatusBadRequest, dto.ValidationError("type", "Type must be site, unit, room or user", dto.ErrorCodeInvalidFormat))
+				return
+			}
+			types = append(types, model.SearchResultType(t))
+		}
+	}
+
+	limit := listquery.DefaultLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, dto.ValidationError("limit", "Limit must be a positive number", dto.ErrorCodeInvalidFormat))
+			return
+		}
+		limit = min(n, listquery.MaxLimit)
+	}
+
+	results, err := h.service.Search(c.Request.Context(), text, types, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Search results retrieved successfully", results))
+}
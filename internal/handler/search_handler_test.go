@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	homeless := &model.User{ID: "user-2", Role: string(model.RoleCompany)}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer)}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		query          string
+		setupRepo      func(r *mock_repository.MockSearchRepository)
+		expectedStatus int
+	}{
+		{
+			name:  "Company user - Every type scoped to own company",
+			user:  companyUser,
+			query: "?q=Riverside%20101",
+			setupRepo: func(r *mock_repository.MockSearchRepository) {
+				own := repository.TenantScope{CompanyID: "company-123"}
+				r.EXPECT().Search(gomock.Any(), repository.SearchQuery{
+					Text:   "Riverside 101",
+					Scopes: map[model.SearchResultType]repository.TenantScope{model.SearchResultSite: own, model.SearchResultUnit: own, model.SearchResultRoom: own, model.SearchResultUser: own},
+					Limit:  10,
+				}).Return([]*model.SearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Customer - Scoped to what is assigned to them",
+			user:  customer,
+			query: "?q=kitchen&type=room",
+			setupRepo: func(r *mock_repository.MockSearchRepository) {
+				r.EXPECT().Search(gomock.Any(), repository.SearchQuery{
+					Text:   "kitchen",
+					Scopes: map[model.SearchResultType]repository.TenantScope{model.SearchResultRoom: {CustomerID: "customer-1"}},
+					Limit:  10,
+				}).Return([]*model.SearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Admin narrowing types with a large limit - Capped",
+			user:  admin,
+			query: "?q=tower&type=unit,%20room&limit=500",
+			setupRepo: func(r *mock_repository.MockSearchRepository) {
+				r.EXPECT().Search(gomock.Any(), repository.SearchQuery{
+					Text:   "tower",
+					Scopes: map[model.SearchResultType]repository.TenantScope{model.SearchResultUnit: {}, model.SearchResultRoom: {}},
+					Limit:  100,
+				}).Return([]*model.SearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Company user without a company - Forbidden",
+			user:           homeless,
+			query:          "?q=tower",
+			setupRepo:      func(r *mock_repository.MockSearchRepository) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Single letter - Bad Request",
+			user:           admin,
+			query:          "?q=%20a%20",
+			setupRepo:      func(r *mock_repository.MockSearchRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown type - Bad Request",
+			user:           admin,
+			query:          "?q=tower&type=company",
+			setupRepo:      func(r *mock_repository.MockSearchRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Malformed limit - Bad Request",
+			user:           admin,
+			query:          "?q=tower&limit=0",
+			setupRepo:      func(r *mock_repository.MockSearchRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockSearchRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepo(repo)
+			handler := NewSearchHandler(service.NewSearchService(repo, service.NewAuthorizer(authz.Default, tenants)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, http.MethodGet, "/search"+tt.query, "")
+
+			handler.Search(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestSearchResultPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repository.NewMockSearchRepository(ctrl)
+	tenants := mock_repository.NewMockTenantRepository(ctrl)
+	repo.EXPECT().Search(gomock.Any(), gomock.Any()).Return([]*model.SearchResult{{
+		Type:  model.SearchResultUnit,
+		ID:    "unit-1",
+		Label: "Apartment 101",
+		Path: []model.SearchPathItem{
+			{Type: "company", ID: "company-123", Label: "Acme"},
+			{Type: "site", ID: "site-1", Label: "Riverside"},
+		},
+		Rank: 0.6,
+	}}, nil)
+	handler := NewSearchHandler(service.NewSearchService(repo, service.NewAuthorizer(authz.Default, tenants)))
+
+	w := httptest.NewRecorder()
+	user := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	c := newTenantContext(w, user, http.MethodGet, "/search?q=apartment%20riverside", "")
+
+	handler.Search(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []model.SearchResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, model.SearchResultUnit, response.Data[0].Type)
+	assert.Equal(t, []string{"Acme", "Riverside"}, []string{response.Data[0].Path[0].Label, response.Data[0].Path[1].Label})
+}
@@ -1,15 +1,13 @@
 package handler
 
 import (
-	"context"
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
-	"github.com/hfleury/bk_globalshot/internal/model"
-	"github.com/hfleury/bk_globalshot/internal/router/middleware"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 type SiteHandler struct {
@@ -33,13 +31,19 @@ type UpdateSiteRequest struct {
 
 func (h *SiteHandler) CreateSite(c *gin.Context) {
 	var req CreateSiteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name/company_id", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	site, err := h.service.CreateSite(c.Request.Context(), req.Name, req.Address, req.CompanyID)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -49,37 +53,26 @@ func (h *SiteHandler) CreateSite(c *gin.Context) {
 }
 
 func (h *SiteHandler) GetAllSites(c *gin.Context) {
-	limit := 10
-	offset := 0
-
-	// Handle pagination if needed, similar to other handlers
-	// Skipping explicit Range header parsing for brevity unless required by frontend framework (Ra-React Admin often uses Range)
-
-	// Retrieve auth payload to ensure authentication passed (middleware handles this, but we can access it)
-	payload := middleware.GetAuthPayload(c)
-	if payload == nil {
-		c.JSON(http.StatusUnauthorized, dto.ResponseError("Unauthorized", nil))
+	query, ok := listQuery(c)
+	if !ok {
 		return
 	}
 
-	// Create a partial user model from payload to satisfy service layer expectations
-	user := &model.User{
-		ID:        payload.UserID, // Assuming UserID is the field name, will verify in token.go
-		Role:      payload.Role,
-		CompanyID: payload.CompanyID,
-	}
-
-	// Add user to context for service layer
-	ctx := context.WithValue(c.Request.Context(), "user", user)
-
-	sites, total, err := h.service.GetAllSites(ctx, limit, offset)
+	sites, total, err := h.service.GetAllSites(c.Request.Context(), query)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
 		return
 	}
 
-	c.Header("Content-Range", fmt.Sprintf("sites %d-%d/%d", offset, offset+len(sites)-1, total))
+	c.Header("Content-Range", query.ContentRange("sites", len(sites), total))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Sites retrieved successfully", sites))
 }
 
@@ -87,6 +80,10 @@ func (h *SiteHandler) GetSiteByID(c *gin.Context) {
 	id := c.Param("id")
 	site, err := h.service.GetSiteByID(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -96,19 +93,59 @@ func (h *SiteHandler) GetSiteByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, site.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Site retrieved successfully", site))
 }
 
 func (h *SiteHandler) UpdateSite(c *gin.Context) {
 	id := c.Param("id")
 	var req UpdateSiteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	site, err := h.service.UpdateSite(c.Request.Context(), id, req.Name, req.Address, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if site == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Site not found", nil))
+		return
+	}
+
+	c.Header("ETag", etag(site.Version))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Site updated successfully", site))
+}
+
+func (h *SiteHandler) PatchSite(c *gin.Context) {
+	id := c.Param("id")
+	var patch service.SitePatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	site, err := h.service.UpdateSite(c.Request.Context(), id, req.Name, req.Address)
+	site, err := h.service.PatchSite(c.Request.Context(), id, patch, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -118,13 +155,22 @@ func (h *SiteHandler) UpdateSite(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(site.Version))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Site updated successfully", site))
 }
 
 func (h *SiteHandler) DeleteSite(c *gin.Context) {
 	id := c.Param("id")
-	err := h.service.DeleteSite(c.Request.Context(), id)
+	err := h.service.DeleteSite(c.Request.Context(), id, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
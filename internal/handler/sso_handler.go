@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+)
+
+// ssoStateCookie keeps the state of a login in the browser that started it. The callback
+// only accepts the state it holds, a link to finish a login started elsewhere is worthless.
+const (
+	ssoStateCookie     = "sso_state"
+	ssoStateCookiePath = "/v1/auth/oidc"
+)
+
+type SSOHandler struct {
+	service service.SSOService
+}
+
+func NewSSOHandler(service service.SSOService) *SSOHandler {
+	return &SSOHandler{service: service}
+}
+
+type ConfigureSSORequest struct {
+	Issuer       string `json:"issuer" binding:"required,url"`
+	ClientID     string `json:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" binding:"required"`
+}
+
+type CompleteSSORequest struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+type SSOAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ConfigureSSO sets the identity provider of a company. The client secret is never returned.
+func (h *SSOHandler) ConfigureSSO(c *gin.Context) {
+	var req ConfigureSSORequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	company, err := h.service.ConfigureCompany(c.Request.Context(), c.Param("id"), req.Issuer, req.ClientID, req.ClientSecret)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrInvalidSSOSettings):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("issuer", "Identity provider could not be discovered at this issuer", dto.ErrorCodeInvalidFormat))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+	if company == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Company not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Single sign-on configured successfully", company))
+}
+
+func (h *SSOHandler) DisableSSO(c *gin.Context) {
+	company, err := h.service.DisableCompany(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if company == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Company not found", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Single sign-on disabled successfully", company))
+}
+
+// BeginSSO is public, it returns where the browser must go to sign in with the company's provider.
+func (h *SSOHandler) BeginSSO(c *gin.Context) {
+	login, err := h.service.BeginLogin(c.Request.Context(), c.Param("companyId"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSSONotConfigured):
+			c.JSON(http.StatusNotFound, dto.ResponseError("Single sign-on is not available for this company", nil))
+		case errors.Is(err, service.ErrSSOLoginFailed):
+			c.JSON(http.StatusBadGateway, dto.ResponseError("Identity provider is unreachable", nil))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	setSSOStateCookie(c, login.State, 0)
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Redirect to the identity provider", SSOAuthorizationResponse{AuthorizationURL: login.AuthorizationURL}))
+}
+
+// CompleteSSO is public, the frontend posts what the provider sent to the redirect URL.
+func (h *SSOHandler) CompleteSSO(c *gin.Context) {
+	var req CompleteSSORequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	cookie, err := c.Cookie(ssoStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(req.State)) != 1 {
+		c.JSON(http.StatusBadRequest, dto.ValidationError("state", "Login was started in another browser, please start again", dto.ErrorCodeInvalidFormat))
+		return
+	}
+	setSSOStateCookie(c, "", -1)
+
+	result, err := h.service.CompleteLogin(c.Request.Context(), req.State, req.Code, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSSOState):
+			c.JSON(http.StatusBadRequest, dto.ValidationError("state", "Login has expired, please start again", dto.ErrorCodeInvalidFormat))
+		case errors.Is(err, service.ErrSSONotConfigured):
+			c.JSON(http.StatusNotFound, dto.ResponseError("Single sign-on is not available for this company", nil))
+		case errors.Is(err, service.ErrSSOLoginFailed), errors.Is(err, service.ErrSSOEmailNotVerified):
+			c.JSON(http.StatusUnauthorized, dto.UnauthorizedResponse("Identity provider did not confirm the login"))
+		case errors.Is(err, service.ErrSSOAccountConflict):
+			c.JSON(http.StatusConflict, dto.ResponseError("This email is already used by an account outside of the company", nil))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	if result.Challenge != nil {
+		c.JSON(http.StatusAccepted, dto.ResponseSuccess("Two-factor authentication required", result.Challenge))
+		return
+	}
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Login successful", result.Tokens))
+}
+
+// setSSOStateCookie stores the state for the callback, a negative maxAge removes it. The
+// cookie lives as long as the browser session, the state expires on the server before that.
+// It is sent cross-site, the frontend may live on another site than the API: another site
+// can make the browser send it but never learn or set it.
+func setSSOStateCookie(c *gin.Context, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    state,
+		Path:     ssoStateCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	paseto "aidanwoods.dev/go-paseto"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_db "github.com/hfleury/bk_globalshot/mock/db"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	mock_services "github.com/hfleury/bk_globalshot/mock/services"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/oidc/oidctest"
+	"github.com/hfleury/bk_globalshot/pkg/secretbox"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ssoMocks struct {
+	db        *mock_db.MockDb
+	sso       *mock_repository.MockSSORepository
+	companies *mock_repository.MockCompanyRepository
+	users     *mock_repository.MockUserRepository
+	auth      *mock_services.MockAuthService
+	audit     *mock_services.MockAuditService
+}
+
+func TestSSOLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+	secrets := newSSOSecrets(t)
+
+	company := &model.Company{
+		ID:               "company-123",
+		OIDCIssuer:       &idp.Issuer,
+		OIDCClientID:     &idp.ClientID,
+		OIDCClientSecret: sealSecret(t, secrets, idp.ClientSecret),
+	}
+	tokens := &service.AuthTokens{AccessToken: "access", RefreshToken: "refresh", Role: string(model.RoleCompany)}
+
+	tests := []struct {
+		name           string
+		identity       oidctest.Identity
+		state          string // Replaces the state the provider sends back when set
+		foreignBrowser bool   // The callback comes from a browser that did not start the login
+		setupRepos     func(m ssoMocks)
+		expectedStatus int
+	}{
+		{
+			name:     "First login - User provisioned as company user",
+			identity: oidctest.Identity{Subject: "idp-1", Email: "Jane@Builder.example", EmailVerified: true},
+			setupRepos: func(m ssoMocks) {
+				m.sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-1").Return(nil, nil)
+				m.users.EXPECT().FindByEmail(gomock.Any(), "jane@builder.example").Return(nil, nil)
+				m.db.EXPECT().BegrinTransaction(gomock.Any()).Return((*sql.Tx)(nil), nil)
+				m.users.EXPECT().WithTx(gomock.Any()).Return(m.users)
+				m.sso.EXPECT().WithTx(gomock.Any()).Return(m.sso)
+				m.users.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *model.User) error {
+					assert.Equal(t, "jane@builder.example", u.Email)
+					assert.Equal(t, string(model.RoleCompany), u.Role)
+					assert.Equal(t, "company-123", u.CompanyID)
+					assert.NotEmpty(t, u.Password)
+					return nil
+				})
+				m.sso.EXPECT().CreateIdentity(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, i *model.UserIdentity) error {
+					assert.Equal(t, idp.Issuer, i.Issuer)
+					assert.Equal(t, "idp-1", i.Subject)
+					return nil
+				})
+				m.db.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil)
+				m.audit.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ interface{}, entry service.AuditEntry) {
+					assert.Equal(t, model.AuditActionCreate, entry.Action)
+					assert.Equal(t, model.AuditResourceUser, entry.ResourceType)
+					assert.Equal(t, "company-123", entry.CompanyID)
+				})
+				m.auth.EXPECT().LoginWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *model.User, _ service.ClientInfo) (*service.LoginResult, error) {
+					assert.Equal(t, "company-123", u.CompanyID)
+					return &service.LoginResult{Tokens: tokens}, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Returning user - Signed in by identity",
+			identity: oidctest.Identity{Subject: "idp-2", Email: "renamed@builder.example"},
+			setupRepos: func(m ssoMocks) {
+				m.sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-2").Return(&model.UserIdentity{UserID: "user-2"}, nil)
+				m.users.EXPECT().FindByID(gomock.Any(), "user-2").Return(&model.User{ID: "user-2", Role: string(model.RoleCompany), CompanyID: "company-123"}, nil)
+				m.auth.EXPECT().LoginWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.LoginResult{Tokens: tokens}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Existing company user - Identity linked",
+			identity: oidctest.Identity{Subject: "idp-3", Email: "staff@builder.example", EmailVerified: true},
+			setupRepos: func(m ssoMocks) {
+				m.sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-3").Return(nil, nil)
+				m.users.EXPECT().FindByEmail(gomock.Any(), "staff@builder.example").Return(&model.User{ID: "user-3", Role: string(model.RoleCompany), CompanyID: "company-123"}, nil)
+				m.db.EXPECT().BegrinTransaction(gomock.Any()).Return((*sql.Tx)(nil), nil)
+				m.users.EXPECT().WithTx(gomock.Any()).Return(m.users)
+				m.sso.EXPECT().WithTx(gomock.Any()).Return(m.sso)
+				m.sso.EXPECT().CreateIdentity(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, i *model.UserIdentity) error {
+					assert.Equal(t, "user-3", i.UserID)
+					return nil
+				})
+				m.db.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil)
+				m.auth.EXPECT().LoginWithIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.LoginResult{Tokens: tokens}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Email of a customer - Conflict",
+			identity: oidctest.Identity{Subject: "idp-4", Email: "owner@home.example", EmailVerified: true},
+			setupRepos: func(m ssoMocks) {
+				m.sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-4").Return(nil, nil)
+				m.users.EXPECT().FindByEmail(gomock.Any(), "owner@home.example").Return(&model.User{ID: "customer-1", Role: string(model.RoleCustomer), CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:     "Unverified email - Unauthorized",
+			identity: oidctest.Identity{Subject: "idp-5", Email: "staff@builder.example"},
+			setupRepos: func(m ssoMocks) {
+				m.sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-5").Return(nil, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "Unknown state - Bad Request",
+			identity: oidctest.Identity{Subject: "idp-1", Email: "jane@builder.example", EmailVerified: true},
+			state:    "forged",
+			setupRepos: func(m ssoMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Callback from another browser - Bad Request",
+			identity:       oidctest.Identity{Subject: "idp-1", Email: "jane@builder.example", EmailVerified: true},
+			foreignBrowser: true,
+			setupRepos: func(m ssoMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := ssoMocks{
+				db:        mock_db.NewMockDb(ctrl),
+				sso:       mock_repository.NewMockSSORepository(ctrl),
+				companies: mock_repository.NewMockCompanyRepository(ctrl),
+				users:     mock_repository.NewMockUserRepository(ctrl),
+				auth:      mock_services.NewMockAuthService(ctrl),
+				audit:     mock_services.NewMockAuditService(ctrl),
+			}
+			m.companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(company, nil).AnyTimes()
+
+			// The state store hands back what was stored, once
+			states := map[string]*model.OIDCLoginState{}
+			m.sso.EXPECT().CreateState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, s *model.OIDCLoginState) error {
+				states[s.StateHash] = s
+				return nil
+			})
+			m.sso.EXPECT().ConsumeState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, hash string) (*model.OIDCLoginState, error) {
+				s := states[hash]
+				delete(states, hash)
+				return s, nil
+			}).MaxTimes(1)
+			tt.setupRepos(m)
+
+			cfgOIDC := &config.ConfigOIDC{RedirectURL: "http://localhost:5173/sso/callback", StateExpiry: time.Minute}
+			svc := service.NewSSOService(m.db, m.sso, m.companies, m.users, m.auth, service.NewAuthorizer(authz.Default, nil), m.audit, cfgOIDC, secrets, idp.HTTPClient())
+			handler := NewSSOHandler(svc)
+
+			w := completeSSOLogin(t, handler, idp, tt.identity, tt.state, !tt.foreignBrowser)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.foreignBrowser {
+				assert.Len(t, states, 1, "The login stays open for the browser that started it")
+			}
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"refresh_token":"refresh"`)
+			}
+		})
+	}
+}
+
+// newSSOSecrets returns the box client secrets are sealed with in the tests.
+func newSSOSecrets(t *testing.T) *secretbox.Box {
+	secrets, err := secretbox.New("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	require.NoError(t, err)
+	return secrets
+}
+
+func sealSecret(t *testing.T, secrets *secretbox.Box, secret string) *string {
+	sealed, err := secrets.Seal(secret)
+	require.NoError(t, err)
+	return &sealed
+}
+
+// completeSSOLogin goes through a login with the provider as the browser would, state replacing
+// the one the provider sends back, in the callback and in the cookie, when set. Without
+// keepCookie the callback comes without the cookie BeginSSO set.
+func completeSSOLogin(t *testing.T, handler *SSOHandler, idp *oidctest.IdP, identity oidctest.Identity, state string, keepCookie bool) *httptest.ResponseRecorder {
+	// Ask where to sign in
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/oidc/company-123/authorize", nil)
+	c.Params = []gin.Param{{Key: "companyId", Value: "company-123"}}
+	handler.BeginSSO(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var begin struct {
+		Data SSOAuthorizationResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &begin))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	assert.True(t, cookie.HttpOnly)
+
+	// Sign in at the provider, which redirects back with a code
+	idp.SignIn(identity)
+	code, returnedState, err := idp.Authorize(begin.Data.AuthorizationURL)
+	require.NoError(t, err)
+	assert.Equal(t, returnedState, cookie.Value)
+	if state == "" {
+		state = returnedState
+	}
+	cookie.Value = state
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	body := `{"state":"` + state + `","code":"` + code + `"}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/oidc/callback", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if keepCookie {
+		c.Request.AddCookie(cookie)
+	}
+	handler.CompleteSSO(c)
+	return w
+}
+
+func TestSSOLoginSecondFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+	secrets := newSSOSecrets(t)
+
+	company := &model.Company{
+		ID:               "company-123",
+		OIDCIssuer:       &idp.Issuer,
+		OIDCClientID:     &idp.ClientID,
+		OIDCClientSecret: sealSecret(t, secrets, idp.ClientSecret),
+	}
+	user := &model.User{ID: "user-2", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	maker, err := token.NewPasetoMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		required       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Second factor enabled - Challenged",
+			enabled:        true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"enrollment_required":false`,
+		},
+		{
+			name:           "Second factor required by the role - Challenged to enroll",
+			required:       true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"enrollment_required":true`,
+		},
+		{
+			name:           "No second factor - Signed in",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"refresh_token":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sso := mock_repository.NewMockSSORepository(ctrl)
+			companies := mock_repository.NewMockCompanyRepository(ctrl)
+			users := mock_repository.NewMockUserRepository(ctrl)
+			sessions := mock_repository.NewMockAuthSessionRepository(ctrl)
+			mfaRepo := mock_repository.NewMockMFARepository(ctrl)
+			mfa := mock_services.NewMockMFAService(ctrl)
+			// No expectation on the lockouts, an SSO login must leave them alone
+			lockouts := mock_repository.NewMockLoginLockoutRepository(ctrl)
+
+			companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(company, nil).AnyTimes()
+			states := map[string]*model.OIDCLoginState{}
+			sso.EXPECT().CreateState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, s *model.OIDCLoginState) error {
+				states[s.StateHash] = s
+				return nil
+			})
+			sso.EXPECT().ConsumeState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, hash string) (*model.OIDCLoginState, error) {
+				return states[hash], nil
+			})
+			sso.EXPECT().FindIdentity(gomock.Any(), idp.Issuer, "idp-2").Return(&model.UserIdentity{UserID: "user-2"}, nil)
+			users.EXPECT().FindByID(gomock.Any(), "user-2").Return(user, nil)
+			mfa.EXPECT().IsEnabled(gomock.Any(), "user-2").Return(tt.enabled, nil)
+			mfa.EXPECT().IsRequired(user.Role).Return(tt.required).AnyTimes()
+			if tt.expectedStatus == http.StatusAccepted {
+				mfaRepo.EXPECT().CreateChallenge(gomock.Any(), gomock.Any()).Return(nil)
+			} else {
+				sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			cfgToken := &config.ConfigToken{TokenExpiry: time.Minute, RefreshTokenExpiry: time.Hour}
+			cfgMFA := &config.ConfigMFA{ChallengeExpiry: time.Minute}
+			auth := service.NewAuthService(users, sessions, nil, mfaRepo, mfa, lockouts, maker, nil, cfgToken, cfgMFA, &config.ConfigRateLimit{})
+			cfgOIDC := &config.ConfigOIDC{RedirectURL: "http://localhost:5173/sso/callback", StateExpiry: time.Minute}
+			svc := service.NewSSOService(nil, sso, companies, users, auth, service.NewAuthorizer(authz.Default, nil), newAuditRecorder(ctrl), cfgOIDC, secrets, idp.HTTPClient())
+
+			w := completeSSOLogin(t, NewSSOHandler(svc), idp, oidctest.Identity{Subject: "idp-2", Email: "staff@builder.example"}, "", true)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestConfigureSSO(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+	secrets := newSSOSecrets(t)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		targetID       string
+		body           string
+		setupRepos     func(m ssoMocks)
+		expectedStatus int
+	}{
+		{
+			name:     "Admin configuring a company - OK, secret not returned",
+			user:     admin,
+			targetID: "company-123",
+			body:     `{"issuer":"` + idp.Issuer + `","client_id":"globalshot","client_secret":"client-secret"}`,
+			setupRepos: func(m ssoMocks) {
+				m.companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(&model.Company{ID: "company-123"}, nil)
+				m.companies.EXPECT().UpdateSSO(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, c *model.Company) error {
+					assert.Equal(t, idp.Issuer, *c.OIDCIssuer)
+					assert.True(t, secretbox.IsSealed(*c.OIDCClientSecret))
+					secret, err := secrets.Open(*c.OIDCClientSecret)
+					require.NoError(t, err)
+					assert.Equal(t, "client-secret", secret)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Issuer without a provider - Bad Request",
+			user:     admin,
+			targetID: "company-123",
+			body:     `{"issuer":"` + idp.Issuer + `/elsewhere","client_id":"globalshot","client_secret":"client-secret"}`,
+			setupRepos: func(m ssoMocks) {
+				m.companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(&model.Company{ID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "Issuer over plain http - Bad Request",
+			user:     admin,
+			targetID: "company-123",
+			body:     `{"issuer":"` + strings.Replace(idp.Issuer, "https://", "http://", 1) + `","client_id":"globalshot","client_secret":"client-secret"}`,
+			setupRepos: func(m ssoMocks) {
+				m.companies.EXPECT().FindByID(gomock.Any(), "company-123").Return(&model.Company{ID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "Company user configuring other company - Forbidden",
+			user:     companyUser,
+			targetID: "company-456",
+			body:     `{"issuer":"` + idp.Issuer + `","client_id":"globalshot","client_secret":"client-secret"}`,
+			setupRepos: func(m ssoMocks) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Missing client secret - Bad Request",
+			user:     admin,
+			targetID: "company-123",
+			body:     `{"issuer":"` + idp.Issuer + `","client_id":"globalshot"}`,
+			setupRepos: func(m ssoMocks) {
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := ssoMocks{
+				companies: mock_repository.NewMockCompanyRepository(ctrl),
+			}
+			tt.setupRepos(m)
+
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			svc := service.NewSSOService(nil, nil, m.companies, nil, nil, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl), &config.ConfigOIDC{}, secrets, idp.HTTPClient())
+			handler := NewSSOHandler(svc)
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, http.MethodPut, "/companies/"+tt.targetID+"/oidc", tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.targetID}}
+
+			handler.ConfigureSSO(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "client-secret")
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type TrashHandler struct {
+	service service.TrashService
+}
+
+func NewTrashHandler(service service.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// GetTrash lists what was deleted, filterable on type, company_id and restorable.
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
+
+	items, total, err := h.service.GetTrash(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+
+	c.Header("Content-Range", query.ContentRange("trash", len(items), total))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Trash retrieved successfully", items))
+}
+
+func (h *TrashHandler) RestoreCompany(c *gin.Context) {
+	h.restore(c, model.TrashItemCompany, "Company")
+}
+
+func (h *TrashHandler) RestoreSite(c *gin.Context) {
+	h.restore(c, model.TrashItemSite, "Site")
+}
+
+func (h *TrashHandler) RestoreUnit(c *gin.Context) {
+	h.restore(c, model.TrashItemUnit, "Unit")
+}
+
+func (h *TrashHandler) RestoreRoom(c *gin.Context) {
+	h.restore(c, model.TrashItemRoom, "Room")
+}
+
+func (h *TrashHandler) restore(c *gin.Context, itemType model.TrashItemType, name string) {
+	item, err := h.service.Restore(c.Request.Context(), itemType, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, service.ErrTrashParentDeleted):
+			c.JSON(http.StatusConflict, dto.ResponseError(name+" is inside something that is in the trash too, restore that first", nil))
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError(name+" not found in the trash", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess(name+" restored successfully", item))
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTrashConfig = &config.ConfigTrash{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour}
+
+func TestGetTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deletedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		setupRepo      func(r *mock_repository.MockTrashRepository)
+		expectedStatus int
+	}{
+		{
+			name: "Admin - Allowed",
+			user: &model.User{ID: "admin-1", Role: string(model.RoleAdmin)},
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit)).Return([]*model.TrashItem{
+					{Type: model.TrashItemSite, ID: "site-1", CompanyID: "company-123", DeletedAt: deletedAt, Restorable: true},
+				}, int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Company user - Forbidden",
+			user:           &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"},
+			setupRepo:      func(r *mock_repository.MockTrashRepository) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockTrashRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepo(repo)
+			handler := NewTrashHandler(service.NewTrashService(repo, nil, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl), testTrashConfig))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, http.MethodGet, "/trash", "")
+
+			handler.GetTrash(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "trash 0-0/1", w.Header().Get("Content-Range"))
+				assert.Contains(t, w.Body.String(), `"purge_at":"2026-03-31T12:00:00Z"`)
+			}
+		})
+	}
+}
+
+func TestRestoreSite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer)}
+	trashed := func(restorable bool) *model.TrashItem {
+		return &model.TrashItem{Type: model.TrashItemSite, ID: "site-1", Name: "Riverside", CompanyID: "company-123", DeletedAt: time.Now().Add(-time.Hour), Restorable: restorable}
+	}
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		setupRepo      func(r *mock_repository.MockTrashRepository)
+		expectedStatus int
+	}{
+		{
+			name: "Company user restoring own site - Restored",
+			user: companyUser,
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindByID(gomock.Any(), model.TrashItemSite, "site-1").Return(trashed(true), nil)
+				r.EXPECT().Restore(gomock.Any(), model.TrashItemSite, "site-1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Company in the trash too - Conflict",
+			user: companyUser,
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindByID(gomock.Any(), model.TrashItemSite, "site-1").Return(trashed(false), nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Another company's site - Forbidden",
+			user: &model.User{ID: "user-2", Role: string(model.RoleCompany), CompanyID: "company-456"},
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindByID(gomock.Any(), model.TrashItemSite, "site-1").Return(trashed(true), nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Customer - Forbidden",
+			user: customer,
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindByID(gomock.Any(), model.TrashItemSite, "site-1").Return(trashed(true), nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Not in the trash - Not Found",
+			user: companyUser,
+			setupRepo: func(r *mock_repository.MockTrashRepository) {
+				r.EXPECT().FindByID(gomock.Any(), model.TrashItemSite, "site-1").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockTrashRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepo(repo)
+			handler := NewTrashHandler(service.NewTrashService(repo, nil, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl), testTrashConfig))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, http.MethodPost, "/sites/site-1/restore", "")
+			c.Params = []gin.Param{{Key: "id", Value: "site-1"}}
+
+			handler.RestoreSite(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	for _, key := range []string{"rooms/room-1/media-1.jpg", "rooms/room-1/media-1/preview.jpg", "rooms/room-2/media-2.jpg"} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("jpeg"), storage.PutOptions{Size: 4})
+		require.NoError(t, err)
+	}
+
+	var before time.Time
+	repo := mock_repository.NewMockTrashRepository(ctrl)
+	repo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, b time.Time) (*repository.PurgedTrash, error) {
+		before = b
+		return &repository.PurgedTrash{RoomIDs: []string{"room-1"}, Units: 1}, nil
+	})
+
+	require.NoError(t, service.NewTrashService(repo, store, nil, nil, testTrashConfig).Purge(context.Background()))
+
+	assert.WithinDuration(t, time.Now().Add(-testTrashConfig.Retention), before, time.Minute)
+	left, err := store.List(context.Background(), "rooms/")
+	require.NoError(t, err)
+	require.Len(t, left, 1)
+	assert.Equal(t, "rooms/room-2/media-2.jpg", left[0].Key)
+}
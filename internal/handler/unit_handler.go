@@ -1,12 +1,13 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 type UnitHandler struct {
@@ -33,13 +34,19 @@ type UpdateUnitRequest struct {
 
 func (h *UnitHandler) CreateUnit(c *gin.Context) {
 	var req CreateUnitRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name/type/site_id", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	unit, err := h.service.CreateUnit(c.Request.Context(), req.Name, req.Type, req.SiteID, req.ClientID)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -50,13 +57,19 @@ func (h *UnitHandler) CreateUnit(c *gin.Context) {
 
 func (h *UnitHandler) BatchCreate(c *gin.Context) {
 	var req []service.BatchCreateUnitItem
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("body", "Invalid input list", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	units, err := h.service.BatchCreateUnits(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -66,18 +79,26 @@ func (h *UnitHandler) BatchCreate(c *gin.Context) {
 }
 
 func (h *UnitHandler) GetAllUnits(c *gin.Context) {
-	limit := 10
-	offset := 0
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
 
-	// Handle pagination if needed
-	units, total, err := h.service.GetAllUnits(c.Request.Context(), limit, offset)
+	units, total, err := h.service.GetAllUnits(c.Request.Context(), query)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
 		return
 	}
 
-	c.Header("Content-Range", fmt.Sprintf("units %d-%d/%d", offset, offset+len(units)-1, total))
+	c.Header("Content-Range", query.ContentRange("units", len(units), total))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Units retrieved successfully", units))
 }
 
@@ -85,6 +106,10 @@ func (h *UnitHandler) GetUnitByID(c *gin.Context) {
 	id := c.Param("id")
 	unit, err := h.service.GetUnitByID(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -94,19 +119,65 @@ func (h *UnitHandler) GetUnitByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, unit.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Unit retrieved successfully", unit))
 }
 
 func (h *UnitHandler) UpdateUnit(c *gin.Context) {
 	id := c.Param("id")
 	var req UpdateUnitRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("name/type/site_id", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	unit, err := h.service.UpdateUnit(c.Request.Context(), id, req.Name, req.Type, req.SiteID, req.ClientID, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if unit == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("Unit not found", nil))
+		return
+	}
+
+	c.Header("ETag", etag(unit.Version))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("Unit updated successfully", unit))
+}
+
+func (h *UnitHandler) PatchUnit(c *gin.Context) {
+	id := c.Param("id")
+	var patch service.UnitPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	unit, err := h.service.UpdateUnit(c.Request.Context(), id, req.Name, req.Type, req.SiteID, req.ClientID)
+	unit, err := h.service.PatchUnit(c.Request.Context(), id, patch, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -116,13 +187,22 @@ func (h *UnitHandler) UpdateUnit(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(unit.Version))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Unit updated successfully", unit))
 }
 
 func (h *UnitHandler) DeleteUnit(c *gin.Context) {
 	id := c.Param("id")
-	err := h.service.DeleteUnit(c.Request.Context(), id)
+	err := h.service.DeleteUnit(c.Request.Context(), id, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
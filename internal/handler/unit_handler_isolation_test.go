@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTenantContext simulates the auth middleware for the given caller.
+func newTenantContext(w *httptest.ResponseRecorder, user *model.User, method, target, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Set("authorization_payload", &token.Payload{
+		UserID:    user.ID,
+		Role:      user.Role,
+		CompanyID: user.CompanyID,
+	})
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req.WithContext(model.ContextWithUser(req.Context(), user))
+	return c
+}
+
+func TestUnitHandler_Isolation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer)}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+	clientID := "customer-1"
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		unitID         string
+		call           func(h *UnitHandler, c *gin.Context)
+		setupRepos     func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository)
+		expectedStatus int
+	}{
+		{
+			name:   "Company user reading own unit - Allowed",
+			user:   companyUser,
+			method: http.MethodGet,
+			unitID: "unit-1",
+			call:   (*UnitHandler).GetUnitByID,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Company user reading other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodGet,
+			unitID: "unit-2",
+			call:   (*UnitHandler).GetUnitByID,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+				// Unit should NOT be loaded
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user updating other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodPut,
+			body:   `{"name":"Flat 2","type":"FLAT","site_id":"site-1"}`,
+			unitID: "unit-2",
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user moving own unit to other company's site - Forbidden",
+			user:   companyUser,
+			method: http.MethodPut,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-2"}`,
+			unitID: "unit-1",
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1", SiteID: "site-1"}, nil)
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user deleting other company's unit - Forbidden",
+			user:   companyUser,
+			method: http.MethodDelete,
+			unitID: "unit-2",
+			call:   (*UnitHandler).DeleteUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user creating unit on other company's site - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Flat 3","type":"FLAT","site_id":"site-2"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user creating unit on a trashed site - Bad Request",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"name":"Flat 3","type":"FLAT","site_id":"site-3"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				// A trashed site is left out of the lookup and refused on insert like a missing one
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-3").Return(nil, nil)
+				units.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&pkgRepository.ReferenceError{Field: "site_id"})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Company user moving own unit to a trashed site - Bad Request",
+			user:   companyUser,
+			method: http.MethodPut,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-3"}`,
+			unitID: "unit-1",
+			call:   (*UnitHandler).UpdateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1", Type: model.UnitTypeFlat, SiteID: "site-1"}, nil)
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-3").Return(nil, nil)
+				units.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&pkgRepository.ReferenceError{Field: "site_id"})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Company user batch creating units on other company's site - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `[{"name":"Flat 4","type":"FLAT","site_id":"site-1"},{"name":"Flat 5","type":"FLAT","site_id":"site-2"}]`,
+			call:   (*UnitHandler).BatchCreate,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-2").Return(&repository.Ownership{CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user listing units - Scoped to own company",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*UnitHandler).GetAllUnits,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				units.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), repository.TenantScope{CompanyID: "company-123"}).Return([]*model.Unit{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer reading assigned unit - Allowed",
+			user:   customer,
+			method: http.MethodGet,
+			unitID: "unit-1",
+			call:   (*UnitHandler).GetUnitByID,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(&repository.Ownership{CompanyID: "company-123", ClientID: &clientID}, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1", ClientID: &clientID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer reading someone else's unit - Forbidden",
+			user:   customer,
+			method: http.MethodGet,
+			unitID: "unit-2",
+			call:   (*UnitHandler).GetUnitByID,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-2").Return(&repository.Ownership{CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Customer deleting assigned unit - Forbidden",
+			user:   customer,
+			method: http.MethodDelete,
+			unitID: "unit-1",
+			call:   (*UnitHandler).DeleteUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				// Customers hold no unit:delete at all, the unit is never looked up
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Customer listing units - Scoped to assigned units",
+			user:   customer,
+			method: http.MethodGet,
+			call:   (*UnitHandler).GetAllUnits,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				units.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), repository.TenantScope{CustomerID: "customer-1"}).Return([]*model.Unit{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Admin user reading any unit - Allowed",
+			user:   admin,
+			method: http.MethodGet,
+			unitID: "unit-2",
+			call:   (*UnitHandler).GetUnitByID,
+			setupRepos: func(units *mock_repository.MockUnitRepository, tenants *mock_repository.MockTenantRepository) {
+				units.EXPECT().FindByID(gomock.Any(), "unit-2").Return(&model.Unit{ID: "unit-2"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			units := mock_repository.NewMockUnitRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepos(units, tenants)
+			handler := NewUnitHandler(service.NewUnitService(nil, units, mock_repository.NewMockUserRepository(ctrl), tenants, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/units/"+tt.unitID, tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.unitID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUnitHandler_ClientAssignment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	ownCustomer := &model.User{ID: "6f1c1a52-3a6e-4c61-9a55-0c6d3c1f0a01", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+	otherCustomer := &model.User{ID: "6f1c1a52-3a6e-4c61-9a55-0c6d3c1f0a02", Role: string(model.RoleCustomer), CompanyID: "company-456"}
+	colleague := &model.User{ID: "6f1c1a52-3a6e-4c61-9a55-0c6d3c1f0a03", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	site := &repository.Ownership{CompanyID: "company-123"}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		call           func(h *UnitHandler, c *gin.Context)
+		setupRepos     func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository)
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:   "Customer of the site's company - Created",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-1","client_id":"` + ownCustomer.ID + `"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil).Times(2)
+				users.EXPECT().FindByID(gomock.Any(), ownCustomer.ID).Return(ownCustomer, nil)
+				units.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "Customer of another company - Rejected",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-1","client_id":"` + otherCustomer.ID + `"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil).Times(2)
+				users.EXPECT().FindByID(gomock.Any(), otherCustomer.ID).Return(otherCustomer, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "client_id",
+		},
+		{
+			name:   "Client who is not a customer - Rejected",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-1","client_id":"` + colleague.ID + `"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil)
+				users.EXPECT().FindByID(gomock.Any(), colleague.ID).Return(colleague, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "client_id",
+		},
+		{
+			name:   "Unknown client - Rejected",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 1","type":"FLAT","site_id":"site-1","client_id":"not-a-user"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "client_id",
+		},
+		{
+			name:   "Batch with a customer of another company - Rejected",
+			method: http.MethodPost,
+			body:   `[{"name":"Flat 1","type":"FLAT","site_id":"site-1"},{"name":"Flat 2","type":"FLAT","site_id":"site-1","client_id":"` + otherCustomer.ID + `"}]`,
+			call:   (*UnitHandler).BatchCreate,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil).Times(2)
+				users.EXPECT().FindByID(gomock.Any(), otherCustomer.ID).Return(otherCustomer, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "[1].client_id",
+		},
+		{
+			name:   "Patch assigning a customer of another company - Rejected",
+			method: http.MethodPatch,
+			body:   `{"client_id":"` + otherCustomer.ID + `"}`,
+			call:   (*UnitHandler).PatchUnit,
+			setupRepos: func(units *mock_repository.MockUnitRepository, users *mock_repository.MockUserRepository, tenants *mock_repository.MockTenantRepository) {
+				tenants.EXPECT().UnitOwnership(gomock.Any(), "unit-1").Return(site, nil)
+				units.EXPECT().FindByID(gomock.Any(), "unit-1").Return(&model.Unit{ID: "unit-1", Type: model.UnitTypeFlat, SiteID: "site-1"}, nil)
+				users.EXPECT().FindByID(gomock.Any(), otherCustomer.ID).Return(otherCustomer, nil)
+				tenants.EXPECT().SiteOwnership(gomock.Any(), "site-1").Return(site, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "client_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			units := mock_repository.NewMockUnitRepository(ctrl)
+			users := mock_repository.NewMockUserRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			tt.setupRepos(units, users, tenants)
+			handler := NewUnitHandler(service.NewUnitService(nil, units, users, tenants, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, companyUser, tt.method, "/units/unit-1", tt.body)
+			if tt.method == http.MethodPatch {
+				c.Request.Header.Set("Content-Type", mergepatch.ContentType)
+				c.Params = []gin.Param{{Key: "id", Value: "unit-1"}}
+			}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedField != "" {
+				assert.Contains(t, w.Body.String(), `"field":"`+tt.expectedField+`"`)
+			}
+		})
+	}
+}
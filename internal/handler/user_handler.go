@@ -1,12 +1,14 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
 	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
 type UserHandler struct {
@@ -32,13 +34,23 @@ type UpdateUserRequest struct {
 
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("email/password/role", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	user, err := h.service.CreateUser(c.Request.Context(), req.Email, req.Password, req.Role, req.CompanyID)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "This email address is already registered.", dto.ErrorCodeDuplicateEntry))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -48,17 +60,26 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	limit := 10
-	offset := 0
+	query, ok := listQuery(c)
+	if !ok {
+		return
+	}
 
-	users, total, err := h.service.GetAllUsers(c.Request.Context(), limit, offset)
+	users, total, err := h.service.GetAllUsers(c.Request.Context(), query)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+		case errors.Is(err, listquery.ErrInvalidQuery):
+			invalidListQuery(c, err)
+		default:
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		}
 		return
 	}
 
-	c.Header("Content-Range", fmt.Sprintf("users %d-%d/%d", offset, offset+len(users)-1, total))
+	c.Header("Content-Range", query.ContentRange("users", len(users), total))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("Users retrieved successfully", users))
 }
 
@@ -66,6 +87,10 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	id := c.Param("id")
 	user, err := h.service.GetUserByID(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -75,19 +100,73 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, user.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, dto.ResponseSuccess("User retrieved successfully", user))
 }
 
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 	var req UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ValidationError("email/role", "Invalid input", dto.ErrorCodeValidationFailed))
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), id, req.Email, req.Role, req.CompanyID, ifMatch(c))
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "This email address is already registered.", dto.ErrorCodeDuplicateEntry))
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError("User not found", nil))
+		return
+	}
+
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, dto.ResponseSuccess("User updated successfully", user))
+}
+
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id := c.Param("id")
+	var patch service.UserPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	user, err := h.service.UpdateUser(c.Request.Context(), id, req.Email, req.Role, req.CompanyID)
+	user, err := h.service.PatchUser(c.Request.Context(), id, patch, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		if invalidInput(c, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, dto.ValidationError("email", "This email address is already registered.", dto.ErrorCodeDuplicateEntry))
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
@@ -97,13 +176,22 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(user.Version))
 	c.JSON(http.StatusOK, dto.ResponseSuccess("User updated successfully", user))
 }
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
-	err := h.service.DeleteUser(c.Request.Context(), id)
+	err := h.service.DeleteUser(c.Request.Context(), id, ifMatch(c))
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, dto.ForbiddenResponse("Access denied"))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
 		c.Error(err)
 		c.JSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
 		return
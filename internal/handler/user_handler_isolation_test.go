@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHandler_Isolation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	companyUser := &model.User{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	customer := &model.User{ID: "customer-1", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+
+	tests := []struct {
+		name           string
+		user           *model.User
+		method         string
+		body           string
+		targetID       string
+		call           func(h *UserHandler, c *gin.Context)
+		setupRepo      func(users *mock_repository.MockUserRepository)
+		expectedStatus int
+	}{
+		{
+			name:     "Company user reading own company's user - Allowed",
+			user:     companyUser,
+			method:   http.MethodGet,
+			targetID: "user-2",
+			call:     (*UserHandler).GetUserByID,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-2").Return(&model.User{ID: "user-2", Role: "customer", CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Company user reading other company's user - Forbidden",
+			user:     companyUser,
+			method:   http.MethodGet,
+			targetID: "user-3",
+			call:     (*UserHandler).GetUserByID,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-3").Return(&model.User{ID: "user-3", Role: "company", CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user reading admin of own company - Forbidden",
+			user:     companyUser,
+			method:   http.MethodGet,
+			targetID: "admin-2",
+			call:     (*UserHandler).GetUserByID,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "admin-2").Return(&model.User{ID: "admin-2", Role: "admin", CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user updating other company's user - Forbidden",
+			user:     companyUser,
+			method:   http.MethodPut,
+			body:     `{"email":"someone@example.com","role":"company","company_id":"company-123"}`,
+			targetID: "user-3",
+			call:     (*UserHandler).UpdateUser,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-3").Return(&model.User{ID: "user-3", Role: "company", CompanyID: "company-456"}, nil)
+				// Update should NOT be called
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user promoting own company's user to admin - Forbidden",
+			user:     companyUser,
+			method:   http.MethodPut,
+			body:     `{"email":"someone@example.com","role":"admin","company_id":"company-123"}`,
+			targetID: "user-2",
+			call:     (*UserHandler).UpdateUser,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-2").Return(&model.User{ID: "user-2", Role: "customer", CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Company user deleting other company's user - Forbidden",
+			user:     companyUser,
+			method:   http.MethodDelete,
+			targetID: "user-3",
+			call:     (*UserHandler).DeleteUser,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-3").Return(&model.User{ID: "user-3", Role: "company", CompanyID: "company-456"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user creating user in other company - Forbidden",
+			user:   companyUser,
+			method: http.MethodPost,
+			body:   `{"email":"new@example.com","password":"secret1","role":"customer","company_id":"company-456"}`,
+			call:   (*UserHandler).CreateUser,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				// Create should NOT be called
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Company user listing users - Scoped to own company",
+			user:   companyUser,
+			method: http.MethodGet,
+			call:   (*UserHandler).GetAllUsers,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), "company-123").Return([]*model.User{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Customer listing users - Forbidden",
+			user:   customer,
+			method: http.MethodGet,
+			call:   (*UserHandler).GetAllUsers,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Customer reading another user - Forbidden",
+			user:     customer,
+			method:   http.MethodGet,
+			targetID: "user-2",
+			call:     (*UserHandler).GetUserByID,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindByID(gomock.Any(), "user-2").Return(&model.User{ID: "user-2", Role: "customer", CompanyID: "company-123"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Admin user listing users - Allowed",
+			user:   admin,
+			method: http.MethodGet,
+			call:   (*UserHandler).GetAllUsers,
+			setupRepo: func(users *mock_repository.MockUserRepository) {
+				users.EXPECT().FindAll(gomock.Any(), listquery.New(listquery.DefaultLimit), "").Return([]*model.User{}, int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			users := mock_repository.NewMockUserRepository(ctrl)
+			tt.setupRepo(users)
+			// Users are authorized on the record itself, no tenant lookups should happen
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			handler := NewUserHandler(service.NewUserService(users, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, tt.user, tt.method, "/users/"+tt.targetID, tt.body)
+			c.Params = []gin.Param{{Key: "id", Value: tt.targetID}}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
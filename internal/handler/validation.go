@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
+)
+
+func init() {
+	// Errors name the fields as clients send them, not as the Go structs call them
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName is the JSON member, or the form field, a struct field is bound from.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// bindJSON reads the JSON body into obj. When it cannot, it answers 400 with an entry for every
+// field that failed and returns false.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		invalidBody(c, obj, err)
+		return false
+	}
+	return true
+}
+
+// invalidBody answers 400 for a body that failed to bind into obj.
+func invalidBody(c *gin.Context, obj interface{}, err error) {
+	c.JSON(http.StatusBadRequest, dto.ValidationErrors(bindingErrors(obj, err)))
+}
+
+// bindingErrors translates what binding obj failed with into one entry per field.
+func bindingErrors(obj interface{}, err error) []dto.ErrorResponse {
+	var invalid validator.ValidationErrors
+	var invalidItems binding.SliceValidationError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &invalid):
+		return fieldErrors("", invalid)
+	case errors.As(err, &invalidItems):
+		// gin drops the index of the items that failed, they are validated again one by one
+		items := reflect.Indirect(reflect.ValueOf(obj))
+		errs := make([]dto.ErrorResponse, 0, len(invalidItems))
+		for i := 0; i < items.Len(); i++ {
+			if errors.As(binding.Validator.ValidateStruct(items.Index(i).Interface()), &invalid) {
+				errs = append(errs, fieldErrors(fmt.Sprintf("[%d].", i), invalid)...)
+			}
+		}
+		return errs
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return []dto.ErrorResponse{dto.FieldError(field, "Must be "+jsonTypeName(typeErr.Type), dto.ErrorCodeInvalidFormat)}
+	default:
+		return []dto.ErrorResponse{dto.FieldError("body", "Body is malformed", dto.ErrorCodeInvalidFormat)}
+	}
+}
+
+// fieldErrors turns the rules fields failed into entries, prefix coming before the field name.
+func fieldErrors(prefix string, invalid validator.ValidationErrors) []dto.ErrorResponse {
+	errs := make([]dto.ErrorResponse, len(invalid))
+	for i, fe := range invalid {
+		// The namespace starts with the struct, "CreateUnitRequest.site_id"
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		message, code := ruleMessage(fe)
+		errs[i] = dto.FieldError(prefix+field, message, code)
+	}
+	return errs
+}
+
+func ruleMessage(fe validator.FieldError) (string, dto.ErrorCode) {
+	switch fe.Tag() {
+	case "required":
+		return "This field is required", dto.ErrorCodeRequiredField
+	case "omitnil":
+		// Only fails for a null sent in a merge patch to what cannot be removed
+		return "Cannot be null", dto.ErrorCodeRequiredField
+	case "email":
+		return "Must be a valid email address", dto.ErrorCodeInvalidFormat
+	case "url":
+		return "Must be a valid URL", dto.ErrorCodeInvalidFormat
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			if fe.Tag() == "min" && fe.Param() == "1" {
+				return "Cannot be empty", dto.ErrorCodeValidationFailed
+			}
+			return fmt.Sprintf("Must be %s %s characters long", bound, fe.Param()), dto.ErrorCodeValidationFailed
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if fe.Tag() == "min" && fe.Param() == "1" {
+				return "Must have at least one item", dto.ErrorCodeValidationFailed
+			}
+			return fmt.Sprintf("Must have %s %s items", bound, fe.Param()), dto.ErrorCodeValidationFailed
+		}
+		return fmt.Sprintf("Must be %s %s", bound, fe.Param()), dto.ErrorCodeValidationFailed
+	default:
+		return fmt.Sprintf("Failed the %s rule", fe.Tag()), dto.ErrorCodeValidationFailed
+	}
+}
+
+// jsonTypeName is how the JSON type a Go type decodes from is called in messages.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// invalidInput answers 400 when err is an input breaking a domain rule, such as a unit type
+// that does not exist or a site_id no site has, and reports whether it was one.
+func invalidInput(c *gin.Context, err error) bool {
+	var invalid service.ValidationError
+	var reference *repository.ReferenceError
+	switch {
+	case errors.As(err, &invalid):
+		errs := make([]dto.ErrorResponse, len(invalid))
+		for i, fe := range invalid {
+			errs[i] = dto.FieldError(fe.Field, fe.Message, dto.ErrorCodeInvalidFormat)
+		}
+		c.JSON(http.StatusBadRequest, dto.ValidationErrors(errs))
+	case errors.As(err, &reference):
+		c.JSON(http.StatusBadRequest, dto.ValidationError(reference.Field, "Does not reference an existing record", dto.ErrorCodeInvalidReference))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/service"
+	mock_repository "github.com/hfleury/bk_globalshot/mock/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
+	"github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		call           func(h *UnitHandler, c *gin.Context)
+		setupRepo      func(units *mock_repository.MockUnitRepository)
+		expectedErrors []dto.ErrorResponse
+	}{
+		{
+			name:   "Empty body - Every required field",
+			method: http.MethodPost,
+			body:   `{}`,
+			call:   (*UnitHandler).CreateUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("name", "This field is required", dto.ErrorCodeRequiredField),
+				dto.FieldError("type", "This field is required", dto.ErrorCodeRequiredField),
+				dto.FieldError("site_id", "This field is required", dto.ErrorCodeRequiredField),
+			},
+		},
+		{
+			name:   "Name of the wrong type - Named",
+			method: http.MethodPost,
+			body:   `{"name":5,"type":"FLAT","site_id":"site-1"}`,
+			call:   (*UnitHandler).CreateUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("name", "Must be a string", dto.ErrorCodeInvalidFormat),
+			},
+		},
+		{
+			name:   "Not JSON - Body",
+			method: http.MethodPost,
+			body:   `{"name":`,
+			call:   (*UnitHandler).CreateUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("body", "Body is malformed", dto.ErrorCodeInvalidFormat),
+			},
+		},
+		{
+			name:   "Unknown unit type - Type",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 2A","type":"CASTLE","site_id":"site-1"}`,
+			call:   (*UnitHandler).CreateUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("type", "Type must be HOUSE or FLAT", dto.ErrorCodeInvalidFormat),
+			},
+		},
+		{
+			name:   "Unknown site - Site",
+			method: http.MethodPost,
+			body:   `{"name":"Flat 2A","type":"FLAT","site_id":"00000000-0000-0000-0000-000000000000"}`,
+			call:   (*UnitHandler).CreateUnit,
+			setupRepo: func(units *mock_repository.MockUnitRepository) {
+				units.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&repository.ReferenceError{Field: "site_id"})
+			},
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("site_id", "Does not reference an existing record", dto.ErrorCodeInvalidReference),
+			},
+		},
+		{
+			name:   "Batch with invalid items - Indexed",
+			method: http.MethodPost,
+			body:   `[{"name":"Flat 1","type":"FLAT","site_id":"site-1"},{"type":"FLAT"}]`,
+			call:   (*UnitHandler).BatchCreate,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("[1].name", "This field is required", dto.ErrorCodeRequiredField),
+				dto.FieldError("[1].site_id", "This field is required", dto.ErrorCodeRequiredField),
+			},
+		},
+		{
+			name:   "Batch with unknown unit types - Indexed",
+			method: http.MethodPost,
+			body:   `[{"name":"Flat 1","type":"CASTLE","site_id":"site-1"},{"name":"Flat 2","type":"FLAT","site_id":"site-1"},{"name":"Flat 3","type":"TOWER","site_id":"site-1"}]`,
+			call:   (*UnitHandler).BatchCreate,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("[0].type", "Type must be HOUSE or FLAT", dto.ErrorCodeInvalidFormat),
+				dto.FieldError("[2].type", "Type must be HOUSE or FLAT", dto.ErrorCodeInvalidFormat),
+			},
+		},
+		{
+			name:   "Patch emptying the name - Name",
+			method: http.MethodPatch,
+			body:   `{"name":"","type":"FLAT"}`,
+			call:   (*UnitHandler).PatchUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("name", "Cannot be empty", dto.ErrorCodeValidationFailed),
+			},
+		},
+		{
+			name:   "Patch removing the name - Name",
+			method: http.MethodPatch,
+			body:   `{"name":null}`,
+			call:   (*UnitHandler).PatchUnit,
+			expectedErrors: []dto.ErrorResponse{
+				dto.FieldError("name", "Cannot be null", dto.ErrorCodeRequiredField),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			units := mock_repository.NewMockUnitRepository(ctrl)
+			tenants := mock_repository.NewMockTenantRepository(ctrl)
+			if tt.setupRepo != nil {
+				tt.setupRepo(units)
+			}
+			handler := NewUnitHandler(service.NewUnitService(nil, units, mock_repository.NewMockUserRepository(ctrl), tenants, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+			w := httptest.NewRecorder()
+			c := newTenantContext(w, admin, tt.method, "/units", tt.body)
+			if tt.method == http.MethodPatch {
+				c.Request.Header.Set("Content-Type", mergepatch.ContentType)
+				c.Params = []gin.Param{{Key: "id", Value: "unit-1"}}
+			}
+
+			tt.call(handler, c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response dto.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedErrors, response.Errors)
+		})
+	}
+}
+
+func TestUserValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mock_repository.NewMockUserRepository(ctrl)
+	tenants := mock_repository.NewMockTenantRepository(ctrl)
+	handler := NewUserHandler(service.NewUserService(users, service.NewAuthorizer(authz.Default, tenants), newAuditRecorder(ctrl)))
+
+	w := httptest.NewRecorder()
+	admin := &model.User{ID: "admin-1", Role: string(model.RoleAdmin)}
+	c := newTenantContext(w, admin, http.MethodPost, "/users", `{"email":"not-an-email","password":"123","role":"owner"}`)
+
+	handler.CreateUser(c)
+
+	// Rules the request breaks come first, the role is only checked once they pass
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response dto.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []dto.ErrorResponse{
+		dto.FieldError("email", "Must be a valid email address", dto.ErrorCodeInvalidFormat),
+		dto.FieldError("password", "Must be at least 6 characters long", dto.ErrorCodeValidationFailed),
+	}, response.Errors)
+
+	w = httptest.NewRecorder()
+	c = newTenantContext(w, admin, http.MethodPost, "/users", `{"email":"client@example.com","password":"123456","role":"owner"}`)
+
+	handler.CreateUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []dto.ErrorResponse{
+		dto.FieldError("role", "Role must be admin, company or customer", dto.ErrorCodeInvalidFormat),
+	}, response.Errors)
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// APIKey lets a machine client of a company call the API without a human login. Only the
+// hash of the key is stored, the prefix is kept in clear so a key can be told apart in
+// listings and logs.
+type APIKey struct {
+	ID         string     `json:"id"`
+	CompanyID  string     `json:"company_id"`
+	CreatedBy  *string    `json:"created_by,omitempty"` // The key acts on their behalf, nullable once they are deleted
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil never expires
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the key is accepted at the given time.
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore" // Taken out of the trash
+)
+
+// Resource types of the audit trail.
+const (
+	AuditResourceCompany = "company"
+	AuditResourceSite    = "site"
+	AuditResourceUnit    = "unit"
+	AuditResourceRoom    = "room"
+	AuditResourceUser    = "user"
+	AuditResourceMedia   = "media"
+)
+
+// IsValidAuditAction reports whether the action is one audit events are recorded with.
+func IsValidAuditAction(action string) bool {
+	switch AuditAction(action) {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRestore:
+		return true
+	}
+	return false
+}
+
+// AuditChange is the value of one field before and after a change. Before is nil on
+// creation, After on deletion.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEvent records who changed what. The actor is nil for changes made by the system, the
+// impersonator is the admin behind an impersonation token.
+type AuditEvent struct {
+	ID             string                 `json:"id"`
+	OccurredAt     time.Time              `json:"occurred_at"`
+	ActorID        *string                `json:"actor_id"`
+	ImpersonatorID *string                `json:"impersonator_id,omitempty"`
+	CompanyID      *string                `json:"company_id"`
+	Action         AuditAction            `json:"action"`
+	ResourceType   string                 `json:"resource_type"`
+	ResourceID     string                 `json:"resource_id"`
+	Changes        map[string]AuditChange `json:"changes"`
+	IPAddress      *string                `json:"ip_address,omitempty"`
+	UserAgent      *string                `json:"user_agent,omitempty"`
+}
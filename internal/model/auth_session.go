@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// AuthSession is a login, persisted in auth_tokens. Access tokens carry its ID so revoking
+// the session cuts them off immediately, the refresh token renewing it is only stored hashed.
+type AuthSession struct {
+	ID                string     `json:"id"`
+	UserID            string     `json:"user_id"`
+	TokenHash         string     `json:"-"`
+	PreviousTokenHash *string    `json:"-"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	Revoked           bool       `json:"revoked"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	UserAgent         *string    `json:"user_agent,omitempty"`
+	IPAddress         *string    `json:"ip_address,omitempty"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Current           bool       `json:"current"` // Not stored, set when listing for the session making the request
+}
+
+// IsActive reports whether the session can still be used at the given time.
+func (s *AuthSession) IsActive(now time.Time) bool {
+	return !s.Revoked && now.Before(s.ExpiresAt)
+}
@@ -7,4 +7,14 @@ type Company struct {
 	Name      string     `json:"name"`
 	CreatedAt time.Time  `json:"created_at"`
 	DeletedAt *time.Time `json:"-"`
+	Version   int64      `json:"version"`
+	// Identity provider the staff of the company sign in with, all three set or none
+	OIDCIssuer       *string `json:"oidc_issuer,omitempty"`
+	OIDCClientID     *string `json:"oidc_client_id,omitempty"`
+	OIDCClientSecret *string `json:"-"` // Sealed with the secret key of the OIDC settings
+}
+
+// SSOEnabled reports whether the company has single sign-on configured.
+func (c *Company) SSOEnabled() bool {
+	return c.OIDCIssuer != nil && c.OIDCClientID != nil && c.OIDCClientSecret != nil
 }
@@ -0,0 +1,39 @@
+package model
+
+import "context"
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// ContextWithUser attaches the authenticated caller to the context so services can
+// authorize against it. Only ID, Role, CompanyID and, for API keys, Scopes are expected to be set.
+func ContextWithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the caller attached by ContextWithUser, or nil when the
+// request is not authenticated.
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey).(*User)
+	return user
+}
+
+const clientContextKey contextKey = "client"
+
+// ClientInfo is where a request came from, kept with sessions and the audit trail.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ContextWithClient attaches the origin of the request to the context.
+func ContextWithClient(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientContextKey, client)
+}
+
+// ClientFromContext returns the origin attached by ContextWithClient, empty when there is none.
+func ClientFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientContextKey).(ClientInfo)
+	return client
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Impersonation records an admin signing in as another user, for support. Admin and user
+// stay nil once their accounts are deleted, the record itself is kept.
+type Impersonation struct {
+	ID        string     `json:"id"`
+	AdminID   *string    `json:"admin_id"`
+	UserID    *string    `json:"user_id"`
+	SessionID *string    `json:"session_id"` // auth_tokens row the impersonation token is bound to
+	Reason    string     `json:"reason"`
+	IPAddress *string    `json:"ip_address,omitempty"`
+	UserAgent *string    `json:"user_agent,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// IsActive reports whether the impersonation token can still be used at the given time.
+func (i *Impersonation) IsActive(now time.Time) bool {
+	return i.EndedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+// Invitation lets someone join with a role, a company and optionally a unit of their own,
+// choosing their password themselves. Like reset links only the hash of the token is stored.
+type Invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	CompanyID  *string    `json:"company_id,omitempty"`
+	UnitID     *string    `json:"unit_id,omitempty"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  *string    `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Status tells where the invitation stands at the given time.
+func (i *Invitation) Status(now time.Time) InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	}
+	return InvitationPending
+}
+
+// IsPending reports whether the invitation can still be accepted at the given time.
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.Status(now) == InvitationPending
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// LoginLockout counts the failed logins of a user in a row and how long they are locked out for.
+type LoginLockout struct {
+	UserID         string
+	FailedAttempts int
+	LockedUntil    *time.Time
+	LastFailedAt   time.Time
+}
+
+// IsLocked reports whether the user cannot sign in at the given time.
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l != nil && l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
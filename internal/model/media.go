@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+type MediaProcessingStatus string
+
+const (
+	MediaProcessingPending    MediaProcessingStatus = "pending"
+	MediaProcessingProcessing MediaProcessingStatus = "processing"
+	MediaProcessingReady      MediaProcessingStatus = "ready"
+	MediaProcessingFailed     MediaProcessingStatus = "failed"
+)
+
+// MediaVariant identifies one of the renditions stored for a capture.
+type MediaVariant string
+
+const (
+	MediaVariantOriginal  MediaVariant = "original"
+	MediaVariantThumbnail MediaVariant = "thumbnail"
+	MediaVariantPreview   MediaVariant = "preview"
+)
+
+// TakenAtSource records where a capture date came from, EXIF beats the client which beats the upload time.
+type TakenAtSource string
+
+const (
+	TakenAtSourceExif   TakenAtSource = "exif"
+	TakenAtSourceClient TakenAtSource = "client"
+	TakenAtSourceUpload TakenAtSource = "upload"
+)
+
+type Media struct {
+	ID               string                 `json:"id"`
+	RoomID           string                 `json:"room_id"`
+	URL              string                 `json:"url"`
+	ThumbnailURL     *string                `json:"thumbnail_url,omitempty"` // Nullable, set once processed
+	PreviewURL       *string                `json:"preview_url,omitempty"`   // Nullable, set once processed
+	StorageKey       string                 `json:"-"`
+	FileName         string                 `json:"file_name"`
+	ContentType      string                 `json:"content_type"`
+	SizeBytes        int64                  `json:"size_bytes"`
+	UploadedBy       *string                `json:"uploaded_by,omitempty"` // Nullable, user may have been deleted
+	ProcessingStatus MediaProcessingStatus  `json:"processing_status"`
+	ProcessingError  *string                `json:"processing_error,omitempty"`
+	TakenAt          time.Time              `json:"taken_at"`
+	TakenAtSource    TakenAtSource          `json:"taken_at_source"`
+	CameraMake       *string                `json:"camera_make,omitempty"`
+	CameraModel      *string                `json:"camera_model,omitempty"`
+	Latitude         *float64               `json:"latitude,omitempty"`
+	Longitude        *float64               `json:"longitude,omitempty"`
+	ProjectionType   *string                `json:"projection_type,omitempty"`
+	PoseHeading      *float64               `json:"pose_heading,omitempty"`
+	Width            *int                   `json:"width,omitempty"`
+	Height           *int                   `json:"height,omitempty"`
+	TilesStatus      *MediaProcessingStatus `json:"tiles_status,omitempty"` // Nullable, only panoramas are tiled
+	TilesError       *string                `json:"tiles_error,omitempty"`
+	TileSize         *int                   `json:"-"`
+	TileFaceSize     *int                   `json:"-"`
+	CreatedAt        time.Time              `json:"created_at"`
+	Version          int64                  `json:"version"`
+}
+
+// TileManifest describes the cube map tile pyramid of a panorama so a viewer can
+// stream only the tiles in view. Levels are ordered from the smallest face up.
+type TileManifest struct {
+	MediaID     string                `json:"media_id"`
+	Status      MediaProcessingStatus `json:"status"`
+	Projection  string                `json:"projection"`
+	Faces       []string              `json:"faces,omitempty"`
+	Levels      []TileLevel           `json:"levels,omitempty"`
+	URLTemplate string                `json:"url_template,omitempty"` // {z} level, {f} face, {y} row, {x} column
+	PreviewURL  *string               `json:"preview_url,omitempty"`
+	PoseHeading *float64              `json:"pose_heading,omitempty"`
+}
+
+type TileLevel struct {
+	Level    int `json:"level"`
+	Size     int `json:"size"`
+	TileSize int `json:"tile_size"`
+	Tiles    int `json:"tiles"` // Tiles per face side
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// UserMFA is the TOTP factor of a user. It exists from the start of the enrollment, the
+// factor only counts once EnabledAt is set by a first valid code.
+type UserMFA struct {
+	UserID       string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64 // Last TOTP step accepted, an intercepted code cannot be replayed
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IsEnabled reports whether the enrollment was confirmed.
+func (m *UserMFA) IsEnabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFAChallenge is the half-finished login between a valid password and the second factor.
+// Only the hash of its token is stored, like refresh tokens.
+type MFAChallenge struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// IsUsable reports whether the challenge can still complete a login at the given time.
+func (c *MFAChallenge) IsUsable(now time.Time, maxAttempts int) bool {
+	return c.UsedAt == nil && c.Attempts < maxAttempts && now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// PasswordResetToken is a single-use reset link. Only the hash of the token is stored,
+// the token itself only ever exists in the email sent to the user.
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// IsUsable reports whether the token can still reset a password at the given time.
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -6,6 +6,8 @@ type Room struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
 	UnitID    string    `json:"unit_id"`
+	Panoramic bool      `json:"panoramic"` // Captures must be 360° equirectangular images
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
 }
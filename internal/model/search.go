@@ -0,0 +1,40 @@
+package model
+
+// SearchResultType is the kind of resource a search result points to.
+type SearchResultType string
+
+const (
+	SearchResultSite SearchResultType = "site"
+	SearchResultUnit SearchResultType = "unit"
+	SearchResultRoom SearchResultType = "room"
+	SearchResultUser SearchResultType = "user"
+)
+
+// SearchResultTypes are the types searched when the caller does not pick any.
+var SearchResultTypes = []SearchResultType{SearchResultSite, SearchResultUnit, SearchResultRoom, SearchResultUser}
+
+func IsValidSearchResultType(t string) bool {
+	for _, valid := range SearchResultTypes {
+		if SearchResultType(t) == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult is one match of a search. Path holds the resources it sits in, outermost
+// first, such as the company, site and unit of a room.
+type SearchResult struct {
+	Type  SearchResultType `json:"type"`
+	ID    string           `json:"id"`
+	Label string           `json:"label"` // Name, or email for users
+	Path  []SearchPathItem `json:"path"`
+	Rank  float64          `json:"rank"`
+}
+
+// SearchPathItem is a resource a search result sits in.
+type SearchPathItem struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
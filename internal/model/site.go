@@ -9,4 +9,5 @@ type Site struct {
 	CompanyID string    `json:"company_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
 }
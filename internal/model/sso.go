@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to the account they have at an identity provider.
+type UserIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCLoginState is a single sign-on login in flight, from the redirect to the identity
+// provider until it sends the user back. Only the hash of the state is stored.
+type OIDCLoginState struct {
+	StateHash    string
+	CompanyID    string
+	Nonce        string
+	CodeVerifier string // PKCE, never leaves the server
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
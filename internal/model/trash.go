@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// TrashItemType is the kind of resource that goes to the trash when deleted.
+type TrashItemType string
+
+const (
+	TrashItemCompany TrashItemType = "company"
+	TrashItemSite    TrashItemType = "site"
+	TrashItemUnit    TrashItemType = "unit"
+	TrashItemRoom    TrashItemType = "room"
+)
+
+// TrashItem is a deleted company, site, unit or room. What sits inside it was deleted along
+// with it and comes back with it.
+type TrashItem struct {
+	Type      TrashItemType `json:"type"`
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	CompanyID string        `json:"company_id"`
+	ParentID  *string       `json:"parent_id"` // Company of a site, site of a unit, unit of a room
+	DeletedAt time.Time     `json:"deleted_at"`
+	PurgeAt   time.Time     `json:"purge_at"` // When it is deleted for good
+	// Restorable is false while the parent is in the trash too, it has to come back first
+	Restorable bool `json:"restorable"`
+}
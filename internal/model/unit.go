@@ -9,6 +9,14 @@ const (
 	UnitTypeFlat  UnitType = "FLAT"
 )
 
+func IsValidUnitType(t string) bool {
+	switch UnitType(t) {
+	case UnitTypeHouse, UnitTypeFlat:
+		return true
+	}
+	return false
+}
+
 type Unit struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
@@ -17,4 +25,5 @@ type Unit struct {
 	ClientID  *string   `json:"client_id,omitempty"` // Nullable
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
 }
@@ -1,9 +1,13 @@
 package model
 
 type User struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Password  string `json:"-"`
-	Role      string `json:"role"`
-	CompanyID string `json:"company_id,omitempty"`
+	ID        string   `json:"id"`
+	Email     string   `json:"email"`
+	Password  string   `json:"-"`
+	Role      string   `json:"role"`
+	CompanyID string   `json:"company_id,omitempty"`
+	Version   int64    `json:"version"`
+	Scopes    []string `json:"-"` // Only on callers authenticated by an API key, see authz.Subject
+	// ImpersonatorID is the admin acting as this user, only on callers of an impersonation token
+	ImpersonatorID string `json:"-"`
 }
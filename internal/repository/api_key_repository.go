@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+//go:generate mockgen -source=api_key_repository.go -destination=../../mock/repository/mock_api_key_repository.go -package=mock_repository
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id string) (*model.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	// FindAll lists keys, newest first, of one company when companyID is set.
+	FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.APIKey, int64, error)
+	// Revoke disables a key for good, it reports false when it was already revoked.
+	Revoke(ctx context.Context, id string) (bool, error)
+	// TouchLastUsed records a use. Writes are coarse, a key used again within a minute is
+	// not updated, so a busy integration does not write on every request.
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// AuditFilter narrows the audit trail down. Empty fields do not filter.
+type AuditFilter struct {
+	CompanyID    string
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+}
+
+//go:generate mockgen -source=audit_repository.go -destination=../../mock/repository/mock_audit_repository.go -package=mock_repository
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEvent) error
+	// FindAll lists events matching the filter, most recent first.
+	FindAll(ctx context.Context, query listquery.Query, filter AuditFilter) ([]*model.AuditEvent, listquery.PageInfo, error)
+}
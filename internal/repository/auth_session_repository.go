@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+//go:generate mockgen -source=auth_session_repository.go -destination=../../mock/repository/mock_auth_session_repository.go -package=mock_repository
+type AuthSessionRepository interface {
+	Create(ctx context.Context, session *model.AuthSession) error
+	FindByID(ctx context.Context, id string) (*model.AuthSession, error)
+	// FindActiveByUserID lists the sessions of a user that are neither revoked nor expired, most recently used first.
+	FindActiveByUserID(ctx context.Context, userID string) ([]*model.AuthSession, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error)
+	// FindByPreviousTokenHash finds the session a refresh token was rotated out of, used to detect replays.
+	FindByPreviousTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error)
+	// Rotate swaps the refresh token of a session. It only succeeds while oldHash is still the current
+	// token, so two concurrent refreshes with the same token cannot both win.
+	Rotate(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time, userAgent, ipAddress *string) (bool, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+	// RevokeOthersForUser signs a user out everywhere but the session they are using.
+	RevokeOthersForUser(ctx context.Context, userID, keepSessionID string) error
+}
@@ -5,13 +5,21 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
+//go:generate mockgen -source=company_repository.go -destination=../../mock/repository/mock_company_repository.go -package=mock_repository
 type CompanyRepository interface {
 	Create(ctx context.Context, company *model.Company) error
-	FindAll(ctx context.Context, limit, offset int) ([]*model.Company, int64, error)
+	FindAll(ctx context.Context, query listquery.Query) ([]*model.Company, int64, error)
 	FindByID(ctx context.Context, id string) (*model.Company, error)
+	// Update is versioned like UnitRepository.Update.
 	Update(ctx context.Context, company *model.Company) error
-	Delete(ctx context.Context, id string) error
+	// UpdateSSO stores the identity provider settings of the company, nil clears them. It
+	// bumps the version without checking it.
+	UpdateSSO(ctx context.Context, company *model.Company) error
+	// Delete moves the company to the trash with its sites, units and rooms if it is still at
+	// version, ErrVersionConflict otherwise.
+	Delete(ctx context.Context, id string, version int64) error
 	WithTx(tx db.Db) CompanyRepository
 }
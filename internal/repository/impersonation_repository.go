@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+//go:generate mockgen -source=impersonation_repository.go -destination=../../mock/repository/mock_impersonation_repository.go -package=mock_repository
+type ImpersonationRepository interface {
+	Create(ctx context.Context, impersonation *model.Impersonation) error
+	FindByID(ctx context.Context, id string) (*model.Impersonation, error)
+	// FindAll lists impersonations, most recent first unless the query sorts them otherwise.
+	FindAll(ctx context.Context, query listquery.Query) ([]*model.Impersonation, int64, error)
+	// End records when an impersonation was stopped, it reports false when it already was.
+	End(ctx context.Context, id string, at time.Time) (bool, error)
+}
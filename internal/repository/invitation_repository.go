@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+//go:generate mockgen -source=invitation_repository.go -destination=../../mock/repository/mock_invitation_repository.go -package=mock_repository
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *model.Invitation) error
+	FindByID(ctx context.Context, id string) (*model.Invitation, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error)
+	// FindAll lists invitations, newest first, of one company when companyID is set.
+	FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.Invitation, int64, error)
+	// RenewToken swaps the token of a pending invitation, the link sent before stops working.
+	RenewToken(ctx context.Context, invitation *model.Invitation) (bool, error)
+	// MarkAccepted consumes an invitation, it reports false when it was no longer pending.
+	MarkAccepted(ctx context.Context, id string) (bool, error)
+	// Revoke cancels an invitation, it reports false when it was no longer pending.
+	Revoke(ctx context.Context, id string) (bool, error)
+	WithTx(tx db.Db) InvitationRepository
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+//go:generate mockgen -source=login_lockout_repository.go -destination=../../mock/repository/mock_login_lockout_repository.go -package=mock_repository
+type LoginLockoutRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*model.LoginLockout, error)
+	// RecordFailure counts a failed login and returns the failures in a row, the count starts
+	// over when the previous failure is older than window.
+	RecordFailure(ctx context.Context, userID string, window time.Duration) (int, error)
+	Lock(ctx context.Context, userID string, until time.Time) error
+	// Reset forgets the failures of a user who signed in.
+	Reset(ctx context.Context, userID string) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+type MediaRepository interface {
+	Create(ctx context.Context, media *model.Media) error
+	FindByID(ctx context.Context, id string) (*model.Media, error)
+	// FindAllByRoomID lists the capture history of a room, newest first. Cursor pages follow
+	// upload order instead.
+	FindAllByRoomID(ctx context.Context, query listquery.Query, roomID string) ([]*model.Media, listquery.PageInfo, error)
+	// FindByProcessingStatus returns media waiting for (or stuck in) background processing, oldest first.
+	FindByProcessingStatus(ctx context.Context, limit int, statuses ...model.MediaProcessingStatus) ([]*model.Media, error)
+	// FindByTilesStatus returns panoramas whose tile pyramid is waiting for (or stuck in) generation, oldest first.
+	FindByTilesStatus(ctx context.Context, limit int, statuses ...model.MediaProcessingStatus) ([]*model.Media, error)
+	// UpdateProcessing saves the outcome of background processing, renditions and tiles alike,
+	// and bumps the version.
+	UpdateProcessing(ctx context.Context, media *model.Media) error
+	Delete(ctx context.Context, id string) error
+	WithTx(tx db.Db) MediaRepository
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+//go:generate mockgen -source=mfa_repository.go -destination=../../mock/repository/mock_mfa_repository.go -package=mock_repository
+type MFARepository interface {
+	FindByUserID(ctx context.Context, userID string) (*model.UserMFA, error)
+	// SavePending starts, or restarts, an enrollment with a new secret that is not enabled yet.
+	SavePending(ctx context.Context, userID, secret string) error
+	// Enable confirms the enrollment, it reports false when it was already enabled.
+	Enable(ctx context.Context, userID string, step int64) (bool, error)
+	// UseStep records an accepted TOTP step, it reports false when that step or a later one
+	// was already used.
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	// Delete removes the factor and its recovery codes.
+	Delete(ctx context.Context, userID string) error
+
+	// ReplaceRecoveryCodes drops every recovery code of the user for the given hashes.
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UseRecoveryCode consumes a recovery code, it reports false when there is no such unused code.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	CreateChallenge(ctx context.Context, challenge *model.MFAChallenge) error
+	FindChallengeByTokenHash(ctx context.Context, tokenHash string) (*model.MFAChallenge, error)
+	// RecordChallengeAttempt counts a wrong code against the challenge.
+	RecordChallengeAttempt(ctx context.Context, id string) error
+	// MarkChallengeUsed consumes a challenge, it reports false when it was already used.
+	MarkChallengeUsed(ctx context.Context, id string) (bool, error)
+}
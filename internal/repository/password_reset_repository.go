@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *model.PasswordResetToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	// MarkUsed consumes a token, it reports false when the token was already used.
+	MarkUsed(ctx context.Context, id string) (bool, error)
+	// InvalidateForUser consumes every outstanding token of a user, only the latest link should work.
+	InvalidateForUser(ctx context.Context, userID string) error
+}
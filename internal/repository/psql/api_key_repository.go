@@ -0,0 +1,158 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+const apiKeyColumns = `id, company_id, created_by, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at, updated_at`
+
+type apiKeyRepository struct {
+	db db.Db
+}
+
+func NewAPIKeyRepository(db db.Db) repository.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	query := `
+		INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		key.ID, key.CompanyID, key.CreatedBy, key.Name, key.Prefix, key.KeyHash,
+		strings.Join(key.Scopes, " "), key.ExpiresAt, key.LastUsedAt, key.RevokedAt,
+		key.CreatedAt, key.UpdatedAt,
+	)
+	return err
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, id string) (*model.APIKey, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	return r.findOne(ctx, query, prefix)
+}
+
+var apiKeyListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":           "id",
+		"name":         "name",
+		"prefix":       "prefix",
+		"company_id":   "company_id",
+		"created_by":   "created_by",
+		"expires_at":   "expires_at",
+		"last_used_at": "last_used_at",
+		"revoked_at":   "revoked_at",
+		"created_at":   "created_at",
+	},
+	Search:      []string{"name", "prefix"},
+	DefaultSort: "created_at DESC",
+	Tiebreaker:  "id",
+}
+
+func (r *apiKeyRepository) FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.APIKey, int64, error) {
+	from := ` FROM api_keys WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		from += fmt.Sprintf(" AND company_id = $%d", len(args))
+	}
+	filter, args, err := query.Where(apiKeyListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(apiKeyListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT `+apiKeyColumns+from+orderBy+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	keys := make([]*model.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, total, rows.Err()
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = $1, updated_at = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`
+	return execConditional(ctx, r.db.GetDb(), query, time.Now().UTC(), id)
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $1 - INTERVAL '1 minute')
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query, at, id)
+	return err
+}
+
+func (r *apiKeyRepository) findOne(ctx context.Context, query string, arg string) (*model.APIKey, error) {
+	key, err := scanAPIKey(r.db.GetDb().QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+type apiKeyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row apiKeyScanner) (*model.APIKey, error) {
+	var k model.APIKey
+	var scopes string
+	err := row.Scan(
+		&k.ID, &k.CompanyID, &k.CreatedBy, &k.Name, &k.Prefix, &k.KeyHash, &scopes,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt, &k.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Stored space separated, like OAuth scopes
+	k.Scopes = strings.Fields(scopes)
+	return &k, nil
+}
@@ -0,0 +1,148 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+const auditEventColumns = `id, occurred_at, actor_id, impersonator_id, company_id, action, resource_type, resource_id, changes, ip_address, user_agent`
+
+type auditRepository struct {
+	db db.Db
+}
+
+func NewAuditRepository(db db.Db) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, e *model.AuditEvent) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_events (` + auditEventColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = r.db.GetDb().ExecContext(ctx, query,
+		e.ID, e.OccurredAt, e.ActorID, e.ImpersonatorID, e.CompanyID, e.Action,
+		e.ResourceType, e.ResourceID, changes, e.IPAddress, e.UserAgent,
+	)
+	return err
+}
+
+var auditListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":              "id",
+		"occurred_at":     "occurred_at",
+		"actor_id":        "actor_id",
+		"impersonator_id": "impersonator_id",
+		"company_id":      "company_id",
+		"action":          "action",
+		"resource_type":   "resource_type",
+		"resource_id":     "resource_id",
+	},
+	DefaultSort: "occurred_at DESC",
+	Tiebreaker:  "id",
+	Keyset:      &listquery.Keyset{Time: "occurred_at", ID: "id"},
+}
+
+func (r *auditRepository) FindAll(ctx context.Context, query listquery.Query, filter repository.AuditFilter) ([]*model.AuditEvent, listquery.PageInfo, error) {
+	var info listquery.PageInfo
+	from := ` FROM audit_events WHERE 1=1`
+	args := []interface{}{}
+	where := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		from += fmt.Sprintf(clause, len(args))
+	}
+	if filter.CompanyID != "" {
+		if !isUUID(filter.CompanyID) {
+			return []*model.AuditEvent{}, info, nil
+		}
+		where(" AND company_id = $%d", filter.CompanyID)
+	}
+	if filter.ActorID != "" {
+		if !isUUID(filter.ActorID) {
+			return []*model.AuditEvent{}, info, nil
+		}
+		where(" AND actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		where(" AND action = $%d", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		where(" AND resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		where(" AND resource_id = $%d", filter.ResourceID)
+	}
+	if filter.From != nil {
+		where(" AND occurred_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where(" AND occurred_at < $%d", *filter.To)
+	}
+	conditions, args, err := query.Where(auditListFields, args)
+	if err != nil {
+		return nil, info, err
+	}
+	from += conditions
+	orderBy, err := query.OrderBy(auditListFields)
+	if err != nil {
+		return nil, info, err
+	}
+
+	if query.Counted() {
+		var total int64
+		err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
+		if err != nil {
+			return nil, info, err
+		}
+		info.Total = &total
+	}
+
+	seek, args, err := query.Seek(auditListFields, args)
+	if err != nil {
+		return nil, info, err
+	}
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT `+auditEventColumns+from+seek+orderBy+page, args...)
+	if err != nil {
+		return nil, info, err
+	}
+	defer rows.Close()
+
+	events := make([]*model.AuditEvent, 0)
+	for rows.Next() {
+		var e model.AuditEvent
+		var changes []byte
+		err := rows.Scan(
+			&e.ID, &e.OccurredAt, &e.ActorID, &e.ImpersonatorID, &e.CompanyID, &e.Action,
+			&e.ResourceType, &e.ResourceID, &changes, &e.IPAddress, &e.UserAgent,
+		)
+		if err != nil {
+			return nil, info, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, info, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, info, err
+	}
+
+	if query.HasMore(len(events)) {
+		events = events[:len(events)-1]
+		last := events[len(events)-1]
+		info.Next = &listquery.Cursor{Time: last.OccurredAt, ID: last.ID}
+	}
+	return events, info, nil
+}
@@ -0,0 +1,128 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+const authSessionColumns = `id, user_id, token, previous_token, expires_at, revoked, revoked_at, user_agent, ip_address,
+		last_used_at, created_at`
+
+type authSessionRepository struct {
+	db db.Db
+}
+
+func NewAuthSessionRepository(db db.Db) repository.AuthSessionRepository {
+	return &authSessionRepository{db: db}
+}
+
+func scanAuthSession(row rowScanner) (*model.AuthSession, error) {
+	var s model.AuthSession
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.TokenHash, &s.PreviousTokenHash, &s.ExpiresAt, &s.Revoked, &s.RevokedAt, &s.UserAgent,
+		&s.IPAddress, &s.LastUsedAt, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *authSessionRepository) Create(ctx context.Context, session *model.AuthSession) error {
+	query := `
+		INSERT INTO auth_tokens (` + authSessionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		session.ID, session.UserID, session.TokenHash, session.PreviousTokenHash, session.ExpiresAt, session.Revoked,
+		session.RevokedAt, session.UserAgent, session.IPAddress, session.LastUsedAt, session.CreatedAt,
+	)
+	return err
+}
+
+func (r *authSessionRepository) FindByID(ctx context.Context, id string) (*model.AuthSession, error) {
+	return r.findOne(ctx, `SELECT `+authSessionColumns+` FROM auth_tokens WHERE id = $1`, id)
+}
+
+func (r *authSessionRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*model.AuthSession, error) {
+	query := `
+		SELECT ` + authSessionColumns + `
+		FROM auth_tokens
+		WHERE user_id = $1 AND revoked = FALSE AND expires_at > $2
+		ORDER BY COALESCE(last_used_at, created_at) DESC
+	`
+	rows, err := r.db.GetDb().QueryContext(ctx, query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*model.AuthSession, 0)
+	for rows.Next() {
+		session, err := scanAuthSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *authSessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error) {
+	return r.findOne(ctx, `SELECT `+authSessionColumns+` FROM auth_tokens WHERE token = $1`, tokenHash)
+}
+
+func (r *authSessionRepository) FindByPreviousTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error) {
+	return r.findOne(ctx, `SELECT `+authSessionColumns+` FROM auth_tokens WHERE previous_token = $1`, tokenHash)
+}
+
+func (r *authSessionRepository) Rotate(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time, userAgent, ipAddress *string) (bool, error) {
+	query := `
+		UPDATE auth_tokens
+		SET token = $1, previous_token = $2, expires_at = $3, user_agent = $4, ip_address = $5, last_used_at = $6
+		WHERE id = $7 AND token = $2 AND revoked = FALSE
+	`
+	result, err := r.db.GetDb().ExecContext(ctx, query, newHash, oldHash, expiresAt, userAgent, ipAddress, time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *authSessionRepository) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE auth_tokens SET revoked = TRUE, revoked_at = $1 WHERE id = $2 AND revoked = FALSE`
+	_, err := r.db.GetDb().ExecContext(ctx, query, time.Now().UTC(), id)
+	return err
+}
+
+func (r *authSessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	query := `UPDATE auth_tokens SET revoked = TRUE, revoked_at = $1 WHERE user_id = $2 AND revoked = FALSE`
+	_, err := r.db.GetDb().ExecContext(ctx, query, time.Now().UTC(), userID)
+	return err
+}
+
+func (r *authSessionRepository) RevokeOthersForUser(ctx context.Context, userID, keepSessionID string) error {
+	query := `UPDATE auth_tokens SET revoked = TRUE, revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked = FALSE`
+	_, err := r.db.GetDb().ExecContext(ctx, query, time.Now().UTC(), userID, keepSessionID)
+	return err
+}
+
+func (r *authSessionRepository) findOne(ctx context.Context, query string, args ...any) (*model.AuthSession, error) {
+	session, err := scanAuthSession(r.db.GetDb().QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,62 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyConn is a database connection where every statement matches no row, standing in for
+// Postgres when a guarded insert finds its parent in the trash. It records what was run.
+type emptyConn struct {
+	queries []string
+}
+
+func (c *emptyConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *emptyConn) Driver() driver.Driver                        { return nil }
+func (c *emptyConn) Prepare(query string) (driver.Stmt, error) {
+	return &emptyStmt{conn: c, query: query}, nil
+}
+func (c *emptyConn) Close() error              { return nil }
+func (c *emptyConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *emptyConn) Commit() error             { return nil }
+func (c *emptyConn) Rollback() error           { return nil }
+
+type emptyStmt struct {
+	conn  *emptyConn
+	query string
+}
+
+func (s *emptyStmt) Close() error  { return nil }
+func (s *emptyStmt) NumInput() int { return -1 }
+
+func (s *emptyStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.conn.queries = append(s.conn.queries, s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *emptyStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.conn.queries = append(s.conn.queries, s.query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"version"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func newEmptyDb(t *testing.T, conn *emptyConn) db.Db {
+	t.Helper()
+	sqlDB := sql.OpenDB(conn)
+	t.Cleanup(func() { sqlDB.Close() })
+	tx, err := sqlDB.Begin()
+	require.NoError(t, err)
+	t.Cleanup(func() { tx.Rollback() })
+	return db.NewTxAdapter(tx)
+}
@@ -0,0 +1,52 @@
+package psql
+
+import (
+	"errors"
+	"regexp"
+
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// isUniqueViolation reports whether err is a unique constraint violation from either Postgres driver.
+func isUniqueViolation(err error) bool {
+	code, _ := pgError(err)
+	return code == uniqueViolation
+}
+
+// foreignKeyColumn matches the detail of a foreign key violation, such as
+// `Key (site_id)=(...) is not present in table "construction_sites".`
+var foreignKeyColumn = regexp.MustCompile(`^Key \((\w+)\)=`)
+
+// referenceError turns a foreign key violation into a pkgRepository.ReferenceError naming the
+// column, any other error is returned as it is.
+func referenceError(err error) error {
+	code, detail := pgError(err)
+	if code != foreignKeyViolation {
+		return err
+	}
+	match := foreignKeyColumn.FindStringSubmatch(detail)
+	if match == nil {
+		return err
+	}
+	return &pkgRepository.ReferenceError{Field: match[1]}
+}
+
+// pgError returns the SQLSTATE code and detail of an error from either Postgres driver.
+func pgError(err error) (code, detail string) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, pgErr.Detail
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code), pqErr.Detail
+	}
+	return "", ""
+}
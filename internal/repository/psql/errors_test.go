@@ -0,0 +1,54 @@
+package psql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceError(t *testing.T) {
+	other := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "pgx foreign key violation - Names the column",
+			err:      &pgconn.PgError{Code: "23503", Detail: `Key (site_id)=(6f9619ff-8b86-d011-b42d-00c04fc964ff) is not present in table "construction_sites".`},
+			expected: &pkgRepository.ReferenceError{Field: "site_id"},
+		},
+		{
+			name:     "Wrapped pq foreign key violation - Names the column",
+			err:      fmt.Errorf("failed to create room: %w", &pq.Error{Code: "23503", Detail: `Key (unit_id)=(6f9619ff-8b86-d011-b42d-00c04fc964ff) is not present in table "units".`}),
+			expected: &pkgRepository.ReferenceError{Field: "unit_id"},
+		},
+		{
+			name:     "Unique violation - Unchanged",
+			err:      &pgconn.PgError{Code: "23505", Detail: `Key (email)=(jane@example.com) already exists.`},
+			expected: &pgconn.PgError{Code: "23505", Detail: `Key (email)=(jane@example.com) already exists.`},
+		},
+		{
+			name:     "Other error - Unchanged",
+			err:      other,
+			expected: other,
+		},
+		{
+			name:     "No error - None",
+			err:      nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, referenceError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+// execConditional runs an update guarded by its WHERE clause and reports whether it hit a row,
+// which is how single-use tokens and state transitions stay race free.
+func execConditional(ctx context.Context, conn db.DbTx, query string, args ...interface{}) (bool, error) {
+	result, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// execCount runs a statement and returns how many rows it hit.
+func execCount(ctx context.Context, conn db.DbTx, query string, args ...interface{}) (int64, error) {
+	result, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// isAlive reports whether the row of the table exists out of the trash. Writes guarded on their
+// parent being alive use it to tell a trashed parent from a version conflict.
+func isAlive(ctx context.Context, conn db.DbTx, table, id string) (bool, error) {
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NULL)`, table)
+	var alive bool
+	err := conn.QueryRowContext(ctx, query, id).Scan(&alive)
+	return alive, err
+}
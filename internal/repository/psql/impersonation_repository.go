@@ -0,0 +1,122 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+const impersonationColumns = `id, admin_id, user_id, session_id, reason, ip_address, user_agent, started_at, expires_at, ended_at`
+
+type impersonationRepository struct {
+	db db.Db
+}
+
+func NewImpersonationRepository(db db.Db) repository.ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(ctx context.Context, i *model.Impersonation) error {
+	query := `
+		INSERT INTO impersonations (` + impersonationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		i.ID, i.AdminID, i.UserID, i.SessionID, i.Reason, i.IPAddress, i.UserAgent,
+		i.StartedAt, i.ExpiresAt, i.EndedAt,
+	)
+	return err
+}
+
+func (r *impersonationRepository) FindByID(ctx context.Context, id string) (*model.Impersonation, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
+	query := `SELECT ` + impersonationColumns + ` FROM impersonations WHERE id = $1`
+	i, err := scanImpersonation(r.db.GetDb().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return i, nil
+}
+
+var impersonationListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":         "id",
+		"admin_id":   "admin_id",
+		"user_id":    "user_id",
+		"started_at": "started_at",
+		"expires_at": "expires_at",
+		"ended_at":   "ended_at",
+	},
+	Search:      []string{"reason"},
+	DefaultSort: "started_at DESC",
+	Tiebreaker:  "id",
+}
+
+func (r *impersonationRepository) FindAll(ctx context.Context, query listquery.Query) ([]*model.Impersonation, int64, error) {
+	from := ` FROM impersonations WHERE 1=1`
+	args := []interface{}{}
+	filter, args, err := query.Where(impersonationListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(impersonationListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT `+impersonationColumns+from+orderBy+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	impersonations := make([]*model.Impersonation, 0)
+	for rows.Next() {
+		i, err := scanImpersonation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		impersonations = append(impersonations, i)
+	}
+
+	return impersonations, total, rows.Err()
+}
+
+func (r *impersonationRepository) End(ctx context.Context, id string, at time.Time) (bool, error) {
+	query := `UPDATE impersonations SET ended_at = $1 WHERE id = $2 AND ended_at IS NULL`
+	return execConditional(ctx, r.db.GetDb(), query, at, id)
+}
+
+type impersonationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanImpersonation(row impersonationScanner) (*model.Impersonation, error) {
+	var i model.Impersonation
+	err := row.Scan(
+		&i.ID, &i.AdminID, &i.UserID, &i.SessionID, &i.Reason, &i.IPAddress, &i.UserAgent,
+		&i.StartedAt, &i.ExpiresAt, &i.EndedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
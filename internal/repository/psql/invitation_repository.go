@@ -0,0 +1,168 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+const invitationColumns = `id, email, role, company_id, unit_id, token_hash, invited_by, expires_at, accepted_at, revoked_at, created_at, updated_at`
+
+type invitationRepository struct {
+	db db.Db
+}
+
+func NewInvitationRepository(db db.Db) repository.InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) WithTx(tx db.Db) repository.InvitationRepository {
+	return &invitationRepository{db: tx}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *model.Invitation) error {
+	query := `
+		INSERT INTO invitations (` + invitationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		invitation.ID, invitation.Email, invitation.Role, invitation.CompanyID, invitation.UnitID,
+		invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt, invitation.AcceptedAt,
+		invitation.RevokedAt, invitation.CreatedAt, invitation.UpdatedAt,
+	)
+	return err
+}
+
+func (r *invitationRepository) FindByID(ctx context.Context, id string) (*model.Invitation, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *invitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE token_hash = $1`
+	return r.findOne(ctx, query, tokenHash)
+}
+
+var invitationListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":          "id",
+		"email":       "email",
+		"role":        "role",
+		"company_id":  "company_id",
+		"unit_id":     "unit_id",
+		"invited_by":  "invited_by",
+		"expires_at":  "expires_at",
+		"accepted_at": "accepted_at",
+		"revoked_at":  "revoked_at",
+		"created_at":  "created_at",
+	},
+	Search:      []string{"email"},
+	DefaultSort: "created_at DESC",
+	Tiebreaker:  "id",
+}
+
+func (r *invitationRepository) FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.Invitation, int64, error) {
+	from := ` FROM invitations WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		from += fmt.Sprintf(" AND company_id = $%d", len(args))
+	}
+	filter, args, err := query.Where(invitationListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(invitationListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT `+invitationColumns+from+orderBy+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invitations := make([]*model.Invitation, 0)
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, total, rows.Err()
+}
+
+func (r *invitationRepository) RenewToken(ctx context.Context, invitation *model.Invitation) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET token_hash = $1, expires_at = $2, updated_at = $3
+		WHERE id = $4 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	return execConditional(ctx, r.db.GetDb(), query, invitation.TokenHash, invitation.ExpiresAt, invitation.UpdatedAt, invitation.ID)
+}
+
+func (r *invitationRepository) MarkAccepted(ctx context.Context, id string) (bool, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE invitations
+		SET accepted_at = $1, updated_at = $1
+		WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $1
+	`
+	return execConditional(ctx, r.db.GetDb(), query, now, id)
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET revoked_at = $1, updated_at = $1
+		WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	return execConditional(ctx, r.db.GetDb(), query, time.Now().UTC(), id)
+}
+
+func (r *invitationRepository) findOne(ctx context.Context, query string, arg string) (*model.Invitation, error) {
+	invitation, err := scanInvitation(r.db.GetDb().QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+type invitationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvitation(row invitationScanner) (*model.Invitation, error) {
+	var i model.Invitation
+	err := row.Scan(
+		&i.ID, &i.Email, &i.Role, &i.CompanyID, &i.UnitID, &i.TokenHash, &i.InvitedBy,
+		&i.ExpiresAt, &i.AcceptedAt, &i.RevokedAt, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
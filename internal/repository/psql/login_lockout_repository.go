@@ -0,0 +1,64 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+type loginLockoutRepository struct {
+	db db.Db
+}
+
+func NewLoginLockoutRepository(db db.Db) repository.LoginLockoutRepository {
+	return &loginLockoutRepository{db: db}
+}
+
+func (r *loginLockoutRepository) FindByUserID(ctx context.Context, userID string) (*model.LoginLockout, error) {
+	query := `
+		SELECT user_id, failed_attempts, locked_until, last_failed_at
+		FROM login_lockouts
+		WHERE user_id = $1
+	`
+	var l model.LoginLockout
+	err := r.db.GetDb().QueryRowContext(ctx, query, userID).Scan(
+		&l.UserID, &l.FailedAttempts, &l.LockedUntil, &l.LastFailedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *loginLockoutRepository) RecordFailure(ctx context.Context, userID string, window time.Duration) (int, error) {
+	now := time.Now().UTC()
+	query := `
+		INSERT INTO login_lockouts AS l (user_id, failed_attempts, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			failed_attempts = CASE WHEN l.last_failed_at < $3 THEN 1 ELSE l.failed_attempts + 1 END,
+			last_failed_at = $2
+		RETURNING failed_attempts
+	`
+	var attempts int
+	err := r.db.GetDb().QueryRowContext(ctx, query, userID, now, now.Add(-window)).Scan(&attempts)
+	return attempts, err
+}
+
+func (r *loginLockoutRepository) Lock(ctx context.Context, userID string, until time.Time) error {
+	query := `UPDATE login_lockouts SET locked_until = $1 WHERE user_id = $2`
+	_, err := r.db.GetDb().ExecContext(ctx, query, until, userID)
+	return err
+}
+
+func (r *loginLockoutRepository) Reset(ctx context.Context, userID string) error {
+	_, err := r.db.GetDb().ExecContext(ctx, `DELETE FROM login_lockouts WHERE user_id = $1`, userID)
+	return err
+}
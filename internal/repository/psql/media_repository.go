@@ -0,0 +1,210 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+const mediaColumns = `id, room_id, url, thumbnail_url, preview_url, storage_key, file_name, content_type, size_bytes,
+		uploaded_by, processing_status, processing_error, taken_at, taken_at_source, camera_make, camera_model,
+		latitude, longitude, projection_type, pose_heading, width, height, tiles_status, tiles_error, tile_size,
+		tile_face_size, created_at`
+
+// mediaSelectColumns are mediaColumns and the version, which starts at its default on upload.
+const mediaSelectColumns = mediaColumns + `, version`
+
+// mediaRoomAlive hides the media of rooms in the trash, they come back with the room.
+const mediaRoomAlive = `EXISTS (SELECT 1 FROM rooms r WHERE r.id = media.room_id AND r.deleted_at IS NULL)`
+
+type mediaRepository struct {
+	db db.Db
+}
+
+func NewMediaRepository(db db.Db) repository.MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) WithTx(tx db.Db) repository.MediaRepository {
+	return &mediaRepository{db: tx}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMedia(row rowScanner) (*model.Media, error) {
+	var m model.Media
+	err := row.Scan(
+		&m.ID, &m.RoomID, &m.URL, &m.ThumbnailURL, &m.PreviewURL, &m.StorageKey, &m.FileName, &m.ContentType, &m.SizeBytes,
+		&m.UploadedBy, &m.ProcessingStatus, &m.ProcessingError, &m.TakenAt, &m.TakenAtSource, &m.CameraMake, &m.CameraModel,
+		&m.Latitude, &m.Longitude, &m.ProjectionType, &m.PoseHeading, &m.Width, &m.Height, &m.TilesStatus, &m.TilesError,
+		&m.TileSize, &m.TileFaceSize, &m.CreatedAt, &m.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *model.Media) error {
+	query := `
+		INSERT INTO media (` + mediaColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)
+		RETURNING version
+	`
+	return r.db.GetDb().QueryRowContext(ctx, query,
+		media.ID, media.RoomID, media.URL, media.ThumbnailURL, media.PreviewURL, media.StorageKey, media.FileName,
+		media.ContentType, media.SizeBytes, media.UploadedBy, media.ProcessingStatus, media.ProcessingError,
+		media.TakenAt, media.TakenAtSource, media.CameraMake, media.CameraModel, media.Latitude, media.Longitude,
+		media.ProjectionType, media.PoseHeading, media.Width, media.Height, media.TilesStatus, media.TilesError,
+		media.TileSize, media.TileFaceSize, media.CreatedAt,
+	).Scan(&media.Version)
+}
+
+func (r *mediaRepository) FindByID(ctx context.Context, id string) (*model.Media, error) {
+	query := `SELECT ` + mediaSelectColumns + ` FROM media WHERE id = $1 AND ` + mediaRoomAlive
+	m, err := scanMedia(r.db.GetDb().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+var mediaListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":                "id",
+		"file_name":         "file_name",
+		"content_type":      "content_type",
+		"size_bytes":        "size_bytes",
+		"uploaded_by":       "uploaded_by",
+		"processing_status": "processing_status",
+		"taken_at":          "taken_at",
+		"taken_at_source":   "taken_at_source",
+		"projection_type":   "projection_type",
+		"created_at":        "created_at",
+	},
+	Search:      []string{"file_name"},
+	DefaultSort: "taken_at DESC, created_at DESC",
+	Tiebreaker:  "id",
+	// Cursor pages follow uploads rather than capture dates, so new captures never land
+	// behind a page already read
+	Keyset: &listquery.Keyset{Time: "created_at", ID: "id"},
+}
+
+func (r *mediaRepository) FindAllByRoomID(ctx context.Context, query listquery.Query, roomID string) ([]*model.Media, listquery.PageInfo, error) {
+	var info listquery.PageInfo
+	filter, args, err := query.Where(mediaListFields, []interface{}{roomID})
+	if err != nil {
+		return nil, info, err
+	}
+	from := ` FROM media WHERE room_id = $1 AND ` + mediaRoomAlive + filter
+	orderBy, err := query.OrderBy(mediaListFields)
+	if err != nil {
+		return nil, info, err
+	}
+
+	if query.Counted() {
+		var total int64
+		err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
+		if err != nil {
+			return nil, info, err
+		}
+		info.Total = &total
+	}
+
+	seek, args, err := query.Seek(mediaListFields, args)
+	if err != nil {
+		return nil, info, err
+	}
+	page, args := query.Page(args)
+	media, err := r.queryMedia(ctx, `SELECT `+mediaSelectColumns+from+seek+orderBy+page, args...)
+	if err != nil {
+		return nil, info, err
+	}
+	if query.HasMore(len(media)) {
+		media = media[:len(media)-1]
+		last := media[len(media)-1]
+		info.Next = &listquery.Cursor{Time: last.CreatedAt, ID: last.ID}
+	}
+	return media, info, nil
+}
+
+func (r *mediaRepository) FindByProcessingStatus(ctx context.Context, limit int, statuses ...model.MediaProcessingStatus) ([]*model.Media, error) {
+	query := `
+		SELECT ` + mediaSelectColumns + `
+		FROM media
+		WHERE processing_status = ANY($1)
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+	return r.queryMedia(ctx, query, statusValues(statuses), limit)
+}
+
+func (r *mediaRepository) FindByTilesStatus(ctx context.Context, limit int, statuses ...model.MediaProcessingStatus) ([]*model.Media, error) {
+	query := `
+		SELECT ` + mediaSelectColumns + `
+		FROM media
+		WHERE tiles_status = ANY($1)
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+	return r.queryMedia(ctx, query, statusValues(statuses), limit)
+}
+
+func (r *mediaRepository) UpdateProcessing(ctx context.Context, media *model.Media) error {
+	query := `
+		UPDATE media
+		SET thumbnail_url = $1, preview_url = $2, processing_status = $3, processing_error = $4,
+			tiles_status = $5, tiles_error = $6, tile_size = $7, tile_face_size = $8, version = version + 1
+		WHERE id = $9
+		RETURNING version
+	`
+	err := r.db.GetDb().QueryRowContext(ctx, query,
+		media.ThumbnailURL, media.PreviewURL, media.ProcessingStatus, media.ProcessingError,
+		media.TilesStatus, media.TilesError, media.TileSize, media.TileFaceSize, media.ID,
+	).Scan(&media.Version)
+	if err == sql.ErrNoRows {
+		return nil // Deleted while being processed
+	}
+	return err
+}
+
+func (r *mediaRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM media WHERE id = $1`
+	_, err := r.db.GetDb().ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *mediaRepository) queryMedia(ctx context.Context, query string, args ...any) ([]*model.Media, error) {
+	rows, err := r.db.GetDb().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	media := make([]*model.Media, 0)
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+func statusValues(statuses []model.MediaProcessingStatus) []string {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	return values
+}
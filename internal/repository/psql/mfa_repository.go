@@ -0,0 +1,170 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+type mfaRepository struct {
+	db db.Db
+}
+
+func NewMFARepository(db db.Db) repository.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID string) (*model.UserMFA, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+	var m model.UserMFA
+	err := r.db.GetDb().QueryRowContext(ctx, query, userID).Scan(
+		&m.UserID, &m.Secret, &m.EnabledAt, &m.LastUsedStep, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mfaRepository) SavePending(ctx context.Context, userID, secret string) error {
+	// An enabled factor is never overwritten, it has to be disabled first
+	query := `
+		INSERT INTO user_mfa (user_id, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = EXCLUDED.updated_at
+		WHERE user_mfa.enabled_at IS NULL
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query, userID, secret, time.Now().UTC())
+	return err
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa
+		SET enabled_at = $1, last_used_step = $2, updated_at = $1
+		WHERE user_id = $3 AND enabled_at IS NULL
+	`
+	return execConditional(ctx, r.db.GetDb(), query, time.Now().UTC(), step, userID)
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $1, updated_at = $2
+		WHERE user_id = $3 AND last_used_step < $1
+	`
+	return execConditional(ctx, r.db.GetDb(), query, step, time.Now().UTC(), userID)
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID string) (err error) {
+	tx, err := r.db.BegrinTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.db.Rollback(ctx, tx)
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return r.db.Commit(ctx, tx)
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) (err error) {
+	tx, err := r.db.BegrinTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.db.Rollback(ctx, tx)
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	query := `INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)`
+	now := time.Now().UTC()
+	for _, codeHash := range codeHashes {
+		if _, err = tx.ExecContext(ctx, query, userID, codeHash, now); err != nil {
+			return err
+		}
+	}
+	return r.db.Commit(ctx, tx)
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`
+	return execConditional(ctx, r.db.GetDb(), query, time.Now().UTC(), userID, codeHash)
+}
+
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `SELECT count(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	err := r.db.GetDb().QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *mfaRepository) CreateChallenge(ctx context.Context, challenge *model.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, token_hash, attempts, expires_at, used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		challenge.ID, challenge.UserID, challenge.TokenHash, challenge.Attempts,
+		challenge.ExpiresAt, challenge.UsedAt, challenge.CreatedAt,
+	)
+	return err
+}
+
+func (r *mfaRepository) FindChallengeByTokenHash(ctx context.Context, tokenHash string) (*model.MFAChallenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, attempts, expires_at, used_at, created_at
+		FROM mfa_challenges
+		WHERE token_hash = $1
+	`
+	var c model.MFAChallenge
+	err := r.db.GetDb().QueryRowContext(ctx, query, tokenHash).Scan(
+		&c.ID, &c.UserID, &c.TokenHash, &c.Attempts, &c.ExpiresAt, &c.UsedAt, &c.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *mfaRepository) RecordChallengeAttempt(ctx context.Context, id string) error {
+	_, err := r.db.GetDb().ExecContext(ctx, `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+func (r *mfaRepository) MarkChallengeUsed(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE mfa_challenges SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+	return execConditional(ctx, r.db.GetDb(), query, time.Now().UTC(), id)
+}
@@ -2,11 +2,14 @@ package psql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
 type PostgresRoomRepository struct {
@@ -18,30 +21,65 @@ func NewPostgresRoomRepository(db db.Db) repository.RoomRepository {
 }
 
 func (r *PostgresRoomRepository) Create(ctx context.Context, room *model.Room) error {
-	query := `INSERT INTO rooms (name, unit_id, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	// Only inserts while the unit is out of the trash, a trashed unit is reported like a missing one
+	query := `
+		INSERT INTO rooms (name, unit_id, is_panoramic, created_at, updated_at)
+		SELECT $1, u.id, $3, $4, $5 FROM units u WHERE u.id = $2 AND u.deleted_at IS NULL
+		RETURNING id, version`
 	// Use GetDb() to access DbTx
-	err := r.db.GetDb().QueryRowContext(ctx, query, room.Name, room.UnitID, room.CreatedAt, room.UpdatedAt).Scan(&room.ID)
+	err := r.db.GetDb().QueryRowContext(ctx, query, room.Name, room.UnitID, room.Panoramic, room.CreatedAt, room.UpdatedAt).Scan(&room.ID, &room.Version)
+	if err == sql.ErrNoRows {
+		return &pkgRepository.ReferenceError{Field: "unit_id"}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create room: %w", err)
+		return fmt.Errorf("failed to create room: %w", referenceError(err))
 	}
 	return nil
 }
 
-func (r *PostgresRoomRepository) FindAll(ctx context.Context, limit, offset int, unitID string) ([]*model.Room, int64, error) {
-	query := `SELECT id, name, unit_id, created_at, updated_at FROM rooms WHERE 1=1`
-	args := []interface{}{}
-	argCounter := 1
+var roomListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":         "r.id",
+		"name":       "r.name",
+		"unit_id":    "r.unit_id",
+		"panoramic":  "r.is_panoramic",
+		"created_at": "r.created_at",
+		"updated_at": "r.updated_at",
+	},
+	Search:      []string{"r.name"},
+	DefaultSort: "r.created_at DESC",
+	Tiebreaker:  "r.id",
+}
 
-	if unitID != "" {
-		query += fmt.Sprintf(" AND unit_id = $%d", argCounter)
-		args = append(args, unitID)
-		argCounter++
+func (r *PostgresRoomRepository) FindAll(ctx context.Context, query listquery.Query, scope repository.TenantScope) ([]*model.Room, int64, error) {
+	from := ` FROM rooms r JOIN units u ON u.id = r.unit_id JOIN construction_sites s ON s.id = u.site_id WHERE r.deleted_at IS NULL`
+	args := []interface{}{}
+	if scope.CompanyID != "" {
+		args = append(args, scope.CompanyID)
+		from += fmt.Sprintf(" AND s.company_id = $%d", len(args))
+	}
+	if scope.CustomerID != "" {
+		args = append(args, scope.CustomerID)
+		from += fmt.Sprintf(" AND u.client_id = $%d", len(args))
+	}
+	filter, args, err := query.Where(roomListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(roomListFields)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
-	args = append(args, limit, offset)
+	// Count with the same filters, the scope must never leak other tenants' totals
+	var total int64
+	if err := r.db.GetDb().QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rooms: %w", err)
+	}
 
-	rows, err := r.db.GetDb().QueryContext(ctx, query, args...)
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT r.id, r.name, r.unit_id, r.is_panoramic, r.created_at, r.updated_at, r.version`+from+orderBy+page, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list rooms: %w", err)
 	}
@@ -50,52 +88,53 @@ func (r *PostgresRoomRepository) FindAll(ctx context.Context, limit, offset int,
 	rooms := make([]*model.Room, 0)
 	for rows.Next() {
 		var room model.Room
-		if err := rows.Scan(&room.ID, &room.Name, &room.UnitID, &room.CreatedAt, &room.UpdatedAt); err != nil {
+		if err := rows.Scan(&room.ID, &room.Name, &room.UnitID, &room.Panoramic, &room.CreatedAt, &room.UpdatedAt, &room.Version); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan room: %w", err)
 		}
 		rooms = append(rooms, &room)
 	}
 
-	var total int64
-	countQuery := `SELECT COUNT(*) FROM rooms`
-	// Simple count for now, if filtering becomes heavy we should filter count too
-	if unitID != "" {
-		countQuery += " WHERE unit_id = $1"
-		if err := r.db.GetDb().QueryRowContext(ctx, countQuery, unitID).Scan(&total); err != nil {
-			return nil, 0, fmt.Errorf("failed to count rooms: %w", err)
-		}
-	} else {
-		if err := r.db.GetDb().QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
-			return nil, 0, fmt.Errorf("failed to count rooms: %w", err)
-		}
-	}
-
 	return rooms, total, nil
 }
 
 func (r *PostgresRoomRepository) FindByID(ctx context.Context, id string) (*model.Room, error) {
-	query := `SELECT id, name, unit_id, created_at, updated_at FROM rooms WHERE id = $1`
+	query := `SELECT id, name, unit_id, is_panoramic, created_at, updated_at, version FROM rooms WHERE id = $1 AND deleted_at IS NULL`
 	var room model.Room
-	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&room.ID, &room.Name, &room.UnitID, &room.CreatedAt, &room.UpdatedAt)
+	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&room.ID, &room.Name, &room.UnitID, &room.Panoramic, &room.CreatedAt, &room.UpdatedAt, &room.Version)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("failed to find room: %w", err)
 	}
 	return &room, nil
 }
 
 func (r *PostgresRoomRepository) Update(ctx context.Context, room *model.Room) error {
-	query := `UPDATE rooms SET name = $1, unit_id = $2, updated_at = $3 WHERE id = $4`
-	_, err := r.db.GetDb().ExecContext(ctx, query, room.Name, room.UnitID, room.UpdatedAt, room.ID)
+	query := `
+		UPDATE rooms SET name = $1, unit_id = $2, is_panoramic = $3, updated_at = $4, version = version + 1
+		WHERE id = $5 AND version = $6 AND deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM units WHERE id = $2 AND deleted_at IS NULL)`
+	updated, err := execConditional(ctx, r.db.GetDb(), query, room.Name, room.UnitID, room.Panoramic, room.UpdatedAt, room.ID, room.Version)
 	if err != nil {
-		return fmt.Errorf("failed to update room: %w", err)
+		return fmt.Errorf("failed to update room: %w", referenceError(err))
 	}
+	if !updated {
+		alive, err := isAlive(ctx, r.db.GetDb(), "units", room.UnitID)
+		if err != nil {
+			return fmt.Errorf("failed to update room: %w", err)
+		}
+		if !alive {
+			return &pkgRepository.ReferenceError{Field: "unit_id"}
+		}
+		return pkgRepository.ErrVersionConflict
+	}
+	room.Version++
 	return nil
 }
 
-func (r *PostgresRoomRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM rooms WHERE id = $1`
-	_, err := r.db.GetDb().ExecContext(ctx, query, id)
-	if err != nil {
+func (r *PostgresRoomRepository) Delete(ctx context.Context, id string, version int64) error {
+	if err := softDelete(ctx, r.db.GetDb(), model.TrashItemRoom, id, version); err != nil {
 		return fmt.Errorf("failed to delete room: %w", err)
 	}
 	return nil
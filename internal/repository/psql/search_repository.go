@@ -0,0 +1,155 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+type searchRepository struct {
+	db db.Db
+}
+
+func NewSearchRepository(db db.Db) repository.SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Every branch of the search selects the same columns: the match, its rank and the
+// company, site and unit it sits in, NULL where they do not apply.
+//
+// Units and rooms are matched together with the names of what they sit in, so "riverside 2a"
+// finds flat 2A of the Riverside site. Their own name weighs most in the rank.
+var searchSelects = map[model.SearchResultType]string{
+	model.SearchResultSite: `
+		SELECT 'site' AS type, s.id::text AS id, s.name AS label, ts_rank(s.search_vector, q.query) AS rank,
+			c.id::text, c.name, NULL, NULL, NULL, NULL
+		FROM construction_sites s
+		JOIN companies c ON c.id = s.company_id
+		CROSS JOIN search q
+		WHERE s.search_vector @@ q.query AND s.deleted_at IS NULL AND c.deleted_at IS NULL`,
+	model.SearchResultUnit: `
+		SELECT 'unit' AS type, u.id::text AS id, u.name AS label, ts_rank(v.vector, q.query) AS rank,
+			c.id::text, c.name, s.id::text, s.name, NULL, NULL
+		FROM units u
+		JOIN construction_sites s ON s.id = u.site_id
+		JOIN companies c ON c.id = s.company_id
+		CROSS JOIN search q
+		CROSS JOIN LATERAL (SELECT setweight(u.search_vector, 'A') ||
+			setweight(to_tsvector('simple', s.name), 'B') AS vector) v
+		WHERE v.vector @@ q.query AND u.deleted_at IS NULL AND c.deleted_at IS NULL`,
+	model.SearchResultRoom: `
+		SELECT 'room' AS type, r.id::text AS id, r.name AS label, ts_rank(v.vector, q.query) AS rank,
+			c.id::text, c.name, s.id::text, s.name, u.id::text, u.name
+		FROM rooms r
+		JOIN units u ON u.id = r.unit_id
+		JOIN construction_sites s ON s.id = u.site_id
+		JOIN companies c ON c.id = s.company_id
+		CROSS JOIN search q
+		CROSS JOIN LATERAL (SELECT setweight(r.search_vector, 'A') ||
+			setweight(to_tsvector('simple', u.name), 'B') ||
+			setweight(to_tsvector('simple', s.name), 'C') AS vector) v
+		WHERE v.vector @@ q.query AND r.deleted_at IS NULL AND c.deleted_at IS NULL`,
+	model.SearchResultUser: `
+		SELECT 'user' AS type, usr.id::text AS id, usr.email AS label, ts_rank(usr.search_vector, q.query) AS rank,
+			c.id::text, c.name, NULL, NULL, NULL, NULL
+		FROM users usr
+		LEFT JOIN companies c ON c.id = usr.company_id
+		CROSS JOIN search q
+		WHERE usr.search_vector @@ q.query AND c.deleted_at IS NULL`,
+}
+
+// searchScopes are the conditions narrowing each branch down to a company, and to what is
+// assigned to a customer.
+var searchScopes = map[model.SearchResultType]struct{ company, customer string }{
+	model.SearchResultSite: {"s.company_id = $%d", "EXISTS (SELECT 1 FROM units cu WHERE cu.site_id = s.id AND cu.client_id = $%d)"},
+	model.SearchResultUnit: {"s.company_id = $%d", "u.client_id = $%d"},
+	model.SearchResultRoom: {"s.company_id = $%d", "u.client_id = $%d"},
+	model.SearchResultUser: {"usr.company_id = $%d", "usr.id = $%d"},
+}
+
+func (r *searchRepository) Search(ctx context.Context, query repository.SearchQuery) ([]*model.SearchResult, error) {
+	results := make([]*model.SearchResult, 0)
+	text := prefixQuery(query.Text)
+	if text == "" {
+		return results, nil
+	}
+
+	args := []interface{}{text}
+	branches := make([]string, 0, len(query.Scopes))
+	for _, t := range model.SearchResultTypes {
+		scope, ok := query.Scopes[t]
+		if !ok {
+			continue
+		}
+		branch := searchSelects[t]
+		if scope.CompanyID != "" {
+			if !isUUID(scope.CompanyID) {
+				continue
+			}
+			args = append(args, scope.CompanyID)
+			branch += " AND " + fmt.Sprintf(searchScopes[t].company, len(args))
+		}
+		if scope.CustomerID != "" {
+			if !isUUID(scope.CustomerID) {
+				continue
+			}
+			args = append(args, scope.CustomerID)
+			branch += " AND " + fmt.Sprintf(searchScopes[t].customer, len(args))
+		}
+		branches = append(branches, branch)
+	}
+	if len(branches) == 0 {
+		return results, nil
+	}
+
+	args = append(args, query.Limit)
+	sqlQuery := `WITH search AS (SELECT to_tsquery('simple', $1) AS query)` +
+		strings.Join(branches, "\n\t\tUNION ALL") +
+		fmt.Sprintf("\n\t\tORDER BY rank DESC, label, id LIMIT $%d", len(args))
+
+	rows, err := r.db.GetDb().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res model.SearchResult
+		var companyID, companyName, siteID, siteName, unitID, unitName sql.NullString
+		if err := rows.Scan(&res.Type, &res.ID, &res.Label, &res.Rank,
+			&companyID, &companyName, &siteID, &siteName, &unitID, &unitName); err != nil {
+			return nil, err
+		}
+		res.Path = make([]model.SearchPathItem, 0, 3)
+		if companyID.Valid {
+			res.Path = append(res.Path, model.SearchPathItem{Type: "company", ID: companyID.String, Label: companyName.String})
+		}
+		if siteID.Valid {
+			res.Path = append(res.Path, model.SearchPathItem{Type: "site", ID: siteID.String, Label: siteName.String})
+		}
+		if unitID.Valid {
+			res.Path = append(res.Path, model.SearchPathItem{Type: "unit", ID: unitID.String, Label: unitName.String})
+		}
+		results = append(results, &res)
+	}
+	return results, rows.Err()
+}
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// prefixQuery turns free text into a tsquery where every word must match the start of a
+// word, "apart 101" becomes "apart:* & 101:*". Anything but letters and digits is dropped,
+// so the text can never be read as tsquery syntax.
+func prefixQuery(text string) string {
+	words := searchWord.FindAllString(strings.ToLower(text), -1)
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,39 @@
+package psql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAcrossTheHierarchy(t *testing.T) {
+	conn := &emptyConn{}
+	repo := NewSearchRepository(newEmptyDb(t, conn))
+
+	scopes := make(map[model.SearchResultType]repository.TenantScope)
+	for _, typ := range model.SearchResultTypes {
+		scopes[typ] = repository.TenantScope{}
+	}
+	results, err := repo.Search(context.Background(), repository.SearchQuery{Text: "Riverside 2A", Scopes: scopes, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	require.Len(t, conn.queries, 1)
+	branches := strings.Split(conn.queries[0], "UNION ALL")
+	require.Len(t, branches, len(model.SearchResultTypes))
+
+	// "riverside:* & 2a:*" has to find flat 2A of the Riverside site, and its rooms
+	unit, room := branches[1], branches[2]
+	assert.Contains(t, unit, "to_tsvector('simple', s.name)", "Unit matched with its site")
+	assert.Contains(t, room, "to_tsvector('simple', u.name)", "Room matched with its unit")
+	assert.Contains(t, room, "to_tsvector('simple', s.name)", "Room matched with its site")
+
+	for i, branch := range branches {
+		assert.Contains(t, branch, "c.deleted_at IS NULL", model.SearchResultTypes[i])
+	}
+}
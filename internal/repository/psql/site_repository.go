@@ -3,10 +3,13 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
 type siteRepository struct {
@@ -21,113 +24,77 @@ func (r *siteRepository) WithTx(tx db.Db) repository.SiteRepository {
 	return &siteRepository{db: tx}
 }
 
+// insertSiteQuery only inserts while the company is out of the trash, a trashed company is
+// reported like a missing one.
+const insertSiteQuery = `
+	INSERT INTO construction_sites (id, name, address, company_id, created_at, updated_at)
+	SELECT $1, $2, $3, c.id, $5, $6
+	FROM companies c
+	WHERE c.id = $4 AND c.deleted_at IS NULL
+	RETURNING version
+`
+
 func (r *siteRepository) Create(ctx context.Context, site *model.Site) error {
-	query := `
-		INSERT INTO construction_sites (id, name, address, company_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err := r.db.GetDb().ExecContext(ctx, query, site.ID, site.Name, site.Address, site.CompanyID, site.CreatedAt, site.UpdatedAt)
-	return err
+	err := r.db.GetDb().QueryRowContext(ctx, insertSiteQuery, site.ID, site.Name, site.Address, site.CompanyID, site.CreatedAt, site.UpdatedAt).Scan(&site.Version)
+	if err == sql.ErrNoRows {
+		return &pkgRepository.ReferenceError{Field: "company_id"}
+	}
+	return referenceError(err)
 }
 
-func (r *siteRepository) FindAll(ctx context.Context, limit, offset int) ([]*model.Site, int64, error) {
-	var total int64
-	countQuery := `SELECT count(*) FROM construction_sites`
-	err := r.db.GetDb().QueryRowContext(ctx, countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, err
-	}
+var siteListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":         "s.id",
+		"name":       "s.name",
+		"address":    "s.address",
+		"company_id": "s.company_id",
+		"created_at": "s.created_at",
+		"updated_at": "s.updated_at",
+	},
+	Search:      []string{"s.name", "s.address"},
+	DefaultSort: "s.created_at DESC",
+	Tiebreaker:  "s.id",
+}
 
-	query := `
-		SELECT id, name, address, company_id, created_at, updated_at
-		FROM construction_sites
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.GetDb().QueryContext(ctx, query, limit, offset)
-	if err != nil {
-		return nil, 0, err
+func (r *siteRepository) FindAll(ctx context.Context, query listquery.Query, scope repository.TenantScope) ([]*model.Site, int64, error) {
+	from := ` FROM construction_sites s WHERE s.deleted_at IS NULL`
+	args := []interface{}{}
+	if scope.CompanyID != "" {
+		args = append(args, scope.CompanyID)
+		from += fmt.Sprintf(" AND s.company_id = $%d", len(args))
 	}
-	defer rows.Close()
-
-	// Initialize as empty slice to return [] instead of null on empty
-	sites := make([]*model.Site, 0)
-	for rows.Next() {
-		var s model.Site
-		if err := rows.Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt); err != nil {
-			return nil, 0, err
-		}
-		sites = append(sites, &s)
+	if scope.CustomerID != "" {
+		args = append(args, scope.CustomerID)
+		from += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM units u WHERE u.site_id = s.id AND u.client_id = $%d AND u.deleted_at IS NULL)", len(args))
 	}
-
-	return sites, total, nil
-}
-
-func (r *siteRepository) FindAllByCompanyID(ctx context.Context, limit, offset int, companyID string) ([]*model.Site, int64, error) {
-	var total int64
-	countQuery := `SELECT count(*) FROM construction_sites WHERE company_id = $1`
-	err := r.db.GetDb().QueryRowContext(ctx, countQuery, companyID).Scan(&total)
+	filter, args, err := query.Where(siteListFields, args)
 	if err != nil {
 		return nil, 0, err
 	}
-
-	query := `
-		SELECT id, name, address, company_id, created_at, updated_at
-		FROM construction_sites
-		WHERE company_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.db.GetDb().QueryContext(ctx, query, companyID, limit, offset)
+	from += filter
+	orderBy, err := query.OrderBy(siteListFields)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
 
-	var sites []*model.Site
-	for rows.Next() {
-		var s model.Site
-		if err := rows.Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt); err != nil {
-			return nil, 0, err
-		}
-		sites = append(sites, &s)
-	}
-
-	return sites, total, nil
-}
-
-func (r *siteRepository) FindAllByCustomerID(ctx context.Context, limit, offset int, customerID string) ([]*model.Site, int64, error) {
-	// Count distinct sites connected to units assigned to this customer
-	countQuery := `
-		SELECT count(DISTINCT s.id)
-		FROM construction_sites s
-		JOIN units u ON u.site_id = s.id
-		WHERE u.client_id = $1
-	`
 	var total int64
-	err := r.db.GetDb().QueryRowContext(ctx, countQuery, customerID).Scan(&total)
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `
-		SELECT DISTINCT s.id, s.name, s.address, s.company_id, s.created_at, s.updated_at
-		FROM construction_sites s
-		JOIN units u ON u.site_id = s.id
-		WHERE u.client_id = $1
-		ORDER BY s.created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.db.GetDb().QueryContext(ctx, query, customerID, limit, offset)
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT s.id, s.name, s.address, s.company_id, s.created_at, s.updated_at, s.version`+from+orderBy+page, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var sites []*model.Site
+	// Initialize as empty slice to return [] instead of null on empty
+	sites := make([]*model.Site, 0)
 	for rows.Next() {
 		var s model.Site
-		if err := rows.Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt, &s.Version); err != nil {
 			return nil, 0, err
 		}
 		sites = append(sites, &s)
@@ -138,12 +105,12 @@ func (r *siteRepository) FindAllByCustomerID(ctx context.Context, limit, offset
 
 func (r *siteRepository) FindByID(ctx context.Context, id string) (*model.Site, error) {
 	query := `
-		SELECT id, name, address, company_id, created_at, updated_at
+		SELECT id, name, address, company_id, created_at, updated_at, version
 		FROM construction_sites
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	var s model.Site
-	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt)
+	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&s.ID, &s.Name, &s.Address, &s.CompanyID, &s.CreatedAt, &s.UpdatedAt, &s.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -156,15 +123,20 @@ func (r *siteRepository) FindByID(ctx context.Context, id string) (*model.Site,
 func (r *siteRepository) Update(ctx context.Context, site *model.Site) error {
 	query := `
 		UPDATE construction_sites
-		SET name = $1, address = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, address = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
 	`
-	_, err := r.db.GetDb().ExecContext(ctx, query, site.Name, site.Address, site.UpdatedAt, site.ID)
-	return err
+	updated, err := execConditional(ctx, r.db.GetDb(), query, site.Name, site.Address, site.UpdatedAt, site.ID, site.Version)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return pkgRepository.ErrVersionConflict
+	}
+	site.Version++
+	return nil
 }
 
-func (r *siteRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM construction_sites WHERE id = $1`
-	_, err := r.db.GetDb().ExecContext(ctx, query, id)
-	return err
+func (r *siteRepository) Delete(ctx context.Context, id string, version int64) error {
+	return softDelete(ctx, r.db.GetDb(), model.TrashItemSite, id, version)
 }
@@ -0,0 +1,26 @@
+package psql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteCreateUnderTrashedCompany(t *testing.T) {
+	conn := &emptyConn{}
+	repo := NewSiteRepository(newEmptyDb(t, conn))
+
+	site := &model.Site{ID: "site-1", Name: "Riverside", CompanyID: "company-1", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err := repo.Create(context.Background(), site)
+
+	var refErr *pkgRepository.ReferenceError
+	require.ErrorAs(t, err, &refErr)
+	assert.Equal(t, "company_id", refErr.Field)
+	require.Len(t, conn.queries, 1)
+	assert.Contains(t, conn.queries[0], "c.deleted_at IS NULL")
+}
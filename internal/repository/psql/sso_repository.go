@@ -0,0 +1,75 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+type ssoRepository struct {
+	db db.Db
+}
+
+func NewSSORepository(db db.Db) repository.SSORepository {
+	return &ssoRepository{db: db}
+}
+
+func (r *ssoRepository) WithTx(tx db.Db) repository.SSORepository {
+	return &ssoRepository{db: tx}
+}
+
+func (r *ssoRepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	// Logins abandoned at the identity provider are never consumed, sweep them on the way
+	if _, err := r.db.GetDb().ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO oidc_login_states (state_hash, company_id, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.GetDb().ExecContext(ctx, query,
+		state.StateHash, state.CompanyID, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt,
+	)
+	return err
+}
+
+func (r *ssoRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	query := `
+		DELETE FROM oidc_login_states WHERE state_hash = $1
+		RETURNING state_hash, company_id, nonce, code_verifier, expires_at, created_at
+	`
+	var s model.OIDCLoginState
+	err := r.db.GetDb().QueryRowContext(ctx, query, stateHash).Scan(
+		&s.StateHash, &s.CompanyID, &s.Nonce, &s.CodeVerifier, &s.ExpiresAt, &s.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ssoRepository) FindIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	query := `SELECT issuer, subject, user_id, created_at FROM user_identities WHERE issuer = $1 AND subject = $2`
+	var i model.UserIdentity
+	err := r.db.GetDb().QueryRowContext(ctx, query, issuer, subject).Scan(&i.Issuer, &i.Subject, &i.UserID, &i.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *ssoRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	query := `INSERT INTO user_identities (issuer, subject, user_id, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.GetDb().ExecContext(ctx, query, identity.Issuer, identity.Subject, identity.UserID, identity.CreatedAt)
+	return err
+}
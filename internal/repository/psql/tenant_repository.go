@@ -0,0 +1,76 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+type tenantRepository struct {
+	db db.Db
+}
+
+func NewTenantRepository(db db.Db) repository.TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) SiteOwnership(ctx context.Context, siteID string) (*repository.Ownership, error) {
+	query := `SELECT company_id, NULL::uuid FROM construction_sites WHERE id = $1 AND deleted_at IS NULL`
+	return r.ownership(ctx, query, siteID)
+}
+
+func (r *tenantRepository) UnitOwnership(ctx context.Context, unitID string) (*repository.Ownership, error) {
+	query := `
+		SELECT s.company_id, u.client_id
+		FROM units u
+		JOIN construction_sites s ON s.id = u.site_id AND s.deleted_at IS NULL
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+	`
+	return r.ownership(ctx, query, unitID)
+}
+
+func (r *tenantRepository) RoomOwnership(ctx context.Context, roomID string) (*repository.Ownership, error) {
+	query := `
+		SELECT s.company_id, u.client_id
+		FROM rooms r
+		JOIN units u ON u.id = r.unit_id AND u.deleted_at IS NULL
+		JOIN construction_sites s ON s.id = u.site_id AND s.deleted_at IS NULL
+		WHERE r.id = $1 AND r.deleted_at IS NULL
+	`
+	return r.ownership(ctx, query, roomID)
+}
+
+func (r *tenantRepository) IsSiteCustomer(ctx context.Context, siteID, customerID string) (bool, error) {
+	if !isUUID(siteID) || !isUUID(customerID) {
+		return false, nil
+	}
+	query := `SELECT EXISTS (SELECT 1 FROM units WHERE site_id = $1 AND client_id = $2 AND deleted_at IS NULL)`
+	var exists bool
+	err := r.db.GetDb().QueryRowContext(ctx, query, siteID, customerID).Scan(&exists)
+	return exists, err
+}
+
+func (r *tenantRepository) ownership(ctx context.Context, query, id string) (*repository.Ownership, error) {
+	// The ids are UUID columns, anything else would make postgres fail the whole query
+	if !isUUID(id) {
+		return nil, nil
+	}
+
+	var o repository.Ownership
+	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&o.CompanyID, &o.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, err
+	}
+	return &o, nil
+}
+
+func isUUID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
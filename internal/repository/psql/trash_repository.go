@@ -0,0 +1,291 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+)
+
+// trashLevel is a table of the hierarchy deletes cascade down, parent being the column that
+// points to the level above.
+type trashLevel struct {
+	itemType model.TrashItemType
+	table    string
+	parent   string
+}
+
+var trashLevels = []trashLevel{
+	{model.TrashItemCompany, "companies", ""},
+	{model.TrashItemSite, "construction_sites", "company_id"},
+	{model.TrashItemUnit, "units", "site_id"},
+	{model.TrashItemRoom, "rooms", "unit_id"},
+}
+
+// softDeleteQuery moves the row with id $1 to the trash if it is still at version $2, with
+// everything below it that is not there yet, all under the same deleted_at. It returns how
+// many rows of the given type were deleted, 0 when the row was not found, changed or already
+// in the trash.
+func softDeleteQuery(itemType model.TrashItemType) string {
+	var query strings.Builder
+	levels := trashLevelsFrom(itemType)
+	for i, level := range levels {
+		if i == 0 {
+			fmt.Fprintf(&query, `WITH level0 AS (
+			UPDATE %s SET deleted_at = NOW() WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+			RETURNING id, deleted_at)`, level.table)
+			continue
+		}
+		fmt.Fprintf(&query, `, level%[1]d AS (
+			UPDATE %[2]s t SET deleted_at = above.deleted_at FROM level%[3]d above
+			WHERE t.%[4]s = above.id AND t.deleted_at IS NULL
+			RETURNING t.id, t.deleted_at)`, i, level.table, i-1, level.parent)
+	}
+	query.WriteString(` SELECT count(*) FROM level0`)
+	return query.String()
+}
+
+// restoreQuery is the reverse of softDeleteQuery: it takes the row with id $1 out of the
+// trash, with what below it has the same deleted_at.
+func restoreQuery(itemType model.TrashItemType) string {
+	var query strings.Builder
+	levels := trashLevelsFrom(itemType)
+	fmt.Fprintf(&query, `WITH root AS (
+			SELECT id, deleted_at FROM %s WHERE id = $1 AND deleted_at IS NOT NULL)`, levels[0].table)
+	for i, level := range levels {
+		if i == 0 {
+			fmt.Fprintf(&query, `, level0 AS (
+			UPDATE %s t SET deleted_at = NULL FROM root WHERE t.id = root.id
+			RETURNING t.id)`, level.table)
+			continue
+		}
+		fmt.Fprintf(&query, `, level%[1]d AS (
+			UPDATE %[2]s t SET deleted_at = NULL FROM level%[3]d above, root
+			WHERE t.%[4]s = above.id AND t.deleted_at = root.deleted_at
+			RETURNING t.id)`, i, level.table, i-1, level.parent)
+	}
+	query.WriteString(` SELECT count(*) FROM level0`)
+	return query.String()
+}
+
+func trashLevelsFrom(itemType model.TrashItemType) []trashLevel {
+	for i, level := range trashLevels {
+		if level.itemType == itemType {
+			return trashLevels[i:]
+		}
+	}
+	panic("unknown trash item type " + string(itemType))
+}
+
+// softDelete runs softDeleteQuery, ErrVersionConflict when the row is no longer at version
+// or was deleted in between.
+func softDelete(ctx context.Context, conn db.DbTx, itemType model.TrashItemType, id string, version int64) error {
+	if !isUUID(id) {
+		return nil
+	}
+	var deleted int64
+	if err := conn.QueryRowContext(ctx, softDeleteQuery(itemType), id, version).Scan(&deleted); err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pkgRepository.ErrVersionConflict
+	}
+	return nil
+}
+
+type trashRepository struct {
+	db db.Db
+}
+
+func NewTrashRepository(db db.Db) repository.TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// Every branch of the trash selects the item with its company, its parent and whether the
+// parent is alive. ownCondition keeps what was deleted on its own, not along with the parent.
+var trashSelects = map[model.TrashItemType]struct{ query, idColumn, ownCondition string }{
+	model.TrashItemCompany: {`
+		SELECT 'company' AS type, c.id::text AS id, c.name AS name, c.id::text AS company_id,
+			NULL::text AS parent_id, c.deleted_at AS deleted_at, TRUE AS restorable
+		FROM companies c
+		WHERE c.deleted_at IS NOT NULL`,
+		"c.id",
+		"",
+	},
+	model.TrashItemSite: {`
+		SELECT 'site' AS type, s.id::text AS id, s.name AS name, s.company_id::text AS company_id,
+			s.company_id::text AS parent_id, s.deleted_at AS deleted_at, c.deleted_at IS NULL AS restorable
+		FROM construction_sites s
+		JOIN companies c ON c.id = s.company_id
+		WHERE s.deleted_at IS NOT NULL`,
+		"s.id",
+		" AND c.deleted_at IS DISTINCT FROM s.deleted_at",
+	},
+	model.TrashItemUnit: {`
+		SELECT 'unit' AS type, u.id::text AS id, u.name AS name, s.company_id::text AS company_id,
+			u.site_id::text AS parent_id, u.deleted_at AS deleted_at, s.deleted_at IS NULL AS restorable
+		FROM units u
+		JOIN construction_sites s ON s.id = u.site_id
+		WHERE u.deleted_at IS NOT NULL`,
+		"u.id",
+		" AND s.deleted_at IS DISTINCT FROM u.deleted_at",
+	},
+	model.TrashItemRoom: {`
+		SELECT 'room' AS type, r.id::text AS id, r.name AS name, s.company_id::text AS company_id,
+			r.unit_id::text AS parent_id, r.deleted_at AS deleted_at, u.deleted_at IS NULL AS restorable
+		FROM rooms r
+		JOIN units u ON u.id = r.unit_id
+		JOIN construction_sites s ON s.id = u.site_id
+		WHERE r.deleted_at IS NOT NULL`,
+		"r.id",
+		" AND u.deleted_at IS DISTINCT FROM r.deleted_at",
+	},
+}
+
+const trashItemColumns = `type, id, name, company_id, parent_id, deleted_at, restorable`
+
+var trashListFields = listquery.Fields{
+	Columns: map[string]string{
+		"type":       "t.type",
+		"id":         "t.id",
+		"name":       "t.name",
+		"company_id": "t.company_id",
+		"parent_id":  "t.parent_id",
+		"deleted_at": "t.deleted_at",
+		"restorable": "t.restorable",
+	},
+	Search:      []string{"t.name"},
+	DefaultSort: "t.deleted_at DESC",
+	Tiebreaker:  "t.id",
+}
+
+func (r *trashRepository) FindAll(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error) {
+	branches := make([]string, 0, len(trashLevels))
+	for _, level := range trashLevels {
+		branch := trashSelects[level.itemType]
+		branches = append(branches, branch.query+branch.ownCondition)
+	}
+	from := ` FROM (` + strings.Join(branches, "\n\t\tUNION ALL") + `) t WHERE 1=1`
+
+	filter, args, err := query.Where(trashListFields, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(trashListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT `+trashItemColumns+from+orderBy+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]*model.TrashItem, 0)
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
+
+func (r *trashRepository) FindByID(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error) {
+	branch, ok := trashSelects[itemType]
+	if !ok || !isUUID(id) {
+		return nil, nil
+	}
+	item, err := scanTrashItem(r.db.GetDb().QueryRowContext(ctx, branch.query+" AND "+branch.idColumn+" = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not in the trash
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *trashRepository) Restore(ctx context.Context, itemType model.TrashItemType, id string) error {
+	if !isUUID(id) {
+		return nil
+	}
+	var restored int64
+	return r.db.GetDb().QueryRowContext(ctx, restoreQuery(itemType), id).Scan(&restored)
+}
+
+func (r *trashRepository) Purge(ctx context.Context, before time.Time) (purged *repository.PurgedTrash, err error) {
+	tx, err := r.db.BegrinTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.db.Rollback(ctx, tx)
+		}
+	}()
+
+	// Whatever is deleted with a parent has its deleted_at, so it is never younger than the
+	// parent and goes in the same purge. Rooms come first to learn which files to remove.
+	rows, err := tx.QueryContext(ctx, `DELETE FROM rooms WHERE deleted_at < $1 RETURNING id`, before)
+	if err != nil {
+		return nil, err
+	}
+	purged = &repository.PurgedTrash{RoomIDs: make([]string, 0)}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		purged.RoomIDs = append(purged.RoomIDs, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if purged.Units, err = execCount(ctx, tx, `DELETE FROM units WHERE deleted_at < $1`, before); err != nil {
+		return nil, err
+	}
+	if purged.Sites, err = execCount(ctx, tx, `DELETE FROM construction_sites WHERE deleted_at < $1`, before); err != nil {
+		return nil, err
+	}
+	// Accounts are not part of the trash, a company keeps its row as long as it has any
+	query := `
+		DELETE FROM companies c
+		WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM users WHERE company_id = c.id)
+	`
+	if purged.Companies, err = execCount(ctx, tx, query, before); err != nil {
+		return nil, err
+	}
+
+	if err = r.db.Commit(ctx, tx); err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+func scanTrashItem(row rowScanner) (*model.TrashItem, error) {
+	var item model.TrashItem
+	if err := row.Scan(&item.Type, &item.ID, &item.Name, &item.CompanyID, &item.ParentID, &item.DeletedAt, &item.Restorable); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
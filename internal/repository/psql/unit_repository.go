@@ -3,10 +3,13 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
 type unitRepository struct {
@@ -21,13 +24,26 @@ func (r *unitRepository) WithTx(tx db.Db) repository.UnitRepository {
 	return &unitRepository{db: tx}
 }
 
+// insertUnitQuery only inserts while the site is out of the trash, a trashed site is reported
+// like a missing one.
+const insertUnitQuery = `
+	INSERT INTO units (id, name, type, site_id, client_id, created_at, updated_at)
+	SELECT $1, $2, $3, s.id, $5, $6, $7
+	FROM construction_sites s
+	WHERE s.id = $4 AND s.deleted_at IS NULL
+	RETURNING version
+`
+
 func (r *unitRepository) Create(ctx context.Context, unit *model.Unit) error {
-	query := `
-		INSERT INTO units (id, name, type, site_id, client_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-	_, err := r.db.GetDb().ExecContext(ctx, query, unit.ID, unit.Name, unit.Type, unit.SiteID, unit.ClientID, unit.CreatedAt, unit.UpdatedAt)
-	return err
+	return insertUnit(ctx, r.db.GetDb(), unit)
+}
+
+func insertUnit(ctx context.Context, conn db.DbTx, unit *model.Unit) error {
+	err := conn.QueryRowContext(ctx, insertUnitQuery, unit.ID, unit.Name, unit.Type, unit.SiteID, unit.ClientID, unit.CreatedAt, unit.UpdatedAt).Scan(&unit.Version)
+	if err == sql.ErrNoRows {
+		return &pkgRepository.ReferenceError{Field: "site_id"}
+	}
+	return referenceError(err)
 }
 
 func (r *unitRepository) BatchCreate(ctx context.Context, units []*model.Unit) error {
@@ -47,14 +63,9 @@ func (r *unitRepository) BatchCreate(ctx context.Context, units []*model.Unit) e
 		}
 	}()
 
-	query := `
-		INSERT INTO units (id, name, type, site_id, client_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
 	// DbTx interface doesn't supported PrepareContext, so we exec directly in loop
 	for _, unit := range units {
-		_, err = tx.ExecContext(ctx, query, unit.ID, unit.Name, unit.Type, unit.SiteID, unit.ClientID, unit.CreatedAt, unit.UpdatedAt)
-		if err != nil {
+		if err = insertUnit(ctx, tx, unit); err != nil {
 			return err
 		}
 	}
@@ -62,22 +73,50 @@ func (r *unitRepository) BatchCreate(ctx context.Context, units []*model.Unit) e
 	return r.db.Commit(ctx, tx)
 }
 
-func (r *unitRepository) FindAll(ctx context.Context, limit, offset int) ([]*model.Unit, int64, error) {
-	// TODO: Add filtering by SiteID if needed
+var unitListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":         "u.id",
+		"name":       "u.name",
+		"type":       "u.type",
+		"site_id":    "u.site_id",
+		"client_id":  "u.client_id",
+		"created_at": "u.created_at",
+		"updated_at": "u.updated_at",
+	},
+	Search:      []string{"u.name"},
+	DefaultSort: "u.created_at DESC",
+	Tiebreaker:  "u.id",
+}
+
+func (r *unitRepository) FindAll(ctx context.Context, query listquery.Query, scope repository.TenantScope) ([]*model.Unit, int64, error) {
+	from := ` FROM units u JOIN construction_sites s ON s.id = u.site_id WHERE u.deleted_at IS NULL`
+	args := []interface{}{}
+	if scope.CompanyID != "" {
+		args = append(args, scope.CompanyID)
+		from += fmt.Sprintf(" AND s.company_id = $%d", len(args))
+	}
+	if scope.CustomerID != "" {
+		args = append(args, scope.CustomerID)
+		from += fmt.Sprintf(" AND u.client_id = $%d", len(args))
+	}
+	filter, args, err := query.Where(unitListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(unitListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var total int64
-	countQuery := `SELECT count(*) FROM units`
-	err := r.db.GetDb().QueryRowContext(ctx, countQuery).Scan(&total)
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `
-		SELECT id, name, type, site_id, client_id, created_at, updated_at
-		FROM units
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.GetDb().QueryContext(ctx, query, limit, offset)
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT u.id, u.name, u.type, u.site_id, u.client_id, u.created_at, u.updated_at, u.version`+from+orderBy+page, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -86,7 +125,7 @@ func (r *unitRepository) FindAll(ctx context.Context, limit, offset int) ([]*mod
 	units := make([]*model.Unit, 0)
 	for rows.Next() {
 		var u model.Unit
-		if err := rows.Scan(&u.ID, &u.Name, &u.Type, &u.SiteID, &u.ClientID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Name, &u.Type, &u.SiteID, &u.ClientID, &u.CreatedAt, &u.UpdatedAt, &u.Version); err != nil {
 			return nil, 0, err
 		}
 		units = append(units, &u)
@@ -97,12 +136,12 @@ func (r *unitRepository) FindAll(ctx context.Context, limit, offset int) ([]*mod
 
 func (r *unitRepository) FindByID(ctx context.Context, id string) (*model.Unit, error) {
 	query := `
-		SELECT id, name, type, site_id, client_id, created_at, updated_at
+		SELECT id, name, type, site_id, client_id, created_at, updated_at, version
 		FROM units
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	var u model.Unit
-	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Name, &u.Type, &u.SiteID, &u.ClientID, &u.CreatedAt, &u.UpdatedAt)
+	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Name, &u.Type, &u.SiteID, &u.ClientID, &u.CreatedAt, &u.UpdatedAt, &u.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -115,15 +154,28 @@ func (r *unitRepository) FindByID(ctx context.Context, id string) (*model.Unit,
 func (r *unitRepository) Update(ctx context.Context, unit *model.Unit) error {
 	query := `
 		UPDATE units
-		SET name = $1, type = $2, site_id = $3, client_id = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, type = $2, site_id = $3, client_id = $4, updated_at = $5, version = version + 1
+		WHERE id = $6 AND version = $7 AND deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM construction_sites WHERE id = $3 AND deleted_at IS NULL)
 	`
-	_, err := r.db.GetDb().ExecContext(ctx, query, unit.Name, unit.Type, unit.SiteID, unit.ClientID, unit.UpdatedAt, unit.ID)
-	return err
+	updated, err := execConditional(ctx, r.db.GetDb(), query, unit.Name, unit.Type, unit.SiteID, unit.ClientID, unit.UpdatedAt, unit.ID, unit.Version)
+	if err != nil {
+		return referenceError(err)
+	}
+	if !updated {
+		alive, err := isAlive(ctx, r.db.GetDb(), "construction_sites", unit.SiteID)
+		if err != nil {
+			return err
+		}
+		if !alive {
+			return &pkgRepository.ReferenceError{Field: "site_id"}
+		}
+		return pkgRepository.ErrVersionConflict
+	}
+	unit.Version++
+	return nil
 }
 
-func (r *unitRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM units WHERE id = $1`
-	_, err := r.db.GetDb().ExecContext(ctx, query, id)
-	return err
+func (r *unitRepository) Delete(ctx context.Context, id string, version int64) error {
+	return softDelete(ctx, r.db.GetDb(), model.TrashItemUnit, id, version)
 }
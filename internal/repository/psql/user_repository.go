@@ -8,8 +8,8 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 	"github.com/hfleury/bk_globalshot/pkg/repository"
-	"github.com/lib/pq"
 )
 
 type PostgresUserRepository struct {
@@ -53,43 +53,66 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string)
 func (r *PostgresUserRepository) Create(ctx context.Context, user *model.User) error {
 	query := `
         INSERT INTO users (id, email, password, role, company_id)
-        VALUES ($1, $2, $3, $4, $5)`
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING version`
 
-	_, err := r.db.GetDb().ExecContext(ctx, query, user.ID, user.Email, user.Password, user.Role, user.CompanyID)
+	err := r.db.GetDb().QueryRowContext(ctx, query, user.ID, user.Email, user.Password, user.Role, nullableCompanyID(user.CompanyID)).Scan(&user.Version)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		if isUniqueViolation(err) {
 			return repository.ErrEmailAlreadyExists
 		}
-		return err
+		return referenceError(err)
 	}
 	return nil
 }
 
-func (r *PostgresUserRepository) FindAll(ctx context.Context, limit, offset int) ([]*model.User, int64, error) {
+var userListFields = listquery.Fields{
+	Columns: map[string]string{
+		"id":         "id",
+		"email":      "email",
+		"role":       "role",
+		"company_id": "company_id",
+	},
+	Search:      []string{"email"},
+	DefaultSort: "email ASC",
+	Tiebreaker:  "id",
+}
+
+func (r *PostgresUserRepository) FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.User, int64, error) {
+	from := ` FROM users WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		from += fmt.Sprintf(" AND company_id = $%d", len(args))
+	}
+	filter, args, err := query.Where(userListFields, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += filter
+	orderBy, err := query.OrderBy(userListFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var total int64
-	countQuery := `SELECT count(*) FROM users`
-	err := r.db.GetDb().QueryRowContext(ctx, countQuery).Scan(&total)
+	err = r.db.GetDb().QueryRowContext(ctx, `SELECT count(*)`+from, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
-	query := `
-		SELECT id, email, role, company_id
-		FROM users
-		ORDER BY email ASC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.GetDb().QueryContext(ctx, query, limit, offset)
+	page, args := query.Page(args)
+	rows, err := r.db.GetDb().QueryContext(ctx, `SELECT id, email, role, company_id, version`+from+orderBy+page, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []*model.User
+	users := make([]*model.User, 0)
 	for rows.Next() {
 		var u model.User
 		var companyID sql.NullString
-		if err := rows.Scan(&u.ID, &u.Email, &u.Role, &companyID); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.Role, &companyID, &u.Version); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
 		}
 		u.CompanyID = companyID.String
@@ -101,13 +124,13 @@ func (r *PostgresUserRepository) FindAll(ctx context.Context, limit, offset int)
 
 func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	query := `
-		SELECT id, email, role, company_id
+		SELECT id, email, password, role, company_id, version
 		FROM users
 		WHERE id = $1
 	`
 	var u model.User
 	var companyID sql.NullString
-	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Email, &u.Role, &companyID)
+	err := r.db.GetDb().QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Email, &u.Password, &u.Role, &companyID, &u.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -121,23 +144,59 @@ func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*mode
 func (r *PostgresUserRepository) Update(ctx context.Context, user *model.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, role = $2, company_id = $3
-		WHERE id = $4
+		SET email = $1, role = $2, company_id = $3, version = version + 1
+		WHERE id = $4 AND version = $5
 	`
 	// Note: Password update is usually handled separately for security, skipping for basic CRUD update
 	// or handled if provided. For now, assuming basic details update.
-	_, err := r.db.GetDb().ExecContext(ctx, query, user.Email, user.Role, user.CompanyID, user.ID)
+	updated, err := execConditional(ctx, r.db.GetDb(), query, user.Email, user.Role, nullableCompanyID(user.CompanyID), user.ID, user.Version)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		if isUniqueViolation(err) {
+			return repository.ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to update user: %w", referenceError(err))
+	}
+	if !updated {
+		return repository.ErrVersionConflict
 	}
+	user.Version++
 	return nil
 }
 
-func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.GetDb().ExecContext(ctx, query, id)
+func (r *PostgresUserRepository) UpdateEmail(ctx context.Context, id, email string) error {
+	query := `UPDATE users SET email = $1, updated_at = NOW(), version = version + 1 WHERE id = $2`
+	_, err := r.db.GetDb().ExecContext(ctx, query, email, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	query := `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.GetDb().ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id string, version int64) error {
+	query := `DELETE FROM users WHERE id = $1 AND version = $2`
+	deleted, err := execConditional(ctx, r.db.GetDb(), query, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	if !deleted {
+		return repository.ErrVersionConflict
+	}
 	return nil
 }
+
+// nullableCompanyID stores users without a company, admins and invited customers, as NULL.
+func nullableCompanyID(companyID string) sql.NullString {
+	return sql.NullString{String: companyID, Valid: companyID != ""}
+}
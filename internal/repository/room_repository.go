@@ -4,13 +4,17 @@ import (
 	"context"
 
 	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
+//go:generate mockgen -source=room_repository.go -destination=../../mock/repository/mock_room_repository.go -package=mock_repository
 type RoomRepository interface {
 	Create(ctx context.Context, room *model.Room) error
-	FindAll(ctx context.Context, limit, offset int, unitID string) ([]*model.Room, int64, error)
+	FindAll(ctx context.Context, query listquery.Query, scope TenantScope) ([]*model.Room, int64, error)
 	FindByID(ctx context.Context, id string) (*model.Room, error)
+	// Update is versioned like UnitRepository.Update.
 	Update(ctx context.Context, room *model.Room) error
-	Delete(ctx context.Context, id string) error
-	// Additional filters can be added to FindAll later
+	// Delete moves the room to the trash, its media stay hidden until it is restored. It is
+	// versioned like UnitRepository.Delete.
+	Delete(ctx context.Context, id string, version int64) error
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+// SearchQuery is a full-text search over the resources the caller may read. Only the types
+// in Scopes are searched, each narrowed down by its own scope.
+type SearchQuery struct {
+	Text   string
+	Scopes map[model.SearchResultType]TenantScope
+	Limit  int
+}
+
+//go:generate mockgen -source=search_repository.go -destination=../../mock/repository/mock_search_repository.go -package=mock_repository
+type SearchRepository interface {
+	// Search returns the best matches first. Words match by prefix, so partly typed words
+	// find results too.
+	Search(ctx context.Context, query SearchQuery) ([]*model.SearchResult, error)
+}
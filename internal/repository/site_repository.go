@@ -5,15 +5,18 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 type SiteRepository interface {
 	Create(ctx context.Context, site *model.Site) error
-	FindAll(ctx context.Context, limit, offset int) ([]*model.Site, int64, error)
-	FindAllByCompanyID(ctx context.Context, limit, offset int, companyID string) ([]*model.Site, int64, error)
-	FindAllByCustomerID(ctx context.Context, limit, offset int, customerID string) ([]*model.Site, int64, error)
+	// FindAll lists the sites within scope, a customer's being those with one of their units.
+	FindAll(ctx context.Context, query listquery.Query, scope TenantScope) ([]*model.Site, int64, error)
 	FindByID(ctx context.Context, id string) (*model.Site, error)
+	// Update saves the site and bumps its version, ErrVersionConflict when it is no longer
+	// at site.Version.
 	Update(ctx context.Context, site *model.Site) error
-	Delete(ctx context.Context, id string) error
+	// Delete moves the site to the trash with its units and rooms, versioned like Update.
+	Delete(ctx context.Context, id string, version int64) error
 	WithTx(tx db.Db) SiteRepository
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+//go:generate mockgen -source=sso_repository.go -destination=../../mock/repository/mock_sso_repository.go -package=mock_repository
+type SSORepository interface {
+	CreateState(ctx context.Context, state *model.OIDCLoginState) error
+	// ConsumeState removes and returns a login in flight, so a state is only ever used once.
+	ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error)
+	FindIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	WithTx(tx db.Db) SSORepository
+}
@@ -0,0 +1,27 @@
+package repository
+
+import "context"
+
+// Ownership tells which tenant a resource belongs to. CompanyID is the company of the
+// construction site the resource sits in, ClientID the customer its unit is assigned to.
+type Ownership struct {
+	CompanyID string
+	ClientID  *string
+}
+
+// TenantScope narrows a listing down to what a caller may see. An empty field does not filter.
+type TenantScope struct {
+	CompanyID  string
+	CustomerID string
+}
+
+//go:generate mockgen -source=tenant_repository.go -destination=../../mock/repository/mock_tenant_repository.go -package=mock_repository
+type TenantRepository interface {
+	// The ownership lookups return nil when the resource does not exist, or it or one of its
+	// parents is in the trash. Nothing can be added under a trashed resource.
+	SiteOwnership(ctx context.Context, siteID string) (*Ownership, error)
+	UnitOwnership(ctx context.Context, unitID string) (*Ownership, error)
+	RoomOwnership(ctx context.Context, roomID string) (*Ownership, error)
+	// IsSiteCustomer reports whether the customer has a unit on the site, out of the trash.
+	IsSiteCustomer(ctx context.Context, siteID, customerID string) (bool, error)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// PurgedTrash is what a purge deleted for good. The files of the rooms have to be removed
+// from storage too.
+type PurgedTrash struct {
+	RoomIDs   []string
+	Units     int64
+	Sites     int64
+	Companies int64
+}
+
+//go:generate mockgen -source=trash_repository.go -destination=../../mock/repository/mock_trash_repository.go -package=mock_repository
+type TrashRepository interface {
+	// FindAll lists what was deleted on its own rather than along with its parent, most
+	// recently deleted first.
+	FindAll(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error)
+	// FindByID returns nil unless the resource is in the trash.
+	FindByID(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error)
+	// Restore brings the resource back along with what was deleted with it. What was
+	// deleted inside it before stays in the trash.
+	Restore(ctx context.Context, itemType model.TrashItemType, id string) error
+	// Purge deletes for good what went to the trash before the given time. Companies that
+	// still have users are kept.
+	Purge(ctx context.Context, before time.Time) (*PurgedTrash, error)
+}
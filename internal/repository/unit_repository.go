@@ -5,14 +5,20 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
+//go:generate mockgen -source=unit_repository.go -destination=../../mock/repository/mock_unit_repository.go -package=mock_repository
 type UnitRepository interface {
 	Create(ctx context.Context, unit *model.Unit) error
 	BatchCreate(ctx context.Context, units []*model.Unit) error
-	FindAll(ctx context.Context, limit, offset int) ([]*model.Unit, int64, error)
+	FindAll(ctx context.Context, query listquery.Query, scope TenantScope) ([]*model.Unit, int64, error)
 	FindByID(ctx context.Context, id string) (*model.Unit, error)
+	// Update saves the unit if it is still at unit.Version and bumps the version,
+	// ErrVersionConflict when someone else updated it in between.
 	Update(ctx context.Context, unit *model.Unit) error
-	Delete(ctx context.Context, id string) error
+	// Delete moves the unit to the trash with its rooms if it is still at version,
+	// ErrVersionConflict otherwise.
+	Delete(ctx context.Context, id string, version int64) error
 	WithTx(tx db.Db) UnitRepository
 }
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type AdminRouter struct {
+	impersonationHandler *handler.ImpersonationHandler
+}
+
+func NewAdminRouter(impersonationHandler *handler.ImpersonationHandler) *AdminRouter {
+	return &AdminRouter{impersonationHandler: impersonationHandler}
+}
+
+func (r *AdminRouter) SetupAdminRouter(config *gin.RouterGroup) {
+	routes := config.Group("/admin", middleware.RequireSession(), middleware.DenyImpersonation(), middleware.RequirePermission(authz.UserImpersonate))
+	{
+		routes.POST("/impersonate/:userId", r.impersonationHandler.Impersonate)
+		routes.GET("/impersonations", r.impersonationHandler.GetAllImpersonations)
+		routes.DELETE("/impersonations/:id", r.impersonationHandler.EndImpersonation)
+	}
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type APIKeyRouter struct {
+	handler *handler.APIKeyHandler
+}
+
+func NewAPIKeyRouter(handler *handler.APIKeyHandler) *APIKeyRouter {
+	return &APIKeyRouter{handler: handler}
+}
+
+func (r *APIKeyRouter) SetupAPIKeyRouter(config *gin.RouterGroup) {
+	routes := config.Group("/api-keys", middleware.RequireSession(), middleware.DenyImpersonation())
+	{
+		routes.POST("", middleware.RequirePermission(authz.APIKeyCreate), r.handler.CreateAPIKey)
+		routes.GET("", middleware.RequirePermission(authz.APIKeyRead), r.handler.GetAllAPIKeys)
+		routes.DELETE("/:id", middleware.RequirePermission(authz.APIKeyDelete), r.handler.RevokeAPIKey)
+	}
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type AuditRouter struct {
+	handler *handler.AuditHandler
+}
+
+func NewAuditRouter(handler *handler.AuditHandler) *AuditRouter {
+	return &AuditRouter{handler: handler}
+}
+
+func (r *AuditRouter) SetupAuditRouter(config *gin.RouterGroup) {
+	config.GET("/audit", middleware.RequirePermission(authz.AuditRead), r.handler.GetAuditEvents)
+}
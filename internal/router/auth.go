@@ -3,23 +3,59 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/ratelimit"
 )
 
 type AuthRouter struct {
-	handler *handler.AuthHandler
+	handler           *handler.AuthHandler
+	invitationHandler *handler.InvitationHandler
+	ssoHandler        *handler.SSOHandler
+	limiter           ratelimit.Limiter
+	cfgRateLimit      *config.ConfigRateLimit
 }
 
-func NewAuthRouter(handler *handler.AuthHandler) *AuthRouter {
+func NewAuthRouter(
+	handler *handler.AuthHandler,
+	invitationHandler *handler.InvitationHandler,
+	ssoHandler *handler.SSOHandler,
+	limiter ratelimit.Limiter,
+	cfgRateLimit *config.ConfigRateLimit,
+) *AuthRouter {
 	return &AuthRouter{
-		handler: handler,
+		handler:           handler,
+		invitationHandler: invitationHandler,
+		ssoHandler:        ssoHandler,
+		limiter:           limiter,
+		cfgRateLimit:      cfgRateLimit,
 	}
 }
 
 func (ar *AuthRouter) SetupAuthRouter(api *gin.RouterGroup) {
 
+	// Everything that checks a secret is throttled per client, and per targeted email where
+	// there is one, so neither spreading addresses nor spreading accounts helps guessing
+	perIP := middleware.RateLimit(ar.limiter, ratelimit.PerMinute(ar.cfgRateLimit.IPPerMinute, ar.cfgRateLimit.IPBurst), middleware.ByClientIP("auth"))
+	perEmail := func(scope string) gin.HandlerFunc {
+		rule := ratelimit.PerMinute(ar.cfgRateLimit.EmailPerMinute, ar.cfgRateLimit.EmailBurst)
+		return middleware.RateLimit(ar.limiter, rule, middleware.ByJSONField(scope, "email"))
+	}
+
 	auth := api.Group("/auth")
 	{
-		auth.POST("/login", ar.handler.Login)
-		auth.POST("/reset-password", ar.handler.ResetPassword)
+		auth.POST("/login", perIP, perEmail("login"), ar.handler.Login)
+		auth.POST("/refresh", ar.handler.Refresh)
+		auth.POST("/logout", ar.handler.Logout)
+		auth.POST("/mfa/verify", perIP, ar.handler.VerifyMFA)
+		auth.POST("/mfa/enroll", perIP, ar.handler.EnrollMFA)
+		auth.POST("/reset-password", perIP, perEmail("reset-password"), ar.handler.ResetPassword)
+		auth.POST("/reset-password/confirm", ar.handler.ConfirmResetPassword)
+		// Invitees have no account yet, the invitation token is their credential
+		auth.POST("/invitations/accept", perIP, ar.invitationHandler.AcceptInvitation)
+		// Single sign-on, the identity provider sends the browser back to the frontend which
+		// posts the code here
+		auth.GET("/oidc/:companyId/authorize", perIP, ar.ssoHandler.BeginSSO)
+		auth.POST("/oidc/callback", perIP, ar.ssoHandler.CompleteSSO)
 	}
 }
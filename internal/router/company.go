@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 )
 
 type CompanyRouter struct {
@@ -18,10 +20,11 @@ func NewCompanyRouter(handler *handler.CompanyHandler) *CompanyRouter {
 func (cr *CompanyRouter) SetupCompanyRouter(api *gin.RouterGroup) {
 	companies := api.Group("/companies")
 	{
-		companies.POST("", cr.handler.CreateCompany)
-		companies.GET("", cr.handler.GetAllCompanies)
-		companies.GET("/:id", cr.handler.GetCompanyByID)
-		companies.PUT("/:id", cr.handler.UpdateCompany)
-		companies.DELETE("/:id", cr.handler.DeleteCompany)
+		companies.POST("", middleware.RequirePermission(authz.CompanyCreate), cr.handler.CreateCompany)
+		companies.GET("", middleware.RequirePermission(authz.CompanyRead), cr.handler.GetAllCompanies)
+		companies.GET("/:id", middleware.RequirePermission(authz.CompanyRead), cr.handler.GetCompanyByID)
+		companies.PUT("/:id", middleware.RequirePermission(authz.CompanyUpdate), cr.handler.UpdateCompany)
+		companies.PATCH("/:id", middleware.RequirePermission(authz.CompanyUpdate), cr.handler.PatchCompany)
+		companies.DELETE("/:id", middleware.RequirePermission(authz.CompanyDelete), cr.handler.DeleteCompany)
 	}
 }
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type InvitationRouter struct {
+	handler *handler.InvitationHandler
+}
+
+func NewInvitationRouter(handler *handler.InvitationHandler) *InvitationRouter {
+	return &InvitationRouter{handler: handler}
+}
+
+func (r *InvitationRouter) SetupInvitationRouter(config *gin.RouterGroup) {
+	routes := config.Group("/invitations")
+	{
+		routes.POST("", middleware.RequirePermission(authz.UserCreate), r.handler.CreateInvitation)
+		routes.GET("", middleware.RequirePermission(authz.UserRead), r.handler.GetAllInvitations)
+		routes.POST("/:id/resend", middleware.RequirePermission(authz.UserCreate), r.handler.ResendInvitation)
+		routes.DELETE("/:id", middleware.RequirePermission(authz.UserDelete), r.handler.RevokeInvitation)
+	}
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+)
+
+type MeRouter struct {
+	handler    *handler.MeHandler
+	mfaHandler *handler.MFAHandler
+}
+
+func NewMeRouter(handler *handler.MeHandler, mfaHandler *handler.MFAHandler) *MeRouter {
+	return &MeRouter{handler: handler, mfaHandler: mfaHandler}
+}
+
+func (r *MeRouter) SetupMeRouter(group *gin.RouterGroup) {
+	routes := group.Group("/me", middleware.RequireSession())
+	// The account and its credentials are the user's own, support only looks at them
+	owner := middleware.DenyImpersonation()
+	{
+		routes.GET("", r.handler.GetMe)
+		routes.PATCH("", owner, r.handler.UpdateMe)
+		routes.GET("/permissions", r.handler.GetPermissions)
+		routes.POST("/password", owner, r.handler.ChangePassword)
+		routes.GET("/sessions", r.handler.GetSessions)
+		routes.DELETE("/sessions", r.handler.DeleteSessions)
+		routes.DELETE("/sessions/:id", r.handler.DeleteSession)
+		routes.GET("/mfa", r.mfaHandler.GetStatus)
+		routes.POST("/mfa/enroll", owner, r.mfaHandler.BeginEnrollment)
+		routes.POST("/mfa/enroll/confirm", owner, r.mfaHandler.ConfirmEnrollment)
+		routes.POST("/mfa/recovery-codes", owner, r.mfaHandler.RegenerateRecoveryCodes)
+		routes.POST("/mfa/disable", owner, r.mfaHandler.Disable)
+	}
+}
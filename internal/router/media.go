@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type MediaRouter struct {
+	handler *handler.MediaHandler
+}
+
+func NewMediaRouter(handler *handler.MediaHandler) *MediaRouter {
+	return &MediaRouter{handler: handler}
+}
+
+func (r *MediaRouter) SetupMediaRouter(group *gin.RouterGroup) {
+	canRead := middleware.RequirePermission(authz.MediaRead)
+
+	router := group.Group("/media")
+	{
+		router.POST("/upload", middleware.RequirePermission(authz.MediaUpload), r.handler.UploadMedia)
+		router.GET("/:id", canRead, r.handler.GetMediaByID)
+		router.GET("/:id/file", canRead, r.handler.GetMediaFile)
+		router.GET("/:id/thumbnail", canRead, r.handler.GetMediaThumbnail)
+		router.GET("/:id/preview", canRead, r.handler.GetMediaPreview)
+		router.GET("/:id/tiles", canRead, r.handler.GetTileManifest)
+		router.GET("/:id/tiles/:level/:face/:y/:x", canRead, r.handler.GetMediaTile)
+		router.POST("/:id/regenerate", middleware.RequirePermission(authz.MediaRegenerate), r.handler.RegenerateRenditions)
+		router.DELETE("/:id", middleware.RequirePermission(authz.MediaDelete), r.handler.DeleteMedia)
+	}
+
+	// Capture history lives under the room it belongs to
+	group.GET("/rooms/:id/media", canRead, r.handler.GetRoomMedia)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/dto"
 	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 	"github.com/hfleury/bk_globalshot/pkg/token"
 )
 
@@ -16,9 +18,24 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	// apiKeyPrefix matches service.APIKeyPrefix, access tokens start with "v4.public."
+	apiKeyPrefix = "gsk_"
 )
 
-func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// SessionValidator reports whether the session an access token was issued for is still live.
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// APIKeyAuthenticator resolves an API key to the identity it acts as, nil when the key is
+// unknown, revoked or expired.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*token.Payload, error)
+}
+
+// AuthMiddleware accepts a PASETO access token of a live session, or an API key, as bearer
+// credentials. API keys are told apart by their prefix.
+func AuthMiddleware(tokenMaker token.Maker, sessions SessionValidator, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
@@ -42,19 +59,123 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.FromError(err))
+		var payload *token.Payload
+		if strings.HasPrefix(fields[1], apiKeyPrefix) {
+			payload = authenticateAPIKey(ctx, apiKeys, fields[1])
+		} else {
+			payload = authenticateAccessToken(ctx, tokenMaker, sessions, fields[1])
+		}
+		if payload == nil {
 			return
 		}
 
 		ctx.Set(authorizationPayloadKey, payload)
+		// Services authorize against the caller on the request context, not against gin
+		reqCtx := model.ContextWithClient(ctx.Request.Context(), model.ClientInfo{
+			IPAddress: ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		})
+		ctx.Request = ctx.Request.WithContext(model.ContextWithUser(reqCtx, &model.User{
+			ID:        payload.UserID,
+			Email:     payload.Email,
+			Role:      payload.Role,
+			CompanyID: payload.CompanyID,
+			Scopes:    payload.Scopes,
+			// The admin behind an impersonation token, if any
+			ImpersonatorID: payload.ImpersonatorID,
+		}))
+		ctx.Next()
+	}
+}
+
+func authenticateAccessToken(ctx *gin.Context, tokenMaker token.Maker, sessions SessionValidator, accessToken string) *token.Payload {
+	payload, err := tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.FromError(err))
+		return nil
+	}
+
+	// Signed tokens stay valid until they expire, the session is what lets us cut them off early
+	active, err := sessions.IsSessionActive(ctx.Request.Context(), payload.SessionID)
+	if err != nil {
+		ctx.Error(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return nil
+	}
+	if !active {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.UnauthorizedResponse("Session has been revoked or has expired"))
+		return nil
+	}
+	return payload
+}
+
+func authenticateAPIKey(ctx *gin.Context, apiKeys APIKeyAuthenticator, key string) *token.Payload {
+	if apiKeys == nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.UnauthorizedResponse("API keys are not accepted"))
+		return nil
+	}
+
+	payload, err := apiKeys.AuthenticateAPIKey(ctx.Request.Context(), key)
+	if err != nil {
+		ctx.Error(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.InternalServerErrorResponse())
+		return nil
+	}
+	if payload == nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.UnauthorizedResponse("API key is invalid, revoked or expired"))
+		return nil
+	}
+	return payload
+}
+
+// RequireSession keeps API keys out of routes meant for people, such as the caller's own
+// account or the management of API keys themselves.
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := GetAuthPayload(ctx)
+		if payload == nil || payload.APIKeyID != "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, dto.ForbiddenResponse("This endpoint requires a signed in user"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RestrictImpersonation keeps admins impersonating a user to reading, support looks around
+// with the user's eyes but does not change anything on their behalf.
+func RestrictImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := GetAuthPayload(ctx)
+		if payload != nil && payload.ImpersonatorID != "" && !readOnlyMethods[ctx.Request.Method] {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, dto.ForbiddenResponse("This action is not allowed while impersonating"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+var readOnlyMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// DenyImpersonation refuses a route to impersonation tokens altogether, for what only the
+// user may do, such as changing their credentials.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := GetAuthPayload(ctx)
+		if payload != nil && payload.ImpersonatorID != "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, dto.ForbiddenResponse("This action is not allowed while impersonating"))
+			return
+		}
 		ctx.Next()
 	}
 }
 
-func RequireRoles(roles ...model.Role) gin.HandlerFunc {
+// RequirePermission rejects callers whose role holds none of the permissions at any scope.
+// Whether the permission reaches the resource at hand is left to the services.
+func RequirePermission(perms ...authz.Permission) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		payload, exists := ctx.Get(authorizationPayloadKey)
 		if !exists {
@@ -68,8 +189,14 @@ func RequireRoles(roles ...model.Role) gin.HandlerFunc {
 			return
 		}
 
-		for _, role := range roles {
-			if model.Role(tokenPayload.Role) == role {
+		sub := authz.SubjectFromUser(&model.User{
+			ID:        tokenPayload.UserID,
+			Role:      tokenPayload.Role,
+			CompanyID: tokenPayload.CompanyID,
+			Scopes:    tokenPayload.Scopes,
+		})
+		for _, perm := range perms {
+			if authz.Default.Can(sub, perm) {
 				ctx.Next()
 				return
 			}
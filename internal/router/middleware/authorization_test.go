@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	paseto "aidanwoods.dev/go-paseto"
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSessions map[string]bool
+
+func (f fakeSessions) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	return f[sessionID], nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	maker, err := token.NewPasetoMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	sessions := fakeSessions{"active-session": true, "revoked-session": false}
+	newToken := func(sessionID string) string {
+		signed, err := maker.CreateToken(&token.Payload{UserID: "user-123", Email: "a@b.c", Role: "admin", SessionID: sessionID}, time.Minute)
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{"SUCCESS - Active session", "Bearer " + newToken("active-session"), http.StatusOK},
+		{"FAIL - Revoked session", "Bearer " + newToken("revoked-session"), http.StatusUnauthorized},
+		{"FAIL - Token without session", "Bearer " + newToken(""), http.StatusUnauthorized},
+		{"FAIL - Missing header", "", http.StatusUnauthorized},
+		{"FAIL - Garbage token", "Bearer not-a-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/protected", AuthMiddleware(maker, sessions, nil), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+type fakeAPIKeys map[string]*token.Payload
+
+func (f fakeAPIKeys) AuthenticateAPIKey(ctx context.Context, key string) (*token.Payload, error) {
+	return f[key], nil
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	maker, err := token.NewPasetoMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	apiKeys := fakeAPIKeys{
+		"gsk_0123456789ab_secret": {
+			UserID:    "user-123",
+			Role:      "company",
+			CompanyID: "company-123",
+			APIKeyID:  "key-1",
+			Scopes:    []string{string(authz.MediaUpload)},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		route          []gin.HandlerFunc
+		expectedStatus int
+	}{
+		{"SUCCESS - Key within its scopes", "Bearer gsk_0123456789ab_secret", []gin.HandlerFunc{RequirePermission(authz.MediaUpload)}, http.StatusOK},
+		{"FAIL - Permission of the role outside the key's scopes", "Bearer gsk_0123456789ab_secret", []gin.HandlerFunc{RequirePermission(authz.SiteDelete)}, http.StatusForbidden},
+		{"FAIL - Key on a route for people", "Bearer gsk_0123456789ab_secret", []gin.HandlerFunc{RequireSession()}, http.StatusForbidden},
+		{"FAIL - Unknown key", "Bearer gsk_0123456789ab_guessed", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			handlers := append([]gin.HandlerFunc{AuthMiddleware(maker, fakeSessions{}, apiKeys)}, tt.route...)
+			handlers = append(handlers, func(c *gin.Context) {
+				user := model.UserFromContext(c.Request.Context())
+				assert.Equal(t, "company-123", user.CompanyID)
+				assert.Equal(t, []string{string(authz.MediaUpload)}, user.Scopes)
+				c.Status(http.StatusOK)
+			})
+			r.GET("/protected", handlers...)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", tt.header)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestImpersonationRestrictions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	maker, err := token.NewPasetoMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	signed, err := maker.CreateToken(&token.Payload{
+		UserID:          "customer-1",
+		Role:            "customer",
+		SessionID:       "admin-session",
+		ImpersonatorID:  "admin-1",
+		ImpersonationID: "impersonation-1",
+	}, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		method         string
+		route          []gin.HandlerFunc
+		expectedStatus int
+	}{
+		{"SUCCESS - Reading as the user", http.MethodGet, nil, http.StatusOK},
+		{"SUCCESS - Checking a resource as the user", http.MethodHead, nil, http.StatusOK},
+		{"FAIL - Creating as the user", http.MethodPost, nil, http.StatusForbidden},
+		{"FAIL - Updating as the user", http.MethodPut, nil, http.StatusForbidden},
+		{"FAIL - Patching as the user", http.MethodPatch, nil, http.StatusForbidden},
+		{"FAIL - Deleting as the user", http.MethodDelete, nil, http.StatusForbidden},
+		{"FAIL - Route reserved to the user", http.MethodGet, []gin.HandlerFunc{DenyImpersonation()}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			handlers := append([]gin.HandlerFunc{AuthMiddleware(maker, fakeSessions{"admin-session": true}, nil), RestrictImpersonation()}, tt.route...)
+			handlers = append(handlers, func(c *gin.Context) {
+				user := model.UserFromContext(c.Request.Context())
+				assert.Equal(t, "customer-1", user.ID)
+				assert.Equal(t, "admin-1", user.ImpersonatorID)
+				c.Status(http.StatusOK)
+			})
+			r.Handle(tt.method, "/protected", handlers...)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/dto"
+	"github.com/hfleury/bk_globalshot/pkg/ratelimit"
+)
+
+// maxPeekBody caps how much of a request body is read to find the rate limit key.
+const maxPeekBody = 64 << 10
+
+// RateLimitKey names the bucket a request draws from, an empty key is not limited.
+type RateLimitKey func(c *gin.Context) string
+
+// ByClientIP limits each client address, scope keeps endpoints apart.
+func ByClientIP(scope string) RateLimitKey {
+	return func(c *gin.Context) string {
+		return scope + ":ip:" + c.ClientIP()
+	}
+}
+
+// ByJSONField limits each value of a field of the JSON body, such as the email a login targets.
+// The body is left in place for the handler.
+func ByJSONField(scope, field string) RateLimitKey {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBody))
+		if err != nil {
+			return ""
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		value, ok := fields[field].(string)
+		if !ok || strings.TrimSpace(value) == "" {
+			return ""
+		}
+		return scope + ":" + field + ":" + strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+// RateLimit answers 429 with Retry-After once the bucket of the request is empty. A limiter
+// that fails lets the request through, an outage of the store must not lock everybody out.
+func RateLimit(limiter ratelimit.Limiter, rule ratelimit.Rule, key RateLimitKey) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		k := key(ctx)
+		if k == "" {
+			ctx.Next()
+			return
+		}
+
+		decision, err := limiter.Allow(ctx.Request.Context(), k, rule)
+		if err != nil {
+			log.Printf("rate limiter failed for %s: %v", k, err)
+			ctx.Next()
+			return
+		}
+		if !decision.Allowed {
+			AbortTooManyRequests(ctx, decision.RetryAfter, "")
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// AbortTooManyRequests answers 429 with the number of seconds to wait in Retry-After.
+func AbortTooManyRequests(ctx *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, dto.TooManyRequestsResponse(message))
+}
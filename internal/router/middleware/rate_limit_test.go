@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewMemoryLimiter()
+	rule := ratelimit.PerMinute(1, 2)
+
+	r := gin.New()
+	r.POST("/login", RateLimit(limiter, rule, ByJSONField("login", "email")), func(c *gin.Context) {
+		// The handler still gets the body the key was read from
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := send(`{"email":"victim@example.com"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"email":"victim@example.com"}`, w.Body.String())
+	}
+
+	// Case and spacing do not give a fresh bucket
+	w := send(`{"email":" Victim@Example.com "}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// Other emails and requests without one are not affected
+	assert.Equal(t, http.StatusOK, send(`{"email":"other@example.com"}`).Code)
+	assert.Equal(t, http.StatusOK, send(`not json`).Code)
+}
@@ -3,8 +3,8 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
-	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 )
 
 type RoomRouter struct {
@@ -17,12 +17,12 @@ func NewRoomRouter(handler *handler.RoomHandler) *RoomRouter {
 
 func (r *RoomRouter) SetupRoomRouter(group *gin.RouterGroup) {
 	router := group.Group("/rooms")
-	router.Use(middleware.RequireRoles(model.RoleAdmin, model.RoleCompany))
 	{
-		router.POST("", r.handler.CreateRoom)
-		router.GET("", r.handler.GetAllRooms)
-		router.GET("/:id", r.handler.GetRoomByID)
-		router.PUT("/:id", r.handler.UpdateRoom)
-		router.DELETE("/:id", r.handler.DeleteRoom)
+		router.POST("", middleware.RequirePermission(authz.RoomCreate), r.handler.CreateRoom)
+		router.GET("", middleware.RequirePermission(authz.RoomRead), r.handler.GetAllRooms)
+		router.GET("/:id", middleware.RequirePermission(authz.RoomRead), r.handler.GetRoomByID)
+		router.PUT("/:id", middleware.RequirePermission(authz.RoomUpdate), r.handler.UpdateRoom)
+		router.PATCH("/:id", middleware.RequirePermission(authz.RoomUpdate), r.handler.PatchRoom)
+		router.DELETE("/:id", middleware.RequirePermission(authz.RoomDelete), r.handler.DeleteRoom)
 	}
 }
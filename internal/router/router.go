@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
 	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/ratelimit"
 	"github.com/hfleury/bk_globalshot/pkg/token"
 )
 
@@ -28,7 +30,21 @@ func (r *Router) SetupRouter(
 	siteHandler *handler.SiteHandler, // Added
 	unitHandler *handler.UnitHandler, // Added
 	userHandler *handler.UserHandler, // Added
+	mediaHandler *handler.MediaHandler,
+	meHandler *handler.MeHandler,
+	mfaHandler *handler.MFAHandler,
+	invitationHandler *handler.InvitationHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	ssoHandler *handler.SSOHandler,
+	impersonationHandler *handler.ImpersonationHandler,
+	auditHandler *handler.AuditHandler,
+	searchHandler *handler.SearchHandler,
+	trashHandler *handler.TrashHandler,
 	tokenMaker token.Maker,
+	sessions middleware.SessionValidator,
+	apiKeys middleware.APIKeyAuthenticator,
+	limiter ratelimit.Limiter,
+	cfgRateLimit *config.ConfigRateLimit,
 ) {
 
 	// CORS Configuration
@@ -43,13 +59,15 @@ func (r *Router) SetupRouter(
 
 	config.AllowOrigins = allowOrigins
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Range"}
-	config.ExposeHeaders = []string{"Content-Range"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Range", "If-Match", "If-None-Match"}
+	config.ExposeHeaders = []string{"Content-Range", "ETag"}
+	// The single sign-on state cookie has to travel with the frontend's requests
+	config.AllowCredentials = true
 	r.eng.Use(cors.New(config))
 
 	api := r.eng.Group("/v1")
 	{
-		authRouter := NewAuthRouter(authHandler)
+		authRouter := NewAuthRouter(authHandler, invitationHandler, ssoHandler, limiter, cfgRateLimit)
 		authRouter.SetupAuthRouter(api)
 
 		healthRouter := NewHealthRouter(healthHandler)
@@ -57,7 +75,7 @@ func (r *Router) SetupRouter(
 
 		// Private routes
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(tokenMaker))
+		protected.Use(middleware.AuthMiddleware(tokenMaker, sessions, apiKeys), middleware.RestrictImpersonation())
 		{
 			companyRouter := NewCompanyRouter(companyHandler)
 			companyRouter.SetupCompanyRouter(protected)
@@ -73,6 +91,33 @@ func (r *Router) SetupRouter(
 
 			userRouter := NewUserRouter(userHandler)
 			userRouter.SetupUserRouter(protected)
+
+			mediaRouter := NewMediaRouter(mediaHandler)
+			mediaRouter.SetupMediaRouter(protected)
+
+			meRouter := NewMeRouter(meHandler, mfaHandler)
+			meRouter.SetupMeRouter(protected)
+
+			invitationRouter := NewInvitationRouter(invitationHandler)
+			invitationRouter.SetupInvitationRouter(protected)
+
+			apiKeyRouter := NewAPIKeyRouter(apiKeyHandler)
+			apiKeyRouter.SetupAPIKeyRouter(protected)
+
+			ssoRouter := NewSSORouter(ssoHandler)
+			ssoRouter.SetupSSORouter(protected)
+
+			adminRouter := NewAdminRouter(impersonationHandler)
+			adminRouter.SetupAdminRouter(protected)
+
+			auditRouter := NewAuditRouter(auditHandler)
+			auditRouter.SetupAuditRouter(protected)
+
+			searchRouter := NewSearchRouter(searchHandler)
+			searchRouter.SetupSearchRouter(protected)
+
+			trashRouter := NewTrashRouter(trashHandler)
+			trashRouter.SetupTrashRouter(protected)
 		}
 	}
 }
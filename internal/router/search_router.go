@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+)
+
+type SearchRouter struct {
+	handler *handler.SearchHandler
+}
+
+func NewSearchRouter(handler *handler.SearchHandler) *SearchRouter {
+	return &SearchRouter{handler: handler}
+}
+
+// SetupSearchRouter needs no permission of its own, each type of result is checked against
+// its read permission by the service.
+func (r *SearchRouter) SetupSearchRouter(config *gin.RouterGroup) {
+	config.GET("/search", r.handler.Search)
+}
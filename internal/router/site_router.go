@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 )
 
 type SiteRouter struct {
@@ -16,10 +18,11 @@ func NewSiteRouter(handler *handler.SiteHandler) *SiteRouter {
 func (r *SiteRouter) SetupSiteRouter(config *gin.RouterGroup) {
 	routes := config.Group("/sites")
 	{
-		routes.POST("", r.handler.CreateSite)
-		routes.GET("", r.handler.GetAllSites)
-		routes.GET("/:id", r.handler.GetSiteByID)
-		routes.PUT("/:id", r.handler.UpdateSite)
-		routes.DELETE("/:id", r.handler.DeleteSite)
+		routes.POST("", middleware.RequirePermission(authz.SiteCreate), r.handler.CreateSite)
+		routes.GET("", middleware.RequirePermission(authz.SiteRead), r.handler.GetAllSites)
+		routes.GET("/:id", middleware.RequirePermission(authz.SiteRead), r.handler.GetSiteByID)
+		routes.PUT("/:id", middleware.RequirePermission(authz.SiteUpdate), r.handler.UpdateSite)
+		routes.PATCH("/:id", middleware.RequirePermission(authz.SiteUpdate), r.handler.PatchSite)
+		routes.DELETE("/:id", middleware.RequirePermission(authz.SiteDelete), r.handler.DeleteSite)
 	}
 }
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type SSORouter struct {
+	handler *handler.SSOHandler
+}
+
+func NewSSORouter(handler *handler.SSOHandler) *SSORouter {
+	return &SSORouter{handler: handler}
+}
+
+// SetupSSORouter registers the provider settings of companies, the login itself is under /auth.
+func (r *SSORouter) SetupSSORouter(config *gin.RouterGroup) {
+	routes := config.Group("/companies/:id/oidc", middleware.RequireSession(), middleware.DenyImpersonation())
+	{
+		routes.PUT("", middleware.RequirePermission(authz.CompanyUpdate), r.handler.ConfigureSSO)
+		routes.DELETE("", middleware.RequirePermission(authz.CompanyUpdate), r.handler.DisableSSO)
+	}
+}
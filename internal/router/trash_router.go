@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+type TrashRouter struct {
+	handler *handler.TrashHandler
+}
+
+func NewTrashRouter(handler *handler.TrashHandler) *TrashRouter {
+	return &TrashRouter{handler: handler}
+}
+
+// SetupTrashRouter adds the trash and the restore routes of every resource that goes to it.
+// Restoring takes the permission to delete.
+func (r *TrashRouter) SetupTrashRouter(config *gin.RouterGroup) {
+	config.GET("/trash", middleware.RequirePermission(authz.TrashRead), r.handler.GetTrash)
+	config.POST("/companies/:id/restore", middleware.RequirePermission(authz.CompanyDelete), r.handler.RestoreCompany)
+	config.POST("/sites/:id/restore", middleware.RequirePermission(authz.SiteDelete), r.handler.RestoreSite)
+	config.POST("/units/:id/restore", middleware.RequirePermission(authz.UnitDelete), r.handler.RestoreUnit)
+	config.POST("/rooms/:id/restore", middleware.RequirePermission(authz.RoomDelete), r.handler.RestoreRoom)
+}
@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 )
 
 type UnitRouter struct {
@@ -16,11 +18,12 @@ func NewUnitRouter(handler *handler.UnitHandler) *UnitRouter {
 func (r *UnitRouter) SetupUnitRouter(config *gin.RouterGroup) {
 	routes := config.Group("/units")
 	{
-		routes.POST("", r.handler.CreateUnit)
-		routes.POST("/batch", r.handler.BatchCreate)
-		routes.GET("", r.handler.GetAllUnits)
-		routes.GET("/:id", r.handler.GetUnitByID)
-		routes.PUT("/:id", r.handler.UpdateUnit)
-		routes.DELETE("/:id", r.handler.DeleteUnit)
+		routes.POST("", middleware.RequirePermission(authz.UnitCreate), r.handler.CreateUnit)
+		routes.POST("/batch", middleware.RequirePermission(authz.UnitCreate), r.handler.BatchCreate)
+		routes.GET("", middleware.RequirePermission(authz.UnitRead), r.handler.GetAllUnits)
+		routes.GET("/:id", middleware.RequirePermission(authz.UnitRead), r.handler.GetUnitByID)
+		routes.PUT("/:id", middleware.RequirePermission(authz.UnitUpdate), r.handler.UpdateUnit)
+		routes.PATCH("/:id", middleware.RequirePermission(authz.UnitUpdate), r.handler.PatchUnit)
+		routes.DELETE("/:id", middleware.RequirePermission(authz.UnitDelete), r.handler.DeleteUnit)
 	}
 }
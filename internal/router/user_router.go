@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hfleury/bk_globalshot/internal/handler"
+	"github.com/hfleury/bk_globalshot/internal/router/middleware"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 )
 
 type UserRouter struct {
@@ -16,10 +18,11 @@ func NewUserRouter(handler *handler.UserHandler) *UserRouter {
 func (r *UserRouter) SetupUserRouter(config *gin.RouterGroup) {
 	routes := config.Group("/users")
 	{
-		routes.POST("", r.handler.CreateUser)
-		routes.GET("", r.handler.GetAllUsers)
-		routes.GET("/:id", r.handler.GetUserByID)
-		routes.PUT("/:id", r.handler.UpdateUser)
-		routes.DELETE("/:id", r.handler.DeleteUser)
+		routes.POST("", middleware.RequirePermission(authz.UserCreate), r.handler.CreateUser)
+		routes.GET("", middleware.RequirePermission(authz.UserRead), r.handler.GetAllUsers)
+		routes.GET("/:id", middleware.RequirePermission(authz.UserRead), r.handler.GetUserByID)
+		routes.PUT("/:id", middleware.RequirePermission(authz.UserUpdate), r.handler.UpdateUser)
+		routes.PATCH("/:id", middleware.RequirePermission(authz.UserUpdate), r.handler.PatchUser)
+		routes.DELETE("/:id", middleware.RequirePermission(authz.UserDelete), r.handler.DeleteUser)
 	}
 }
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+
+// AccountService is what a signed in user can do with their own account.
+//
+//go:generate mockgen -source=account_service.go -destination=../../mock/services/mock_account_service.go -package=mock_services
+type AccountService interface {
+	GetProfile(ctx context.Context, userID string) (*model.User, error)
+	// UpdateEmail checks the current password first. Wrong passwords count toward the login
+	// lockout, an AccountLockedError is returned while it lasts.
+	UpdateEmail(ctx context.Context, userID, email, currentPassword string) (*model.User, error)
+	// ChangePassword checks the current password like UpdateEmail and signs out every other session.
+	ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]*model.AuthSession, error)
+	// RevokeSession ends one of the user's sessions, it reports false when the user has no such session.
+	RevokeSession(ctx context.Context, userID, sessionID string) (bool, error)
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error
+	// Permissions lists what the caller's role is granted by the policy, for the frontend to
+	// hide what it cannot do. The services still check every call.
+	Permissions(ctx context.Context) ([]authz.Grant, error)
+}
+
+type accountService struct {
+	users    pkgRepository.UserRepository
+	sessions repository.AuthSessionRepository
+	lockouts repository.LoginLockoutRepository
+	policy   *authz.Policy
+	audit    AuditService
+	cfgLimit *config.ConfigRateLimit
+}
+
+func NewAccountService(users pkgRepository.UserRepository, sessions repository.AuthSessionRepository, lockouts repository.LoginLockoutRepository, policy *authz.Policy, audit AuditService, cfgLimit *config.ConfigRateLimit) AccountService {
+	return &accountService{users: users, sessions: sessions, lockouts: lockouts, policy: policy, audit: audit, cfgLimit: cfgLimit}
+}
+
+func (s *accountService) GetProfile(ctx context.Context, userID string) (*model.User, error) {
+	return s.users.FindByID(ctx, userID)
+}
+
+func (s *accountService) UpdateEmail(ctx context.Context, userID, email, currentPassword string) (*model.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+	if err := s.checkPassword(ctx, user, currentPassword); err != nil {
+		return nil, err
+	}
+	if user.Email == email {
+		return user, nil
+	}
+
+	if err := s.users.UpdateEmail(ctx, userID, email); err != nil {
+		return nil, err
+	}
+	before := *user
+	user.Email = email
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    user.CompanyID,
+		Before:       &before,
+		After:        user,
+	})
+	return user, nil
+}
+
+func (s *accountService) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidCurrentPassword
+	}
+	if err := s.checkPassword(ctx, user, currentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.users.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.sessions.RevokeOthersForUser(ctx, userID, sessionID)
+}
+
+// checkPassword confirms the user's current password. A stolen access token must not be
+// enough to guess it, so wrong ones count toward the login lockout.
+func (s *accountService) checkPassword(ctx context.Context, user *model.User, password string) error {
+	if err := checkLockout(ctx, s.lockouts, user.ID); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		if err := recordFailure(ctx, s.lockouts, s.cfgLimit, user.ID); err != nil {
+			return err
+		}
+		return ErrInvalidCurrentPassword
+	}
+	return nil
+}
+
+func (s *accountService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*model.AuthSession, error) {
+	sessions, err := s.sessions.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *accountService) RevokeSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return false, nil
+	}
+	session, err := s.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	// Someone else's session is reported as missing, not as forbidden
+	if session == nil || session.UserID != userID {
+		return false, nil
+	}
+	return true, s.sessions.Revoke(ctx, sessionID)
+}
+
+func (s *accountService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	return s.sessions.RevokeOthersForUser(ctx, userID, currentSessionID)
+}
+
+func (s *accountService) Permissions(ctx context.Context) ([]authz.Grant, error) {
+	sub, err := subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.policy.Grants(sub), nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/token"
+)
+
+var (
+	ErrInvalidAPIKeyScope   = errors.New("api key scope is unknown or not grantable")
+	ErrInvalidAPIKeyExpiry  = errors.New("api key expiry must be in the future")
+	ErrInvalidAPIKeyCompany = errors.New("api key company does not exist")
+	ErrAPIKeyRevoked        = errors.New("api key was already revoked")
+)
+
+const (
+	// APIKeyPrefix starts every key so they are easy to spot, in secret scanners too.
+	APIKeyPrefix = "gsk_"
+	// apiKeyIDLength is the hex identifier after APIKeyPrefix, stored in clear to find the key.
+	apiKeyIDLength = 12
+)
+
+// CreatedAPIKey carries the key itself, it is only ever returned by CreateAPIKey.
+type CreatedAPIKey struct {
+	*model.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyService manages the keys integrations of a company call the API with. A key acts as
+// a company user on behalf of the one who created it, narrowed to the scopes it was given.
+//
+//go:generate mockgen -source=api_key_service.go -destination=../../mock/services/mock_api_key_service.go -package=mock_services
+type APIKeyService interface {
+	// CreateAPIKey issues a key for the company, or the caller's company when companyID is empty.
+	CreateAPIKey(ctx context.Context, name, companyID string, scopes []string, expiresAt *time.Time) (*CreatedAPIKey, error)
+	GetAllAPIKeys(ctx context.Context, query listquery.Query) ([]*model.APIKey, int64, error)
+	RevokeAPIKey(ctx context.Context, id string) (*model.APIKey, error)
+	// AuthenticateAPIKey resolves a presented key to the identity it acts as. It returns nil
+	// when the key is unknown, revoked or expired.
+	AuthenticateAPIKey(ctx context.Context, key string) (*token.Payload, error)
+}
+
+type apiKeyService struct {
+	keys      repository.APIKeyRepository
+	companies repository.CompanyRepository
+	authz     Authorizer
+	policy    *authz.Policy
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepository, companies repository.CompanyRepository, authz Authorizer, policy *authz.Policy) APIKeyService {
+	return &apiKeyService{keys: keys, companies: companies, authz: authz, policy: policy}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, name, companyID string, scopes []string, expiresAt *time.Time) (*CreatedAPIKey, error) {
+	if companyID == "" {
+		if caller := model.UserFromContext(ctx); caller != nil {
+			companyID = caller.CompanyID
+		}
+	}
+	if companyID == "" {
+		return nil, ErrInvalidAPIKeyCompany
+	}
+	if err := s.authz.AuthorizeCompany(ctx, companyID, authz.APIKeyCreate); err != nil {
+		return nil, err
+	}
+
+	scopes, err := s.grantableScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	company, err := s.companies.FindByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil {
+		return nil, ErrInvalidAPIKeyCompany
+	}
+
+	key, prefix, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &model.APIKey{
+		ID:        uuid.New().String(),
+		CompanyID: companyID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if caller := model.UserFromContext(ctx); caller != nil {
+		apiKey.CreatedBy = optionalString(caller.ID)
+	}
+
+	if err := s.keys.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *apiKeyService) GetAllAPIKeys(ctx context.Context, query listquery.Query) ([]*model.APIKey, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.APIKeyRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scope.CustomerID != "" {
+		return nil, 0, ErrForbidden
+	}
+	return s.keys.FindAll(ctx, query, scope.CompanyID)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id string) (*model.APIKey, error) {
+	apiKey, err := s.keys.FindByID(ctx, id)
+	if err != nil || apiKey == nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeCompany(ctx, apiKey.CompanyID, authz.APIKeyDelete); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.keys.Revoke(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	now := time.Now().UTC()
+	apiKey.RevokedAt = &now
+	apiKey.UpdatedAt = now
+	return apiKey, nil
+}
+
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*token.Payload, error) {
+	prefix, ok := apiKeyPrefix(key)
+	if !ok {
+		return nil, nil
+	}
+	apiKey, err := s.keys.FindByPrefix(ctx, prefix)
+	if err != nil || apiKey == nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashToken(key))) != 1 {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	if !apiKey.IsActive(now) {
+		return nil, nil
+	}
+
+	// Tracking is informative, a failed write must not turn the caller away
+	if err := s.keys.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+		log.Printf("failed to record use of api key %s: %v", apiKey.ID, err)
+	}
+
+	payload := &token.Payload{
+		Role:      string(model.RoleCompany),
+		CompanyID: apiKey.CompanyID,
+		APIKeyID:  apiKey.ID,
+		Scopes:    apiKey.Scopes,
+	}
+	if apiKey.CreatedBy != nil {
+		payload.UserID = *apiKey.CreatedBy
+	}
+	if apiKey.ExpiresAt != nil {
+		payload.ExpiresAt = *apiKey.ExpiresAt
+	}
+	return payload, nil
+}
+
+// grantableScopes checks the scopes against what a company user holds, a key never reaches
+// further than its company. Managing keys is left out, keys cannot mint keys.
+func (s *apiKeyService) grantableScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidAPIKeyScope
+	}
+	companyUser := authz.Subject{Role: string(model.RoleCompany)}
+
+	seen := make(map[string]bool, len(scopes))
+	grantable := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		perm := authz.Permission(scope)
+		if !s.policy.Can(companyUser, perm) || strings.HasPrefix(scope, "api_key:") {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			grantable = append(grantable, scope)
+		}
+	}
+	return grantable, nil
+}
+
+// newAPIKey returns a key such as "gsk_1f0c9a7be2d4_<43 random characters>" and its prefix.
+func newAPIKey() (string, string, error) {
+	id := make([]byte, apiKeyIDLength/2)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	prefix := APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// apiKeyPrefix extracts the stored prefix of a presented key.
+func apiKeyPrefix(key string) (string, bool) {
+	n := len(APIKeyPrefix) + apiKeyIDLength
+	if !strings.HasPrefix(key, APIKeyPrefix) || len(key) <= n+1 || key[n] != '_' {
+		return "", false
+	}
+	return key[:n], true
+}
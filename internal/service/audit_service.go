@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// AuditEntry describes one change to record. Before and After are the resource as the API
+// returns it, so fields never shown, such as password hashes, never reach the trail either.
+type AuditEntry struct {
+	Action       model.AuditAction
+	ResourceType string
+	ResourceID   string
+	// CompanyID is the tenant of the resource. When it is empty the tenant is looked up
+	// from the closest parent given.
+	CompanyID string
+	SiteID    string
+	UnitID    string
+	RoomID    string
+	Before    interface{}
+	After     interface{}
+}
+
+// AuditService keeps the trail of who changed what, and shows it to admins and, for their
+// own company, to company users.
+//
+//go:generate mockgen -source=audit_service.go -destination=../../mock/services/mock_audit_service.go -package=mock_services
+type AuditService interface {
+	// Record adds an entry for the caller on the context. The change already happened when
+	// it is called, a failure to record is logged rather than failing the request.
+	Record(ctx context.Context, entry AuditEntry)
+	GetAuditEvents(ctx context.Context, query listquery.Query, filter repository.AuditFilter) ([]*model.AuditEvent, listquery.PageInfo, error)
+}
+
+type auditService struct {
+	repo    repository.AuditRepository
+	tenants repository.TenantRepository
+	authz   Authorizer
+}
+
+func NewAuditService(repo repository.AuditRepository, tenants repository.TenantRepository, authz Authorizer) AuditService {
+	return &auditService{repo: repo, tenants: tenants, authz: authz}
+}
+
+func (s *auditService) Record(ctx context.Context, entry AuditEntry) {
+	if err := s.record(ctx, entry); err != nil {
+		log.Printf("failed to record audit event %s %s %s: %v", entry.Action, entry.ResourceType, entry.ResourceID, err)
+	}
+}
+
+func (s *auditService) record(ctx context.Context, entry AuditEntry) error {
+	changes, err := auditChanges(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	companyID, err := s.tenantOf(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	client := model.ClientFromContext(ctx)
+	event := &model.AuditEvent{
+		ID:           uuid.New().String(),
+		OccurredAt:   time.Now().UTC(),
+		CompanyID:    optionalString(companyID),
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Changes:      changes,
+		IPAddress:    optionalString(client.IPAddress),
+		UserAgent:    optionalString(client.UserAgent),
+	}
+	if caller := model.UserFromContext(ctx); caller != nil {
+		event.ActorID = optionalString(caller.ID)
+		event.ImpersonatorID = optionalString(caller.ImpersonatorID)
+	}
+	return s.repo.Create(ctx, event)
+}
+
+func (s *auditService) GetAuditEvents(ctx context.Context, query listquery.Query, filter repository.AuditFilter) ([]*model.AuditEvent, listquery.PageInfo, error) {
+	scope, err := s.authz.Scope(ctx, authz.AuditRead)
+	if err != nil {
+		return nil, listquery.PageInfo{}, err
+	}
+	if scope.CustomerID != "" {
+		return nil, listquery.PageInfo{}, ErrForbidden
+	}
+	if scope.CompanyID != "" {
+		// Company users only ever see their own company, whatever they ask for
+		filter.CompanyID = scope.CompanyID
+	}
+	return s.repo.FindAll(ctx, query, filter)
+}
+
+// tenantOf returns the company the audited resource belongs to.
+func (s *auditService) tenantOf(ctx context.Context, entry AuditEntry) (string, error) {
+	var owner *repository.Ownership
+	var err error
+	switch {
+	case entry.CompanyID != "":
+		return entry.CompanyID, nil
+	case entry.RoomID != "":
+		owner, err = s.tenants.RoomOwnership(ctx, entry.RoomID)
+	case entry.UnitID != "":
+		owner, err = s.tenants.UnitOwnership(ctx, entry.UnitID)
+	case entry.SiteID != "":
+		owner, err = s.tenants.SiteOwnership(ctx, entry.SiteID)
+	}
+	if err != nil || owner == nil {
+		return "", err
+	}
+	return owner.CompanyID, nil
+}
+
+// auditChanges lists the fields that differ between two versions of a resource. Either may
+// be nil, for creations and deletions.
+func auditChanges(before, after interface{}) (map[string]model.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.AuditChange)
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = model.AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, seen := beforeFields[field]; !seen && value != nil {
+			changes[field] = model.AuditChange{After: value}
+		}
+	}
+	// Bumped by every update, they tell nothing the event does not
+	delete(changes, "updated_at")
+	delete(changes, "version")
+	return changes, nil
+}
+
+func auditFields(resource interface{}) (map[string]interface{}, error) {
+	if resource == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audited resource: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode audited resource: %w", err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+var ErrForbidden = authz.ErrForbidden
+
+// Authorizer applies the authz policy inside the services. It resolves which tenant a
+// resource belongs to, walking unit → site → company, and asks the policy whether the caller
+// may use the permission on it. The caller is taken from the context, see model.ContextWithUser.
+//
+// The Authorize methods let missing resources through, the service then reports them as not found.
+type Authorizer interface {
+	// Scope returns the filter a listing must apply for the caller.
+	Scope(ctx context.Context, perm authz.Permission) (repository.TenantScope, error)
+	AuthorizeCompany(ctx context.Context, companyID string, perm authz.Permission) error
+	AuthorizeSite(ctx context.Context, siteID string, perm authz.Permission) error
+	AuthorizeUnit(ctx context.Context, unitID string, perm authz.Permission) error
+	AuthorizeRoom(ctx context.Context, roomID string, perm authz.Permission) error
+	AuthorizeUser(ctx context.Context, target *model.User, perm authz.Permission) error
+}
+
+type authorizer struct {
+	policy  *authz.Policy
+	tenants repository.TenantRepository
+}
+
+func NewAuthorizer(policy *authz.Policy, tenants repository.TenantRepository) Authorizer {
+	return &authorizer{policy: policy, tenants: tenants}
+}
+
+func (a *authorizer) Scope(ctx context.Context, perm authz.Permission) (repository.TenantScope, error) {
+	sub, err := subject(ctx)
+	if err != nil {
+		return repository.TenantScope{}, err
+	}
+
+	switch a.policy.Scope(sub, perm) {
+	case authz.ScopeAll:
+		return repository.TenantScope{}, nil
+	case authz.ScopeCompany:
+		if sub.CompanyID != "" {
+			return repository.TenantScope{CompanyID: sub.CompanyID}, nil
+		}
+	case authz.ScopeOwn:
+		return repository.TenantScope{CustomerID: sub.ID}, nil
+	}
+	return repository.TenantScope{}, ErrForbidden
+}
+
+func (a *authorizer) AuthorizeCompany(ctx context.Context, companyID string, perm authz.Permission) error {
+	sub, err := subject(ctx)
+	if err != nil {
+		return err
+	}
+	return a.policy.Authorize(sub, perm, authz.Resource{CompanyID: companyID})
+}
+
+func (a *authorizer) AuthorizeSite(ctx context.Context, siteID string, perm authz.Permission) error {
+	sub, err := subject(ctx)
+	if err != nil {
+		return err
+	}
+	scope := a.policy.Scope(sub, perm)
+	if scope == authz.ScopeAll {
+		return nil
+	}
+	if scope == authz.ScopeNone {
+		return ErrForbidden
+	}
+
+	owner, err := a.tenants.SiteOwnership(ctx, siteID)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return nil
+	}
+
+	res := authz.Resource{CompanyID: owner.CompanyID}
+	if scope == authz.ScopeOwn {
+		// A site is nobody's own, it is reachable through any unit the customer has on it
+		ok, err := a.tenants.IsSiteCustomer(ctx, siteID, sub.ID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			res.OwnerID = sub.ID
+		}
+	}
+	return a.policy.Authorize(sub, perm, res)
+}
+
+func (a *authorizer) AuthorizeUnit(ctx context.Context, unitID string, perm authz.Permission) error {
+	return a.authorizeOwned(ctx, unitID, perm, a.tenants.UnitOwnership)
+}
+
+func (a *authorizer) AuthorizeRoom(ctx context.Context, roomID string, perm authz.Permission) error {
+	return a.authorizeOwned(ctx, roomID, perm, a.tenants.RoomOwnership)
+}
+
+func (a *authorizer) AuthorizeUser(ctx context.Context, target *model.User, perm authz.Permission) error {
+	sub, err := subject(ctx)
+	if err != nil {
+		return err
+	}
+	return a.policy.Authorize(sub, perm, authz.Resource{
+		CompanyID:  target.CompanyID,
+		OwnerID:    target.ID,
+		Restricted: model.Role(target.Role) == model.RoleAdmin,
+	})
+}
+
+func (a *authorizer) authorizeOwned(ctx context.Context, id string, perm authz.Permission,
+	lookup func(ctx context.Context, id string) (*repository.Ownership, error)) error {
+	sub, err := subject(ctx)
+	if err != nil {
+		return err
+	}
+	switch a.policy.Scope(sub, perm) {
+	case authz.ScopeAll:
+		return nil
+	case authz.ScopeNone:
+		return ErrForbidden
+	}
+
+	owner, err := lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return nil
+	}
+
+	res := authz.Resource{CompanyID: owner.CompanyID}
+	if owner.ClientID != nil {
+		res.OwnerID = *owner.ClientID
+	}
+	return a.policy.Authorize(sub, perm, res)
+}
+
+func subject(ctx context.Context) (authz.Subject, error) {
+	user := model.UserFromContext(ctx)
+	if user == nil {
+		return authz.Subject{}, ErrForbidden
+	}
+	return authz.SubjectFromUser(user), nil
+}
@@ -9,35 +9,61 @@ import (
 	"github.com/google/uuid"
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
 	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// CompanyPatch is a JSON merge patch of a company.
+type CompanyPatch struct {
+	Name mergepatch.Value[string] `json:"name" binding:"omitnil,min=1"`
+}
+
 //go:generate mockgen -source=company_service.go -destination=../../mock/services/mock_company_service.go -package=mock_services
 type CompanyService interface {
+	// CreateCompany creates a company and its first company user. Without a password the
+	// user is invited instead and picks one through the invitation link.
 	CreateCompany(ctx context.Context, name, email, password string) (*model.Company, error)
-	GetAllCompanies(ctx context.Context, limit, offset int) ([]*model.Company, int64, error)
+	GetAllCompanies(ctx context.Context, query listquery.Query) ([]*model.Company, int64, error)
 	GetCompanyByID(ctx context.Context, id string) (*model.Company, error)
-	UpdateCompany(ctx context.Context, id string, name string) (*model.Company, error)
-	DeleteCompany(ctx context.Context, id string) error
+	UpdateCompany(ctx context.Context, id string, name string, pre Precondition) (*model.Company, error)
+	PatchCompany(ctx context.Context, id string, patch CompanyPatch, pre Precondition) (*model.Company, error)
+	DeleteCompany(ctx context.Context, id string, pre Precondition) error
 }
 
 type companyService struct {
-	db       db.Db
-	repo     repository.CompanyRepository
-	userRepo pkgRepository.UserRepository
+	db          db.Db
+	repo        repository.CompanyRepository
+	userRepo    pkgRepository.UserRepository
+	invitations InvitationService
+	authz       Authorizer
+	audit       AuditService
 }
 
-func NewCompanyService(db db.Db, repo repository.CompanyRepository, userRepo pkgRepository.UserRepository) CompanyService {
+func NewCompanyService(db db.Db, repo repository.CompanyRepository, userRepo pkgRepository.UserRepository, invitations InvitationService, authz Authorizer, audit AuditService) CompanyService {
 	return &companyService{
-		db:       db,
-		repo:     repo,
-		userRepo: userRepo,
+		db:          db,
+		repo:        repo,
+		userRepo:    userRepo,
+		invitations: invitations,
+		authz:       authz,
+		audit:       audit,
 	}
 }
 
 func (s *companyService) CreateCompany(ctx context.Context, name, email, password string) (*model.Company, error) {
+	// A new company belongs to no tenant yet, only a grant over all of them can create one
+	if err := s.authz.AuthorizeCompany(ctx, "", authz.CompanyCreate); err != nil {
+		return nil, err
+	}
+
+	if password == "" {
+		return s.createCompanyAndInvite(ctx, name, email)
+	}
+
 	tx, err := s.db.BegrinTransaction(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -81,18 +107,80 @@ func (s *companyService) CreateCompany(ctx context.Context, name, email, passwor
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.auditCreated(ctx, company)
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    company.ID,
+		After:        user,
+	})
 	return company, nil
 }
 
-func (s *companyService) GetAllCompanies(ctx context.Context, limit, offset int) ([]*model.Company, int64, error) {
-	return s.repo.FindAll(ctx, limit, offset)
+func (s *companyService) createCompanyAndInvite(ctx context.Context, name, email string) (*model.Company, error) {
+	// Checked up front, a taken email would otherwise leave a company nobody can sign in to
+	existing, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, pkgRepository.ErrEmailAlreadyExists
+	}
+
+	company := &model.Company{
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, company); err != nil {
+		return nil, fmt.Errorf("failed to create company: %w", err)
+	}
+	s.auditCreated(ctx, company)
+
+	if _, err := s.invitations.CreateInvitation(ctx, email, string(model.RoleCompany), company.ID, nil); err != nil {
+		return nil, fmt.Errorf("failed to invite company user: %w", err)
+	}
+	return company, nil
+}
+
+func (s *companyService) GetAllCompanies(ctx context.Context, query listquery.Query) ([]*model.Company, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.CompanyRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scope.CustomerID != "" {
+		return nil, 0, ErrForbidden
+	}
+	if scope.CompanyID != "" {
+		// Company users only ever see their own company
+		query = query.With("id", scope.CompanyID)
+	}
+	return s.repo.FindAll(ctx, query)
 }
 
 func (s *companyService) GetCompanyByID(ctx context.Context, id string) (*model.Company, error) {
+	if err := s.authz.AuthorizeCompany(ctx, id, authz.CompanyRead); err != nil {
+		return nil, err
+	}
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *companyService) UpdateCompany(ctx context.Context, id string, name string) (*model.Company, error) {
+func (s *companyService) UpdateCompany(ctx context.Context, id string, name string, pre Precondition) (*model.Company, error) {
+	return s.updateCompany(ctx, id, pre, func(company *model.Company) {
+		company.Name = name
+	})
+}
+
+func (s *companyService) PatchCompany(ctx context.Context, id string, patch CompanyPatch, pre Precondition) (*model.Company, error) {
+	return s.updateCompany(ctx, id, pre, func(company *model.Company) {
+		patch.Name.Apply(&company.Name)
+	})
+}
+
+func (s *companyService) updateCompany(ctx context.Context, id string, pre Precondition, apply func(company *model.Company)) (*model.Company, error) {
+	if err := s.authz.AuthorizeCompany(ctx, id, authz.CompanyUpdate); err != nil {
+		return nil, err
+	}
 	company, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -100,16 +188,31 @@ func (s *companyService) UpdateCompany(ctx context.Context, id string, name stri
 	if company == nil {
 		return nil, nil // Or explicit error not found
 	}
+	if err := pre.Check(company.Version); err != nil {
+		return nil, err
+	}
 
-	company.Name = name
+	before := *company
+	apply(company)
 
 	if err := s.repo.Update(ctx, company); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceCompany,
+		ResourceID:   company.ID,
+		CompanyID:    company.ID,
+		Before:       &before,
+		After:        company,
+	})
 	return company, nil
 }
 
-func (s *companyService) DeleteCompany(ctx context.Context, id string) error {
+func (s *companyService) DeleteCompany(ctx context.Context, id string, pre Precondition) error {
+	if err := s.authz.AuthorizeCompany(ctx, id, authz.CompanyDelete); err != nil {
+		return err
+	}
 	company, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -117,5 +220,28 @@ func (s *companyService) DeleteCompany(ctx context.Context, id string) error {
 	if company == nil {
 		return nil // Or error not found
 	}
-	return s.repo.Delete(ctx, id)
+	if err := pre.Check(company.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, company.Version); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceCompany,
+		ResourceID:   company.ID,
+		CompanyID:    company.ID,
+		Before:       company,
+	})
+	return nil
+}
+
+func (s *companyService) auditCreated(ctx context.Context, company *model.Company) {
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceCompany,
+		ResourceID:   company.ID,
+		CompanyID:    company.ID,
+		After:        company,
+	})
 }
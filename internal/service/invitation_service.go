@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mailer"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidInvitation     = errors.New("invalid or expired invitation")
+	ErrInvitationNotPending  = errors.New("invitation was already accepted or revoked")
+	ErrInvalidInvitationRole = errors.New("invalid invitation role")
+	// ErrInvalidInvitationUnit covers units that do not exist, belong to another company
+	// or are offered to anyone but a customer.
+	ErrInvalidInvitationUnit = errors.New("unit cannot be assigned by this invitation")
+)
+
+//go:generate mockgen -source=invitation_service.go -destination=../../mock/services/mock_invitation_service.go -package=mock_services
+type InvitationService interface {
+	// CreateInvitation records a pending invitation and emails its link to the invitee.
+	CreateInvitation(ctx context.Context, email, role, companyID string, unitID *string) (*model.Invitation, error)
+	GetAllInvitations(ctx context.Context, query listquery.Query) ([]*model.Invitation, int64, error)
+	// ResendInvitation emails a fresh link with a new expiry, the previous link stops working.
+	ResendInvitation(ctx context.Context, id string) (*model.Invitation, error)
+	RevokeInvitation(ctx context.Context, id string) (*model.Invitation, error)
+	// AcceptInvitation creates the invitee's account with the password they chose and
+	// hands them the unit of the invitation, if any.
+	AcceptInvitation(ctx context.Context, invitationToken, password string) (*model.User, error)
+}
+
+type invitationService struct {
+	db          db.Db
+	invitations repository.InvitationRepository
+	users       pkgRepository.UserRepository
+	units       repository.UnitRepository
+	tenants     repository.TenantRepository
+	mailer      mailer.Mailer
+	cfgToken    *config.ConfigToken
+	authz       Authorizer
+	audit       AuditService
+}
+
+func NewInvitationService(
+	db db.Db,
+	invitations repository.InvitationRepository,
+	users pkgRepository.UserRepository,
+	units repository.UnitRepository,
+	tenants repository.TenantRepository,
+	mailer mailer.Mailer,
+	cfgToken *config.ConfigToken,
+	authz Authorizer,
+	audit AuditService,
+) InvitationService {
+	return &invitationService{
+		db:          db,
+		invitations: invitations,
+		users:       users,
+		units:       units,
+		tenants:     tenants,
+		mailer:      mailer,
+		cfgToken:    cfgToken,
+		authz:       authz,
+		audit:       audit,
+	}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, email, role, companyID string, unitID *string) (*model.Invitation, error) {
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidInvitationRole
+	}
+
+	if unitID != nil {
+		if role != string(model.RoleCustomer) {
+			return nil, ErrInvalidInvitationUnit
+		}
+		if err := s.authz.AuthorizeUnit(ctx, *unitID, authz.UnitUpdate); err != nil {
+			return nil, err
+		}
+		ownership, err := s.tenants.UnitOwnership(ctx, *unitID)
+		if err != nil {
+			return nil, err
+		}
+		if ownership == nil || (companyID != "" && ownership.CompanyID != companyID) {
+			return nil, ErrInvalidInvitationUnit
+		}
+		// The customer joins the company the unit is built by
+		companyID = ownership.CompanyID
+	}
+
+	if err := s.authz.AuthorizeUser(ctx, &model.User{Email: email, Role: role, CompanyID: companyID}, authz.UserCreate); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, pkgRepository.ErrEmailAlreadyExists
+	}
+
+	invitationToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	invitation := &model.Invitation{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Role:      role,
+		CompanyID: optionalString(companyID),
+		UnitID:    unitID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(s.cfgToken.InviteTokenExpiry),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if inviter := model.UserFromContext(ctx); inviter != nil {
+		invitation.InvitedBy = optionalString(inviter.ID)
+	}
+
+	if err := s.invitations.Create(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	s.sendInvitation(ctx, invitation, invitationToken)
+	return invitation, nil
+}
+
+func (s *invitationService) GetAllInvitations(ctx context.Context, query listquery.Query) ([]*model.Invitation, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.UserRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scope.CustomerID != "" {
+		return nil, 0, ErrForbidden
+	}
+	return s.invitations.FindAll(ctx, query, scope.CompanyID)
+}
+
+func (s *invitationService) ResendInvitation(ctx context.Context, id string) (*model.Invitation, error) {
+	invitation, err := s.findInvitation(ctx, id, authz.UserCreate)
+	if err != nil || invitation == nil {
+		return nil, err
+	}
+
+	invitationToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = now.Add(s.cfgToken.InviteTokenExpiry)
+	invitation.UpdatedAt = now
+
+	renewed, err := s.invitations.RenewToken(ctx, invitation)
+	if err != nil {
+		return nil, err
+	}
+	if !renewed {
+		return nil, ErrInvitationNotPending
+	}
+
+	s.sendInvitation(ctx, invitation, invitationToken)
+	return invitation, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, id string) (*model.Invitation, error) {
+	invitation, err := s.findInvitation(ctx, id, authz.UserDelete)
+	if err != nil || invitation == nil {
+		return nil, err
+	}
+
+	revoked, err := s.invitations.Revoke(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrInvitationNotPending
+	}
+
+	now := time.Now().UTC()
+	invitation.RevokedAt = &now
+	invitation.UpdatedAt = now
+	return invitation, nil
+}
+
+func (s *invitationService) AcceptInvitation(ctx context.Context, invitationToken, password string) (*model.User, error) {
+	invitation, err := s.invitations.FindByTokenHash(ctx, hashToken(invitationToken))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || !invitation.IsPending(time.Now()) {
+		return nil, ErrInvalidInvitation
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.BegrinTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	txAdapter := db.NewTxAdapter(tx.(*sql.Tx))
+	txInvitations := s.invitations.WithTx(txAdapter)
+	txUsers := s.users.WithTx(txAdapter)
+	txUnits := s.units.WithTx(txAdapter)
+
+	// Consume the invitation first so two concurrent acceptances cannot both go through
+	accepted, err := txInvitations.MarkAccepted(ctx, invitation.ID)
+	if err != nil {
+		s.db.Rollback(ctx, tx)
+		return nil, err
+	}
+	if !accepted {
+		s.db.Rollback(ctx, tx)
+		return nil, ErrInvalidInvitation
+	}
+
+	user := &model.User{
+		ID:       uuid.New().String(),
+		Email:    invitation.Email,
+		Password: string(hashedPassword),
+		Role:     invitation.Role,
+	}
+	if invitation.CompanyID != nil {
+		user.CompanyID = *invitation.CompanyID
+	}
+	if err := txUsers.Create(ctx, user); err != nil {
+		s.db.Rollback(ctx, tx)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	var unit, unitBefore *model.Unit
+	if invitation.UnitID != nil {
+		unit, err = txUnits.FindByID(ctx, *invitation.UnitID)
+		if err != nil {
+			s.db.Rollback(ctx, tx)
+			return nil, err
+		}
+		// The unit may have been deleted since, the account is still worth creating
+		if unit != nil {
+			before := *unit
+			unitBefore = &before
+			unit.ClientID = &user.ID
+			unit.UpdatedAt = time.Now()
+			if err := txUnits.Update(ctx, unit); err != nil {
+				s.db.Rollback(ctx, tx)
+				return nil, fmt.Errorf("failed to assign unit: %w", err)
+			}
+		}
+	}
+
+	if err := s.db.Commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Nobody is signed in yet, the invitee creates the account themselves
+	ctx = model.ContextWithUser(ctx, user)
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    user.CompanyID,
+		After:        user,
+	})
+	if unit != nil {
+		s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionUpdate,
+			ResourceType: model.AuditResourceUnit,
+			ResourceID:   unit.ID,
+			SiteID:       unit.SiteID,
+			Before:       unitBefore,
+			After:        unit,
+		})
+	}
+	return user, nil
+}
+
+// findInvitation loads an invitation the caller may manage as if it were the user it will become.
+func (s *invitationService) findInvitation(ctx context.Context, id string, perm authz.Permission) (*model.Invitation, error) {
+	invitation, err := s.invitations.FindByID(ctx, id)
+	if err != nil || invitation == nil {
+		return nil, err
+	}
+
+	target := &model.User{Email: invitation.Email, Role: invitation.Role}
+	if invitation.CompanyID != nil {
+		target.CompanyID = *invitation.CompanyID
+	}
+	if err := s.authz.AuthorizeUser(ctx, target, perm); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *invitationService) sendInvitation(ctx context.Context, invitation *model.Invitation, invitationToken string) {
+	msg := mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: "You have been invited to GlobalShot",
+		Body: fmt.Sprintf("Hello,\n\n"+
+			"You have been invited to join GlobalShot.\n"+
+			"Follow the link below to choose your password and activate your account, it expires in %s:\n\n"+
+			"%s\n\n"+
+			"If you were not expecting this invitation, you can ignore this email.\n",
+			s.cfgToken.InviteTokenExpiry, resetLink(s.cfgToken.InviteURL, invitationToken)),
+	}
+	// The invitation stays pending, a failed delivery can be retried by resending it
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send invitation %s: %v", invitation.ID, err)
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"path"
+	"strconv"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/imaging"
+	"github.com/hfleury/bk_globalshot/pkg/storage"
+)
+
+const (
+	previewWidth    = 2048
+	thumbnailWidth  = 480
+	thumbnailHeight = 360
+	thumbnailFOV    = 90
+)
+
+// MediaProcessor generates the derived renditions and, for panoramas, the cube map tile
+// pyramid of uploaded captures in the background, so uploads return as soon as the original is stored.
+type MediaProcessor interface {
+	// Enqueue schedules a media item for processing. It never blocks; when the queue is full
+	// the item stays pending and is picked up again on the next start.
+	Enqueue(mediaID string) bool
+	Start(ctx context.Context)
+}
+
+type mediaProcessor struct {
+	repo            repository.MediaRepository
+	storage         storage.Storage
+	queue           chan string
+	workers         int
+	tileSize        int
+	tileMaxFaceSize int
+	maxImagePixels  int
+}
+
+func NewMediaProcessor(repo repository.MediaRepository, storage storage.Storage, cfg *config.ConfigMedia) MediaProcessor {
+	workers := cfg.ProcessingWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	tileSize := cfg.TileSize
+	if tileSize < 1 {
+		tileSize = 512
+	}
+	return &mediaProcessor{
+		repo:            repo,
+		storage:         storage,
+		queue:           make(chan string, cfg.ProcessingQueueSize),
+		workers:         workers,
+		tileSize:        tileSize,
+		tileMaxFaceSize: cfg.TileMaxFaceSize,
+		maxImagePixels:  cfg.MaxImagePixels,
+	}
+}
+
+func (p *mediaProcessor) Enqueue(mediaID string) bool {
+	select {
+	case p.queue <- mediaID:
+		return true
+	default:
+		log.Printf("media processing queue full, %s left pending", mediaID)
+		return false
+	}
+}
+
+func (p *mediaProcessor) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.worker(ctx)
+	}
+	go p.resumePending(ctx)
+}
+
+// resumePending re-queues work interrupted by a restart.
+func (p *mediaProcessor) resumePending(ctx context.Context) {
+	pending, err := p.repo.FindByProcessingStatus(ctx, cap(p.queue), model.MediaProcessingPending, model.MediaProcessingProcessing)
+	if err != nil {
+		log.Printf("failed to load pending media: %v", err)
+		return
+	}
+	pendingTiles, err := p.repo.FindByTilesStatus(ctx, cap(p.queue), model.MediaProcessingPending, model.MediaProcessingProcessing)
+	if err != nil {
+		log.Printf("failed to load media pending tiling: %v", err)
+		return
+	}
+
+	queued := make(map[string]bool)
+	for _, media := range append(pending, pendingTiles...) {
+		if queued[media.ID] {
+			continue
+		}
+		if !p.Enqueue(media.ID) {
+			return
+		}
+		queued[media.ID] = true
+	}
+}
+
+func (p *mediaProcessor) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			p.process(ctx, id)
+		}
+	}
+}
+
+func (p *mediaProcessor) process(ctx context.Context, id string) {
+	media, err := p.repo.FindByID(ctx, id)
+	if err != nil {
+		log.Printf("failed to load media %s for processing: %v", id, err)
+		return
+	}
+	if media == nil {
+		return // Deleted while queued
+	}
+
+	media.ProcessingStatus = model.MediaProcessingProcessing
+	media.ProcessingError = nil
+	if media.TilesStatus != nil {
+		setTilesStatus(media, model.MediaProcessingProcessing)
+		media.TilesError = nil
+	}
+	if err := p.repo.UpdateProcessing(ctx, media); err != nil {
+		log.Printf("failed to mark media %s as processing: %v", id, err)
+		return
+	}
+
+	original, err := p.loadOriginal(ctx, media)
+	if err == nil {
+		err = p.generateRenditions(ctx, media, original)
+	}
+	if err != nil {
+		log.Printf("failed to process media %s: %v", id, err)
+		msg := err.Error()
+		media.ProcessingStatus = model.MediaProcessingFailed
+		media.ProcessingError = &msg
+		if media.TilesStatus != nil {
+			setTilesStatus(media, model.MediaProcessingFailed)
+			media.TilesError = &msg
+		}
+	} else {
+		media.ProcessingStatus = model.MediaProcessingReady
+	}
+
+	// Save the renditions before tiling, thumbnails should not wait on the much slower pyramid
+	if err := p.repo.UpdateProcessing(ctx, media); err != nil {
+		log.Printf("failed to save processing result for media %s: %v", id, err)
+		return
+	}
+	if media.ProcessingStatus != model.MediaProcessingReady || media.TilesStatus == nil {
+		return
+	}
+
+	if err := p.generateTiles(ctx, media, original); err != nil {
+		log.Printf("failed to tile media %s: %v", id, err)
+		msg := err.Error()
+		setTilesStatus(media, model.MediaProcessingFailed)
+		media.TilesError = &msg
+	} else {
+		setTilesStatus(media, model.MediaProcessingReady)
+	}
+
+	if err := p.repo.UpdateProcessing(ctx, media); err != nil {
+		log.Printf("failed to save tiling result for media %s: %v", id, err)
+	}
+}
+
+func (p *mediaProcessor) loadOriginal(ctx context.Context, media *model.Media) (image.Image, error) {
+	r, _, err := p.storage.Get(ctx, media.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read original: %w", err)
+	}
+	defer r.Close()
+	return imaging.DecodeLimited(r, p.maxImagePixels)
+}
+
+func (p *mediaProcessor) generateRenditions(ctx context.Context, media *model.Media, original image.Image) error {
+	// Work from the preview, it is plenty of resolution for a thumbnail and far cheaper to sample
+	preview := imaging.ResizeToWidth(original, previewWidth)
+	if err := p.putJPEG(ctx, mediaVariantKey(media, model.MediaVariantPreview), preview); err != nil {
+		return err
+	}
+
+	// Panoramas get a flat view looking at the centre of the capture, anything else is simply scaled down
+	var thumbnail image.Image
+	if isPanorama(media) {
+		thumbnail = imaging.Perspective(imaging.ToRGBA(preview), imaging.View{FOV: thumbnailFOV}, thumbnailWidth, thumbnailHeight)
+	} else {
+		thumbnail = imaging.ResizeToWidth(preview, thumbnailWidth)
+	}
+	if err := p.putJPEG(ctx, mediaVariantKey(media, model.MediaVariantThumbnail), thumbnail); err != nil {
+		return err
+	}
+
+	thumbnailURL := mediaVariantURL(media, model.MediaVariantThumbnail)
+	previewURL := mediaVariantURL(media, model.MediaVariantPreview)
+	media.ThumbnailURL = &thumbnailURL
+	media.PreviewURL = &previewURL
+	return nil
+}
+
+// generateTiles renders the cube map of a panorama at its most detailed level and
+// scales each face down for the levels below, slicing every level into tiles.
+func (p *mediaProcessor) generateTiles(ctx context.Context, media *model.Media, original image.Image) error {
+	levels := imaging.CubeLevels(original.Bounds().Dx(), p.tileSize, p.tileMaxFaceSize)
+	if len(levels) == 0 {
+		return fmt.Errorf("image too small to tile")
+	}
+	top := levels[len(levels)-1]
+
+	// Sampling never needs more than four panorama pixels per face pixel, drop the rest up front
+	src := imaging.ToRGBA(imaging.ResizeToWidth(original, 4*top.Size))
+
+	for _, face := range imaging.CubeFaces {
+		rendered := imaging.RenderCubeFace(src, face, top.Size)
+		for z := len(levels) - 1; z >= 0; z-- {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			level := levels[z]
+			faceImg := rendered
+			if level.Size != top.Size {
+				faceImg = imaging.ResizeSquare(rendered, level.Size)
+			}
+			for y := 0; y < level.Tiles(); y++ {
+				for x := 0; x < level.Tiles(); x++ {
+					tile := faceImg.SubImage(level.TileRect(x, y))
+					if err := p.putJPEG(ctx, mediaTileKey(media, z, face, x, y), tile); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	media.TileSize = &top.TileSize
+	media.TileFaceSize = &top.Size
+	return nil
+}
+
+func (p *mediaProcessor) putJPEG(ctx context.Context, key string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := imaging.EncodeJPEG(&buf, img, imaging.DefaultJPEGQuality); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if _, err := p.storage.Put(ctx, key, &buf, storage.PutOptions{ContentType: "image/jpeg", Size: int64(buf.Len())}); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// mediaVariantKey returns where a rendition lives in storage. Derived renditions sit in a
+// folder named after the media next to the original.
+func mediaVariantKey(media *model.Media, variant model.MediaVariant) string {
+	if variant == model.MediaVariantOriginal {
+		return media.StorageKey
+	}
+	return path.Join("rooms", media.RoomID, media.ID, string(variant)+".jpg")
+}
+
+// mediaTileKey returns where a tile of the cube map pyramid lives, next to the other renditions.
+func mediaTileKey(media *model.Media, level int, face imaging.CubeFace, x, y int) string {
+	return path.Join("rooms", media.RoomID, media.ID, "tiles", strconv.Itoa(level), string(face), fmt.Sprintf("%d_%d.jpg", y, x))
+}
+
+func mediaTilesURLTemplate(media *model.Media) string {
+	return "/v1/media/" + media.ID + "/tiles/{z}/{f}/{y}/{x}"
+}
+
+func setTilesStatus(media *model.Media, status model.MediaProcessingStatus) {
+	media.TilesStatus = &status
+}
+
+func mediaVariantURL(media *model.Media, variant model.MediaVariant) string {
+	if variant == model.MediaVariantOriginal {
+		return "/v1/media/" + media.ID + "/file"
+	}
+	return "/v1/media/" + media.ID + "/" + string(variant)
+}
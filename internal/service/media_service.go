@@ -0,0 +1,416 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/imaging"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/storage"
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrNotEquirectangular   = errors.New("room expects 360° equirectangular captures")
+	ErrImageTooLarge        = errors.New("image dimensions exceed the limit")
+	ErrMediaNotTiled        = errors.New("media has no tile pyramid")
+)
+
+// allowedMediaTypes lists the sniffed content types accepted for 360° captures.
+var allowedMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// MediaFile is an open rendition of a media item, the caller must close Reader.
+type MediaFile struct {
+	Media       *model.Media
+	Reader      io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+type UploadMediaInput struct {
+	RoomID     string
+	UploadedBy string
+	TakenAt    *time.Time // Optional, defaults to upload time
+	FileName   string
+	File       io.Reader
+}
+
+//go:generate mockgen -source=media_service.go -destination=../../mock/services/mock_media_service.go -package=mock_services
+type MediaService interface {
+	UploadMedia(ctx context.Context, input UploadMediaInput) (*model.Media, error)
+	GetRoomMedia(ctx context.Context, roomID string, query listquery.Query) ([]*model.Media, listquery.PageInfo, error)
+	GetMediaByID(ctx context.Context, id string) (*model.Media, error)
+	OpenMediaFile(ctx context.Context, id string, variant model.MediaVariant) (*MediaFile, error)
+	// GetTileManifest describes the cube map pyramid of a panorama, levels are only listed once tiling is done.
+	GetTileManifest(ctx context.Context, id string) (*model.TileManifest, error)
+	OpenTile(ctx context.Context, id string, level int, face imaging.CubeFace, x, y int) (*MediaFile, error)
+	RegenerateRenditions(ctx context.Context, id string) (*model.Media, error)
+	DeleteMedia(ctx context.Context, id string, pre Precondition) error
+}
+
+type mediaService struct {
+	repo      repository.MediaRepository
+	roomRepo  repository.RoomRepository
+	storage   storage.Storage
+	processor MediaProcessor
+	authz     Authorizer
+	audit     AuditService
+	maxPixels int
+}
+
+func NewMediaService(repo repository.MediaRepository, roomRepo repository.RoomRepository, storage storage.Storage, processor MediaProcessor, authz Authorizer, audit AuditService, cfg *config.ConfigMedia) MediaService {
+	return &mediaService{
+		repo:      repo,
+		roomRepo:  roomRepo,
+		storage:   storage,
+		processor: processor,
+		authz:     authz,
+		audit:     audit,
+		maxPixels: cfg.MaxImagePixels,
+	}
+}
+
+func (s *mediaService) UploadMedia(ctx context.Context, input UploadMediaInput) (*model.Media, error) {
+	if err := s.authz.AuthorizeRoom(ctx, input.RoomID, authz.MediaUpload); err != nil {
+		return nil, err
+	}
+	room, err := s.roomRepo.FindByID(ctx, input.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, nil // Room not found
+	}
+
+	// Peek at the header only, the body is streamed straight to storage afterwards
+	reader := bufio.NewReaderSize(input.File, imaging.MetadataHeaderSize)
+	head, err := reader.Peek(imaging.MetadataHeaderSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	// Sniff the real content type instead of trusting the client supplied header
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedMediaTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	meta := imaging.ReadMetadata(head)
+	if room.Panoramic && !meta.IsEquirectangular() {
+		return nil, ErrNotEquirectangular
+	}
+
+	// Check the declared dimensions now, the processor would have to decode the whole bitmap
+	var header bytes.Buffer
+	if err := imaging.CheckSize(io.TeeReader(reader, &header), s.maxPixels); err != nil {
+		if errors.Is(err, imaging.ErrImageTooLarge) {
+			return nil, ErrImageTooLarge
+		}
+		return nil, ErrUnsupportedMediaType
+	}
+	body := io.MultiReader(&header, reader)
+
+	now := time.Now()
+	media := &model.Media{
+		ID:               uuid.New().String(),
+		RoomID:           room.ID,
+		FileName:         input.FileName,
+		ContentType:      contentType,
+		ProcessingStatus: model.MediaProcessingPending,
+		CreatedAt:        now,
+	}
+	applyCaptureMetadata(media, meta, input.TakenAt, now)
+	if isPanorama(media) {
+		setTilesStatus(media, model.MediaProcessingPending)
+	}
+	if input.UploadedBy != "" {
+		media.UploadedBy = &input.UploadedBy
+	}
+	media.StorageKey = path.Join("rooms", room.ID, media.ID+ext)
+	media.URL = mediaVariantURL(media, model.MediaVariantOriginal)
+
+	info, err := s.storage.Put(ctx, media.StorageKey, body, storage.PutOptions{ContentType: contentType, Size: -1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	media.SizeBytes = info.Size
+
+	if err := s.repo.Create(ctx, media); err != nil {
+		s.removeObject(ctx, media.StorageKey)
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceMedia,
+		ResourceID:   media.ID,
+		RoomID:       media.RoomID,
+		After:        media,
+	})
+
+	s.processor.Enqueue(media.ID)
+	return media, nil
+}
+
+func (s *mediaService) GetRoomMedia(ctx context.Context, roomID string, query listquery.Query) ([]*model.Media, listquery.PageInfo, error) {
+	if err := s.authz.AuthorizeRoom(ctx, roomID, authz.MediaRead); err != nil {
+		return nil, listquery.PageInfo{}, err
+	}
+	return s.repo.FindAllByRoomID(ctx, query, roomID)
+}
+
+func (s *mediaService) GetMediaByID(ctx context.Context, id string) (*model.Media, error) {
+	return s.findMedia(ctx, id, authz.MediaRead)
+}
+
+func (s *mediaService) OpenMediaFile(ctx context.Context, id string, variant model.MediaVariant) (*MediaFile, error) {
+	media, err := s.findMedia(ctx, id, authz.MediaRead)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, nil
+	}
+	if variant != model.MediaVariantOriginal && media.ProcessingStatus != model.MediaProcessingReady {
+		return nil, nil // Rendition not generated yet
+	}
+
+	reader, info, err := s.storage.Get(ctx, mediaVariantKey(media, variant))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open media file: %w", err)
+	}
+
+	contentType := info.ContentType
+	if variant == model.MediaVariantOriginal {
+		contentType = media.ContentType
+	}
+	return &MediaFile{
+		Media:       media,
+		Reader:      reader,
+		ContentType: contentType,
+		Size:        info.Size,
+	}, nil
+}
+
+func (s *mediaService) GetTileManifest(ctx context.Context, id string) (*model.TileManifest, error) {
+	media, err := s.findMedia(ctx, id, authz.MediaRead)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, nil
+	}
+	if media.TilesStatus == nil {
+		return nil, ErrMediaNotTiled
+	}
+
+	manifest := &model.TileManifest{
+		MediaID:     media.ID,
+		Status:      *media.TilesStatus,
+		Projection:  "cubemap",
+		PreviewURL:  media.PreviewURL,
+		PoseHeading: media.PoseHeading,
+	}
+	levels := tileLevels(media)
+	if levels == nil {
+		return manifest, nil
+	}
+
+	for _, face := range imaging.CubeFaces {
+		manifest.Faces = append(manifest.Faces, string(face))
+	}
+	for z, level := range levels {
+		manifest.Levels = append(manifest.Levels, model.TileLevel{
+			Level:    z,
+			Size:     level.Size,
+			TileSize: level.TileSize,
+			Tiles:    level.Tiles(),
+		})
+	}
+	manifest.URLTemplate = mediaTilesURLTemplate(media)
+	return manifest, nil
+}
+
+func (s *mediaService) OpenTile(ctx context.Context, id string, level int, face imaging.CubeFace, x, y int) (*MediaFile, error) {
+	media, err := s.findMedia(ctx, id, authz.MediaRead)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, nil
+	}
+
+	// Only look up tiles the manifest advertises, anything else cannot exist
+	levels := tileLevels(media)
+	if level < 0 || level >= len(levels) || x < 0 || y < 0 || x >= levels[level].Tiles() || y >= levels[level].Tiles() {
+		return nil, nil
+	}
+
+	reader, info, err := s.storage.Get(ctx, mediaTileKey(media, level, face, x, y))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open tile: %w", err)
+	}
+	return &MediaFile{
+		Media:       media,
+		Reader:      reader,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+	}, nil
+}
+
+func (s *mediaService) RegenerateRenditions(ctx context.Context, id string) (*model.Media, error) {
+	media, err := s.findMedia(ctx, id, authz.MediaRegenerate)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, nil
+	}
+
+	before := *media
+	media.ProcessingStatus = model.MediaProcessingPending
+	media.ProcessingError = nil
+	if isPanorama(media) {
+		setTilesStatus(media, model.MediaProcessingPending)
+		media.TilesError = nil
+	}
+	if err := s.repo.UpdateProcessing(ctx, media); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceMedia,
+		ResourceID:   media.ID,
+		RoomID:       media.RoomID,
+		Before:       &before,
+		After:        media,
+	})
+
+	s.processor.Enqueue(media.ID)
+	return media, nil
+}
+
+func (s *mediaService) DeleteMedia(ctx context.Context, id string, pre Precondition) error {
+	media, err := s.findMedia(ctx, id, authz.MediaDelete)
+	if err != nil {
+		return err
+	}
+	if media == nil {
+		return nil
+	}
+	if err := pre.Check(media.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceMedia,
+		ResourceID:   media.ID,
+		RoomID:       media.RoomID,
+		Before:       media,
+	})
+
+	s.removeObject(ctx, media.StorageKey)
+	derived, err := s.storage.List(ctx, path.Dir(mediaVariantKey(media, model.MediaVariantPreview))+"/")
+	if err != nil {
+		log.Printf("failed to list renditions of media %s: %v", id, err)
+		return nil
+	}
+	for _, obj := range derived {
+		s.removeObject(ctx, obj.Key)
+	}
+	return nil
+}
+
+// applyCaptureMetadata copies what the file told us onto the media. The embedded capture date is
+// preferred over the client supplied one since photographers routinely forget to set it.
+func applyCaptureMetadata(media *model.Media, meta *imaging.Metadata, clientTakenAt *time.Time, uploadedAt time.Time) {
+	switch {
+	case meta.TakenAt != nil:
+		media.TakenAt = *meta.TakenAt
+		media.TakenAtSource = model.TakenAtSourceExif
+	case clientTakenAt != nil:
+		media.TakenAt = *clientTakenAt
+		media.TakenAtSource = model.TakenAtSourceClient
+	default:
+		media.TakenAt = uploadedAt
+		media.TakenAtSource = model.TakenAtSourceUpload
+	}
+
+	media.Latitude = meta.Latitude
+	media.Longitude = meta.Longitude
+	media.PoseHeading = meta.PoseHeading
+	if meta.CameraMake != "" {
+		media.CameraMake = &meta.CameraMake
+	}
+	if meta.CameraModel != "" {
+		media.CameraModel = &meta.CameraModel
+	}
+	if meta.Width > 0 && meta.Height > 0 {
+		media.Width = &meta.Width
+		media.Height = &meta.Height
+	}
+
+	// Record the effective projection so later processing knows whether it holds a panorama
+	if meta.IsEquirectangular() {
+		projection := imaging.ProjectionEquirectangular
+		media.ProjectionType = &projection
+	} else if meta.ProjectionType != "" {
+		media.ProjectionType = &meta.ProjectionType
+	}
+}
+
+func isPanorama(media *model.Media) bool {
+	return media.ProjectionType != nil && *media.ProjectionType == imaging.ProjectionEquirectangular
+}
+
+// tileLevels rebuilds the pyramid the processor generated from what it recorded, nil until tiling is done.
+func tileLevels(media *model.Media) []imaging.CubeLevel {
+	if media.TilesStatus == nil || *media.TilesStatus != model.MediaProcessingReady || media.TileSize == nil || media.TileFaceSize == nil {
+		return nil
+	}
+	return imaging.CubeLevels(*media.TileFaceSize*4, *media.TileSize, *media.TileFaceSize)
+}
+
+// findMedia loads a media item once the caller is allowed on the room holding it.
+func (s *mediaService) findMedia(ctx context.Context, id string, perm authz.Permission) (*model.Media, error) {
+	media, err := s.repo.FindByID(ctx, id)
+	if err != nil || media == nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeRoom(ctx, media.RoomID, perm); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// removeObject is best effort, an orphaned blob is preferable to failing the request.
+func (s *mediaService) removeObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Printf("failed to remove media object %s: %v", key, err)
+	}
+}
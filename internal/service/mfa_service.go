@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/hfleury/bk_globalshot/pkg/totp"
+)
+
+var (
+	ErrMFAAlreadyEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled           = errors.New("two-factor authentication is not enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment was not started")
+	ErrInvalidMFACode          = errors.New("invalid two-factor code")
+	// ErrMFARequired refuses to turn off a factor the user's role cannot sign in without.
+	ErrMFARequired = errors.New("two-factor authentication is required for this role")
+)
+
+const recoveryCodeCount = 10
+
+// MFAEnrollment is what the user needs to add the account to an authenticator app.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // Rendered as a QR code by the frontend
+}
+
+type MFAStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// MFAService manages the TOTP factor of a user and checks the codes they present.
+//
+//go:generate mockgen -source=mfa_service.go -destination=../../mock/services/mock_mfa_service.go -package=mock_services
+type MFAService interface {
+	Status(ctx context.Context, userID string) (*MFAStatus, error)
+	// BeginEnrollment issues a new secret. It only counts once confirmed with a valid code.
+	BeginEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error)
+	// ConfirmEnrollment enables the factor and returns the recovery codes, shown only this once.
+	ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error)
+	// RegenerateRecoveryCodes replaces every recovery code, the old ones stop working.
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	Disable(ctx context.Context, userID, code string) error
+	// IsRequired reports whether users of the role cannot sign in without a second factor.
+	IsRequired(role string) bool
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+	// Verify checks a TOTP code, or consumes a recovery code, of a user with the factor enabled.
+	Verify(ctx context.Context, userID, code string) (bool, error)
+}
+
+type mfaService struct {
+	repo   repository.MFARepository
+	users  pkgRepository.UserRepository
+	cfgMFA *config.ConfigMFA
+}
+
+func NewMFAService(repo repository.MFARepository, users pkgRepository.UserRepository, cfgMFA *config.ConfigMFA) MFAService {
+	return &mfaService{repo: repo, users: users, cfgMFA: cfgMFA}
+}
+
+func (s *mfaService) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	factor, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{Enabled: factor.IsEnabled(), Required: s.IsRequired(user.Role)}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *mfaService) BeginEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	factor, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePending(ctx, userID, secret); err != nil {
+		return nil, fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.cfgMFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	factor, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+	if factor.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(factor.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	enabled, err := s.repo.Enable(ctx, userID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if err := s.checkCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID, code string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user != nil && s.IsRequired(user.Role) {
+		return ErrMFARequired
+	}
+	if err := s.checkCode(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID)
+}
+
+func (s *mfaService) IsRequired(role string) bool {
+	for _, required := range s.cfgMFA.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	factor, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return factor.IsEnabled(), nil
+}
+
+func (s *mfaService) Verify(ctx context.Context, userID, code string) (bool, error) {
+	factor, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !factor.IsEnabled() {
+		return false, nil
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(factor.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.repo.UseStep(ctx, userID, step)
+	}
+	return s.repo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// checkCode guards changes to an enabled factor behind a fresh code, a stolen session is not enough.
+func (s *mfaService) checkCode(ctx context.Context, userID, code string) error {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrMFANotEnabled
+	}
+	ok, err := s.Verify(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// newRecoveryCode returns a code such as "k3v9q-2xw7m", 50 random bits are plenty for single use.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes with or without the dash, in any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+)
+
+// ErrPreconditionFailed is returned when the resource is no longer at the version the caller
+// read, whether it changed before the call or in the middle of it.
+var ErrPreconditionFailed = pkgRepository.ErrVersionConflict
+
+// Precondition holds the versions a change may apply to, those of an If-Match header. nil
+// accepts any version, an empty one none.
+type Precondition []int64
+
+// Check returns ErrPreconditionFailed unless version is one of those accepted.
+func (p Precondition) Check(version int64) error {
+	if p == nil {
+		return nil
+	}
+	for _, v := range p {
+		if v == version {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed
+}
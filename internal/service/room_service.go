@@ -6,46 +6,104 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
 )
 
+// RoomPatch is a JSON merge patch of a room.
+type RoomPatch struct {
+	Name      mergepatch.Value[string] `json:"name" binding:"omitnil,min=1"`
+	UnitID    mergepatch.Value[string] `json:"unit_id" binding:"omitnil,min=1"`
+	Panoramic mergepatch.Value[bool]   `json:"panoramic" binding:"omitnil"`
+}
+
 //go:generate mockgen -source=room_service.go -destination=../../mock/services/mock_room_service.go -package=mock_services
 type RoomService interface {
-	CreateRoom(ctx context.Context, name, unitID string) (*model.Room, error)
-	GetAllRooms(ctx context.Context, limit, offset int, unitID string) ([]*model.Room, int64, error)
+	// CreateRoom defaults to a panoramic room when panoramic is nil.
+	CreateRoom(ctx context.Context, name, unitID string, panoramic *bool) (*model.Room, error)
+	GetAllRooms(ctx context.Context, query listquery.Query) ([]*model.Room, int64, error)
 	GetRoomByID(ctx context.Context, id string) (*model.Room, error)
-	UpdateRoom(ctx context.Context, id string, name string, unitID string) (*model.Room, error)
-	DeleteRoom(ctx context.Context, id string) error
+	UpdateRoom(ctx context.Context, id string, name string, unitID string, panoramic *bool, pre Precondition) (*model.Room, error)
+	PatchRoom(ctx context.Context, id string, patch RoomPatch, pre Precondition) (*model.Room, error)
+	DeleteRoom(ctx context.Context, id string, pre Precondition) error
 }
 
 type roomService struct {
-	repo repository.RoomRepository
+	repo  repository.RoomRepository
+	authz Authorizer
+	audit AuditService
 }
 
-func NewRoomService(repo repository.RoomRepository) RoomService {
+func NewRoomService(repo repository.RoomRepository, authz Authorizer, audit AuditService) RoomService {
 	return &roomService{
-		repo: repo,
+		repo:  repo,
+		authz: authz,
+		audit: audit,
 	}
 }
 
-func (s *roomService) CreateRoom(ctx context.Context, name, unitID string) (*model.Room, error) {
+func (s *roomService) CreateRoom(ctx context.Context, name, unitID string, panoramic *bool) (*model.Room, error) {
+	if err := s.authz.AuthorizeUnit(ctx, unitID, authz.RoomCreate); err != nil {
+		return nil, err
+	}
 	room := &model.Room{
 		Name:      name,
 		UnitID:    unitID,
+		Panoramic: panoramic == nil || *panoramic,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	return room, s.repo.Create(ctx, room)
+	if err := s.repo.Create(ctx, room); err != nil {
+		return room, err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceRoom,
+		ResourceID:   room.ID,
+		UnitID:       room.UnitID,
+		After:        room,
+	})
+	return room, nil
 }
 
-func (s *roomService) GetAllRooms(ctx context.Context, limit, offset int, unitID string) ([]*model.Room, int64, error) {
-	return s.repo.FindAll(ctx, limit, offset, unitID)
+func (s *roomService) GetAllRooms(ctx context.Context, query listquery.Query) ([]*model.Room, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.RoomRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(ctx, query, scope)
 }
 
 func (s *roomService) GetRoomByID(ctx context.Context, id string) (*model.Room, error) {
+	if err := s.authz.AuthorizeRoom(ctx, id, authz.RoomRead); err != nil {
+		return nil, err
+	}
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *roomService) UpdateRoom(ctx context.Context, id string, name string, unitID string) (*model.Room, error) {
+func (s *roomService) UpdateRoom(ctx context.Context, id string, name string, unitID string, panoramic *bool, pre Precondition) (*model.Room, error) {
+	return s.updateRoom(ctx, id, pre, func(room *model.Room) {
+		room.Name = name
+		room.UnitID = unitID
+		if panoramic != nil {
+			room.Panoramic = *panoramic
+		}
+	})
+}
+
+func (s *roomService) PatchRoom(ctx context.Context, id string, patch RoomPatch, pre Precondition) (*model.Room, error) {
+	return s.updateRoom(ctx, id, pre, func(room *model.Room) {
+		patch.Name.Apply(&room.Name)
+		patch.UnitID.Apply(&room.UnitID)
+		patch.Panoramic.Apply(&room.Panoramic)
+	})
+}
+
+func (s *roomService) updateRoom(ctx context.Context, id string, pre Precondition, apply func(room *model.Room)) (*model.Room, error) {
+	if err := s.authz.AuthorizeRoom(ctx, id, authz.RoomUpdate); err != nil {
+		return nil, err
+	}
 	room, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -53,18 +111,37 @@ func (s *roomService) UpdateRoom(ctx context.Context, id string, name string, un
 	if room == nil {
 		return nil, nil
 	}
+	if err := pre.Check(room.Version); err != nil {
+		return nil, err
+	}
 
-	room.Name = name
-	room.UnitID = unitID
+	before := *room
+	apply(room)
+	if room.UnitID != before.UnitID {
+		if err := s.authz.AuthorizeUnit(ctx, room.UnitID, authz.RoomUpdate); err != nil {
+			return nil, err
+		}
+	}
 	room.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, room); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceRoom,
+		ResourceID:   room.ID,
+		UnitID:       room.UnitID,
+		Before:       &before,
+		After:        room,
+	})
 	return room, nil
 }
 
-func (s *roomService) DeleteRoom(ctx context.Context, id string) error {
+func (s *roomService) DeleteRoom(ctx context.Context, id string, pre Precondition) error {
+	if err := s.authz.AuthorizeRoom(ctx, id, authz.RoomDelete); err != nil {
+		return err
+	}
 	room, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -72,5 +149,18 @@ func (s *roomService) DeleteRoom(ctx context.Context, id string) error {
 	if room == nil {
 		return nil
 	}
-	return s.repo.Delete(ctx, id)
+	if err := pre.Check(room.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, room.Version); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceRoom,
+		ResourceID:   room.ID,
+		UnitID:       room.UnitID,
+		Before:       room,
+	})
+	return nil
 }
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+// searchPermissions is the permission each type of search result is read with.
+var searchPermissions = map[model.SearchResultType]authz.Permission{
+	model.SearchResultSite: authz.SiteRead,
+	model.SearchResultUnit: authz.UnitRead,
+	model.SearchResultRoom: authz.RoomRead,
+	model.SearchResultUser: authz.UserRead,
+}
+
+// SearchService finds sites, units, rooms and users by name, address or email, among what
+// the caller may read.
+//
+//go:generate mockgen -source=search_service.go -destination=../../mock/services/mock_search_service.go -package=mock_services
+type SearchService interface {
+	// Search looks into the given types, every type when none is given. Types the caller
+	// cannot read are left out, it is ErrForbidden only when none is left.
+	Search(ctx context.Context, text string, types []model.SearchResultType, limit int) ([]*model.SearchResult, error)
+}
+
+type searchService struct {
+	repo  repository.SearchRepository
+	authz Authorizer
+}
+
+func NewSearchService(repo repository.SearchRepository, authz Authorizer) SearchService {
+	return &searchService{repo: repo, authz: authz}
+}
+
+func (s *searchService) Search(ctx context.Context, text string, types []model.SearchResultType, limit int) ([]*model.SearchResult, error) {
+	if len(types) == 0 {
+		types = model.SearchResultTypes
+	}
+
+	scopes := make(map[model.SearchResultType]repository.TenantScope, len(types))
+	for _, t := range types {
+		scope, err := s.authz.Scope(ctx, searchPermissions[t])
+		if errors.Is(err, ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scopes[t] = scope
+	}
+	if len(scopes) == 0 {
+		return nil, ErrForbidden
+	}
+
+	return s.repo.Search(ctx, repository.SearchQuery{Text: text, Scopes: scopes, Limit: limit})
+}
@@ -8,30 +8,48 @@ import (
 	"github.com/google/uuid"
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
 )
 
+// SitePatch is a JSON merge patch of a site.
+type SitePatch struct {
+	Name    mergepatch.Value[string] `json:"name" binding:"omitnil,min=1"`
+	Address mergepatch.Value[string] `json:"address" binding:"omitnil"`
+}
+
 type SiteService interface {
 	CreateSite(ctx context.Context, name, address, companyID string) (*model.Site, error)
-	GetAllSites(ctx context.Context, limit, offset int) ([]*model.Site, int64, error)
+	GetAllSites(ctx context.Context, query listquery.Query) ([]*model.Site, int64, error)
 	GetSiteByID(ctx context.Context, id string) (*model.Site, error)
-	UpdateSite(ctx context.Context, id, name, address string) (*model.Site, error)
-	DeleteSite(ctx context.Context, id string) error
+	UpdateSite(ctx context.Context, id, name, address string, pre Precondition) (*model.Site, error)
+	PatchSite(ctx context.Context, id string, patch SitePatch, pre Precondition) (*model.Site, error)
+	DeleteSite(ctx context.Context, id string, pre Precondition) error
 }
 
 type siteService struct {
-	db   db.Db
-	repo repository.SiteRepository
+	db    db.Db
+	repo  repository.SiteRepository
+	authz Authorizer
+	audit AuditService
 }
 
-func NewSiteService(db db.Db, repo repository.SiteRepository) SiteService {
+func NewSiteService(db db.Db, repo repository.SiteRepository, authz Authorizer, audit AuditService) SiteService {
 	return &siteService{
-		db:   db,
-		repo: repo,
+		db:    db,
+		repo:  repo,
+		authz: authz,
+		audit: audit,
 	}
 }
 
 func (s *siteService) CreateSite(ctx context.Context, name, address, companyID string) (*model.Site, error) {
+	if err := s.authz.AuthorizeCompany(ctx, companyID, authz.SiteCreate); err != nil {
+		return nil, err
+	}
+
 	site := &model.Site{
 		ID:        uuid.New().String(),
 		Name:      name,
@@ -44,47 +62,50 @@ func (s *siteService) CreateSite(ctx context.Context, name, address, companyID s
 	if err := s.repo.Create(ctx, site); err != nil {
 		return nil, fmt.Errorf("failed to create site: %w", err)
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceSite,
+		ResourceID:   site.ID,
+		CompanyID:    site.CompanyID,
+		After:        site,
+	})
 	return site, nil
 }
 
-func (s *siteService) GetAllSites(ctx context.Context, limit, offset int) ([]*model.Site, int64, error) {
-	// Extract relevant user info from context, assuming it was populated by handler from token payload or similar.
-	// We'll look for individual keys to avoid strict struct dependency which can be brittle,
-	// or we can stick to the model User if we ensure it's populated.
-	// Given the previous panic, let's try to get the user safely.
-
-	// FIX: The handler now populates a partial User model from the token payload.
-	val := ctx.Value("user")
-	if val == nil {
-		return nil, 0, fmt.Errorf("user context missing")
-	}
-	user, ok := val.(*model.User)
-	if !ok {
-		return nil, 0, fmt.Errorf("invalid user context type")
+func (s *siteService) GetAllSites(ctx context.Context, query listquery.Query) ([]*model.Site, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.SiteRead)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if user.Role == string(model.RoleCustomer) {
-		// Ensure we have an ID for the customer
-		if user.ID == "" {
-			// If ID is missing from token (it shouldn't be), we can't filter by customer
-			return nil, 0, fmt.Errorf("customer ID missing from context")
-		}
-		return s.repo.FindAllByCustomerID(ctx, limit, offset, user.ID)
-	}
+	return s.repo.FindAll(ctx, query, scope)
+}
 
-	// For Admin, if they don't have a CompanyID (e.g. Super Admin), return all sites.
-	if user.Role == string(model.RoleAdmin) && user.CompanyID == "" {
-		return s.repo.FindAll(ctx, limit, offset)
+func (s *siteService) GetSiteByID(ctx context.Context, id string) (*model.Site, error) {
+	if err := s.authz.AuthorizeSite(ctx, id, authz.SiteRead); err != nil {
+		return nil, err
 	}
+	return s.repo.FindByID(ctx, id)
+}
 
-	return s.repo.FindAllByCompanyID(ctx, limit, offset, user.CompanyID)
+func (s *siteService) UpdateSite(ctx context.Context, id, name, address string, pre Precondition) (*model.Site, error) {
+	return s.updateSite(ctx, id, pre, func(site *model.Site) {
+		site.Name = name
+		site.Address = address
+	})
 }
 
-func (s *siteService) GetSiteByID(ctx context.Context, id string) (*model.Site, error) {
-	return s.repo.FindByID(ctx, id)
+func (s *siteService) PatchSite(ctx context.Context, id string, patch SitePatch, pre Precondition) (*model.Site, error) {
+	return s.updateSite(ctx, id, pre, func(site *model.Site) {
+		patch.Name.Apply(&site.Name)
+		patch.Address.Apply(&site.Address)
+	})
 }
 
-func (s *siteService) UpdateSite(ctx context.Context, id, name, address string) (*model.Site, error) {
+func (s *siteService) updateSite(ctx context.Context, id string, pre Precondition, apply func(site *model.Site)) (*model.Site, error) {
+	if err := s.authz.AuthorizeSite(ctx, id, authz.SiteUpdate); err != nil {
+		return nil, err
+	}
 	site, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -92,18 +113,32 @@ func (s *siteService) UpdateSite(ctx context.Context, id, name, address string)
 	if site == nil {
 		return nil, nil // Not found
 	}
+	if err := pre.Check(site.Version); err != nil {
+		return nil, err
+	}
 
-	site.Name = name
-	site.Address = address
+	before := *site
+	apply(site)
 	site.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, site); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceSite,
+		ResourceID:   site.ID,
+		CompanyID:    site.CompanyID,
+		Before:       &before,
+		After:        site,
+	})
 	return site, nil
 }
 
-func (s *siteService) DeleteSite(ctx context.Context, id string) error {
+func (s *siteService) DeleteSite(ctx context.Context, id string, pre Precondition) error {
+	if err := s.authz.AuthorizeSite(ctx, id, authz.SiteDelete); err != nil {
+		return err
+	}
 	site, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -111,5 +146,18 @@ func (s *siteService) DeleteSite(ctx context.Context, id string) error {
 	if site == nil {
 		return nil
 	}
-	return s.repo.Delete(ctx, id)
+	if err := pre.Check(site.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, site.Version); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceSite,
+		ResourceID:   site.ID,
+		CompanyID:    site.CompanyID,
+		Before:       site,
+	})
+	return nil
 }
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/oidc"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
+	"github.com/hfleury/bk_globalshot/pkg/secretbox"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrSSONotConfigured    = errors.New("single sign-on is not configured for this company")
+	ErrInvalidSSOSettings  = errors.New("identity provider settings are incomplete or unreachable")
+	ErrInvalidSSOState     = errors.New("single sign-on state is invalid or expired")
+	ErrSSOLoginFailed      = errors.New("identity provider did not confirm the login")
+	ErrSSOEmailNotVerified = errors.New("identity provider has not verified the email")
+	ErrSSOAccountConflict  = errors.New("email belongs to an account outside of this company")
+)
+
+// SSOService signs in the staff of a company through the OpenID Connect provider the company
+// configured. Users unknown to the API are created on their first login, as company users.
+//
+//go:generate mockgen -source=sso_service.go -destination=../../mock/services/mock_sso_service.go -package=mock_services
+type SSOService interface {
+	// ConfigureCompany stores the provider of a company, after checking it answers discovery.
+	ConfigureCompany(ctx context.Context, companyID, issuer, clientID, clientSecret string) (*model.Company, error)
+	DisableCompany(ctx context.Context, companyID string) (*model.Company, error)
+	// BeginLogin returns where to send the browser to sign in with the identity provider.
+	BeginLogin(ctx context.Context, companyID string) (*SSOLogin, error)
+	// CompleteLogin takes what the provider sent back to the redirect URL and signs the user in,
+	// or returns the challenge to answer when a second factor is due.
+	CompleteLogin(ctx context.Context, state, code string, client ClientInfo) (*LoginResult, error)
+}
+
+// SSOLogin is a login started with an identity provider.
+type SSOLogin struct {
+	AuthorizationURL string
+	// State comes back from the provider along with the code. The browser starting the login
+	// keeps it too, so that a login cannot be completed in someone else's browser.
+	State string
+}
+
+type ssoService struct {
+	db         db.Db
+	sso        repository.SSORepository
+	companies  repository.CompanyRepository
+	users      pkgRepository.UserRepository
+	auth       AuthService
+	authz      Authorizer
+	audit      AuditService
+	cfgOIDC    *config.ConfigOIDC
+	secrets    *secretbox.Box // Seals the client secrets stored with the companies
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	clients map[string]*oidc.Client // By company, discovery and signing keys are cached with them
+}
+
+func NewSSOService(
+	db db.Db,
+	sso repository.SSORepository,
+	companies repository.CompanyRepository,
+	users pkgRepository.UserRepository,
+	auth AuthService,
+	authz Authorizer,
+	audit AuditService,
+	cfgOIDC *config.ConfigOIDC,
+	secrets *secretbox.Box,
+	httpClient *http.Client,
+) SSOService {
+	return &ssoService{
+		db:         db,
+		sso:        sso,
+		companies:  companies,
+		users:      users,
+		auth:       auth,
+		authz:      authz,
+		audit:      audit,
+		cfgOIDC:    cfgOIDC,
+		secrets:    secrets,
+		httpClient: httpClient,
+		clients:    make(map[string]*oidc.Client),
+	}
+}
+
+func (s *ssoService) ConfigureCompany(ctx context.Context, companyID, issuer, clientID, clientSecret string) (*model.Company, error) {
+	if err := s.authz.AuthorizeCompany(ctx, companyID, authz.CompanyUpdate); err != nil {
+		return nil, err
+	}
+	company, err := s.companies.FindByID(ctx, companyID)
+	if err != nil || company == nil {
+		return nil, err
+	}
+
+	issuer = strings.TrimSpace(issuer)
+	if issuer == "" || clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidSSOSettings
+	}
+	if _, err := oidc.Discover(ctx, s.httpClient, issuer); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSOSettings, err)
+	}
+
+	sealedSecret, err := s.secrets.Seal(clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *company
+	company.OIDCIssuer = &issuer
+	company.OIDCClientID = &clientID
+	company.OIDCClientSecret = &sealedSecret
+	if err := s.companies.UpdateSSO(ctx, company); err != nil {
+		return nil, err
+	}
+	s.forgetClient(companyID)
+	s.auditCompany(ctx, &before, company)
+	return company, nil
+}
+
+func (s *ssoService) DisableCompany(ctx context.Context, companyID string) (*model.Company, error) {
+	if err := s.authz.AuthorizeCompany(ctx, companyID, authz.CompanyUpdate); err != nil {
+		return nil, err
+	}
+	company, err := s.companies.FindByID(ctx, companyID)
+	if err != nil || company == nil {
+		return nil, err
+	}
+
+	before := *company
+	company.OIDCIssuer = nil
+	company.OIDCClientID = nil
+	company.OIDCClientSecret = nil
+	if err := s.companies.UpdateSSO(ctx, company); err != nil {
+		return nil, err
+	}
+	s.forgetClient(companyID)
+	s.auditCompany(ctx, &before, company)
+	return company, nil
+}
+
+// auditCompany records a change of provider, the client secret never shows in the trail.
+func (s *ssoService) auditCompany(ctx context.Context, before, after *model.Company) {
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceCompany,
+		ResourceID:   after.ID,
+		CompanyID:    after.ID,
+		Before:       before,
+		After:        after,
+	})
+}
+
+func (s *ssoService) BeginLogin(ctx context.Context, companyID string) (*SSOLogin, error) {
+	company, err := s.companies.FindByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil || !company.SSOEnabled() {
+		return nil, ErrSSONotConfigured
+	}
+	client, err := s.client(ctx, company)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	loginState := &model.OIDCLoginState{
+		StateHash:    hashToken(state),
+		CompanyID:    company.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(s.cfgOIDC.StateExpiry),
+		CreatedAt:    now,
+	}
+	if err := s.sso.CreateState(ctx, loginState); err != nil {
+		return nil, fmt.Errorf("failed to store login state: %w", err)
+	}
+	return &SSOLogin{AuthorizationURL: client.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier)), State: state}, nil
+}
+
+func (s *ssoService) CompleteLogin(ctx context.Context, state, code string, clientInfo ClientInfo) (*LoginResult, error) {
+	loginState, err := s.sso.ConsumeState(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil || !time.Now().Before(loginState.ExpiresAt) {
+		return nil, ErrInvalidSSOState
+	}
+
+	company, err := s.companies.FindByID(ctx, loginState.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil || !company.SSOEnabled() {
+		return nil, ErrSSONotConfigured
+	}
+	client, err := s.client(ctx, company)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := client.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrExchange) || errors.Is(err, oidc.ErrInvalidIDToken) || errors.Is(err, oidc.ErrUnknownSigningKey) {
+			return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+		}
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, company, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.auth.LoginWithIdentity(ctx, user, clientInfo)
+}
+
+// resolveUser finds the user an identity belongs to, linking or creating the account on the
+// first login. Only company users of the company can sign in through its provider.
+func (s *ssoService) resolveUser(ctx context.Context, company *model.Company, claims *oidc.Claims) (*model.User, error) {
+	identity, err := s.sso.FindIdentity(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.users.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || !isCompanyMember(user, company.ID) {
+			return nil, ErrSSOAccountConflict
+		}
+		return user, nil
+	}
+
+	// Matching on the email hands the account over, only take the provider's word when it
+	// vouches for the address
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		return nil, ErrSSOEmailNotVerified
+	}
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil && !isCompanyMember(user, company.ID) {
+		return nil, ErrSSOAccountConflict
+	}
+	return s.linkIdentity(ctx, user, company, email, claims)
+}
+
+// linkIdentity records the identity, creating the user first when there is none.
+func (s *ssoService) linkIdentity(ctx context.Context, user *model.User, company *model.Company, email string, claims *oidc.Claims) (*model.User, error) {
+	tx, err := s.db.BegrinTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	txAdapter := db.NewTxAdapter(tx.(*sql.Tx))
+	txUsers := s.users.WithTx(txAdapter)
+	txSSO := s.sso.WithTx(txAdapter)
+
+	created := user == nil
+	if created {
+		// Nobody knows this password, the account signs in through the provider or a reset
+		password, err := oidc.RandomString()
+		if err != nil {
+			s.db.Rollback(ctx, tx)
+			return nil, err
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			s.db.Rollback(ctx, tx)
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		user = &model.User{
+			ID:        uuid.New().String(),
+			Email:     email,
+			Password:  string(hashedPassword),
+			Role:      string(model.RoleCompany),
+			CompanyID: company.ID,
+		}
+		if err := txUsers.Create(ctx, user); err != nil {
+			s.db.Rollback(ctx, tx)
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	}
+
+	identity := &model.UserIdentity{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := txSSO.CreateIdentity(ctx, identity); err != nil {
+		s.db.Rollback(ctx, tx)
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	if err := s.db.Commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if created {
+		// Nobody is signed in yet, the account is created by its own first login
+		s.audit.Record(model.ContextWithUser(ctx, user), AuditEntry{
+			Action:       model.AuditActionCreate,
+			ResourceType: model.AuditResourceUser,
+			ResourceID:   user.ID,
+			CompanyID:    user.CompanyID,
+			After:        user,
+		})
+	}
+	return user, nil
+}
+
+// client returns the relying party for the company, discovering its provider the first time.
+func (s *ssoService) client(ctx context.Context, company *model.Company) (*oidc.Client, error) {
+	clientSecret, err := s.secrets.Open(*company.OIDCClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open client secret: %w", err)
+	}
+
+	s.mu.Lock()
+	client, ok := s.clients[company.ID]
+	s.mu.Unlock()
+	if ok && client.Provider.Issuer == *company.OIDCIssuer &&
+		client.ClientID == *company.OIDCClientID && client.ClientSecret == clientSecret {
+		return client, nil
+	}
+
+	provider, err := oidc.Discover(ctx, s.httpClient, *company.OIDCIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+	client = &oidc.Client{
+		Provider:     provider,
+		ClientID:     *company.OIDCClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  s.cfgOIDC.RedirectURL,
+		HTTPClient:   s.httpClient,
+	}
+
+	s.mu.Lock()
+	s.clients[company.ID] = client
+	s.mu.Unlock()
+	return client, nil
+}
+
+func (s *ssoService) forgetClient(companyID string) {
+	s.mu.Lock()
+	delete(s.clients, companyID)
+	s.mu.Unlock()
+}
+
+func isCompanyMember(user *model.User, companyID string) bool {
+	return user.Role == string(model.RoleCompany) && user.CompanyID == companyID
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/storage"
+)
+
+var ErrTrashParentDeleted = errors.New("the parent is in the trash too and has to be restored first")
+
+// trashRestorePermissions is what restoring each type takes: whoever may delete it may
+// bring it back.
+var trashRestorePermissions = map[model.TrashItemType]authz.Permission{
+	model.TrashItemCompany: authz.CompanyDelete,
+	model.TrashItemSite:    authz.SiteDelete,
+	model.TrashItemUnit:    authz.UnitDelete,
+	model.TrashItemRoom:    authz.RoomDelete,
+}
+
+// TrashService keeps deleted companies, sites, units and rooms restorable for the retention
+// period, then deletes them for good along with their media files.
+//
+//go:generate mockgen -source=trash_service.go -destination=../../mock/services/mock_trash_service.go -package=mock_services
+type TrashService interface {
+	GetTrash(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error)
+	// Restore returns the item it took out of the trash, nil when it is not in the trash.
+	Restore(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error)
+	// Purge deletes for good what stayed in the trash longer than the retention period.
+	Purge(ctx context.Context) error
+	// Start purges every PurgeInterval in the background until ctx is done.
+	Start(ctx context.Context)
+}
+
+type trashService struct {
+	repo    repository.TrashRepository
+	storage storage.Storage
+	authz   Authorizer
+	audit   AuditService
+	cfg     *config.ConfigTrash
+}
+
+func NewTrashService(repo repository.TrashRepository, storage storage.Storage, authz Authorizer, audit AuditService, cfg *config.ConfigTrash) TrashService {
+	return &trashService{repo: repo, storage: storage, authz: authz, audit: audit, cfg: cfg}
+}
+
+func (s *trashService) GetTrash(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.TrashRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scope.CustomerID != "" {
+		return nil, 0, ErrForbidden
+	}
+	if scope.CompanyID != "" {
+		query = query.With("company_id", scope.CompanyID)
+	}
+
+	items, total, err := s.repo.FindAll(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, item := range items {
+		item.PurgeAt = item.DeletedAt.Add(s.cfg.Retention)
+	}
+	return items, total, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error) {
+	perm, ok := trashRestorePermissions[itemType]
+	if !ok {
+		return nil, nil
+	}
+	item, err := s.repo.FindByID(ctx, itemType, id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeCompany(ctx, item.CompanyID, perm); err != nil {
+		return nil, err
+	}
+	if !item.Restorable {
+		return nil, ErrTrashParentDeleted
+	}
+
+	if err := s.repo.Restore(ctx, itemType, id); err != nil {
+		return nil, err
+	}
+	item.PurgeAt = item.DeletedAt.Add(s.cfg.Retention)
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionRestore,
+		ResourceType: string(itemType),
+		ResourceID:   item.ID,
+		CompanyID:    item.CompanyID,
+		Before:       map[string]interface{}{"deleted_at": item.DeletedAt},
+		After:        map[string]interface{}{"deleted_at": nil},
+	})
+	return item, nil
+}
+
+func (s *trashService) Purge(ctx context.Context) error {
+	purged, err := s.repo.Purge(ctx, time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	// Everything of a room, originals and renditions alike, is stored under its folder
+	for _, roomID := range purged.RoomIDs {
+		objects, err := s.storage.List(ctx, path.Join("rooms", roomID)+"/")
+		if err != nil {
+			log.Printf("failed to list files of purged room %s: %v", roomID, err)
+			continue
+		}
+		for _, obj := range objects {
+			if err := s.storage.Delete(ctx, obj.Key); err != nil {
+				log.Printf("failed to remove media object %s: %v", obj.Key, err)
+			}
+		}
+	}
+
+	if len(purged.RoomIDs) > 0 || purged.Units > 0 || purged.Sites > 0 || purged.Companies > 0 {
+		log.Printf("purged trash: %d companies, %d sites, %d units, %d rooms",
+			purged.Companies, purged.Sites, purged.Units, len(purged.RoomIDs))
+	}
+	return nil
+}
+
+func (s *trashService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Purge(ctx); err != nil {
+					log.Printf("failed to purge trash: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -8,38 +8,75 @@ import (
 	"github.com/google/uuid"
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/internal/repository"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
+	pkgRepository "github.com/hfleury/bk_globalshot/pkg/repository"
 )
 
 type BatchCreateUnitItem struct {
-	Name     string  `json:"name"`
-	Type     string  `json:"type"`
-	SiteID   string  `json:"site_id"`
+	Name     string  `json:"name" binding:"required"`
+	Type     string  `json:"type" binding:"required"`
+	SiteID   string  `json:"site_id" binding:"required"`
 	ClientID *string `json:"client_id"`
 }
 
+// UnitPatch is a JSON merge patch of a unit, bound straight from the request body. Sending
+// "client_id": null unassigns the customer.
+type UnitPatch struct {
+	Name     mergepatch.Value[string]    `json:"name" binding:"omitnil,min=1"`
+	Type     mergepatch.Value[string]    `json:"type" binding:"omitnil,min=1"`
+	SiteID   mergepatch.Value[string]    `json:"site_id" binding:"omitnil,min=1"`
+	ClientID mergepatch.Nullable[string] `json:"client_id"`
+}
+
 type UnitService interface {
 	CreateUnit(ctx context.Context, name string, unitType string, siteID string, clientID *string) (*model.Unit, error)
 	BatchCreateUnits(ctx context.Context, items []BatchCreateUnitItem) ([]*model.Unit, error)
-	GetAllUnits(ctx context.Context, limit, offset int) ([]*model.Unit, int64, error)
+	GetAllUnits(ctx context.Context, query listquery.Query) ([]*model.Unit, int64, error)
 	GetUnitByID(ctx context.Context, id string) (*model.Unit, error)
-	UpdateUnit(ctx context.Context, id, name, unitType, siteID string, clientID *string) (*model.Unit, error)
-	DeleteUnit(ctx context.Context, id string) error
+	// UpdateUnit and DeleteUnit return ErrPreconditionFailed when the unit is not at a version
+	// pre accepts.
+	UpdateUnit(ctx context.Context, id, name, unitType, siteID string, clientID *string, pre Precondition) (*model.Unit, error)
+	// PatchUnit changes only what the patch sends.
+	PatchUnit(ctx context.Context, id string, patch UnitPatch, pre Precondition) (*model.Unit, error)
+	DeleteUnit(ctx context.Context, id string, pre Precondition) error
 }
 
 type unitService struct {
-	db   db.Db
-	repo repository.UnitRepository
+	db      db.Db
+	repo    repository.UnitRepository
+	users   pkgRepository.UserRepository
+	tenants repository.TenantRepository
+	authz   Authorizer
+	audit   AuditService
 }
 
-func NewUnitService(db db.Db, repo repository.UnitRepository) UnitService {
+func NewUnitService(db db.Db, repo repository.UnitRepository, users pkgRepository.UserRepository, tenants repository.TenantRepository, authz Authorizer, audit AuditService) UnitService {
 	return &unitService{
-		db:   db,
-		repo: repo,
+		db:      db,
+		repo:    repo,
+		users:   users,
+		tenants: tenants,
+		authz:   authz,
+		audit:   audit,
 	}
 }
 
 func (s *unitService) CreateUnit(ctx context.Context, name string, unitType string, siteID string, clientID *string) (*model.Unit, error) {
+	if !model.IsValidUnitType(unitType) {
+		return nil, ValidationError{{Field: "type", Message: unitTypeMessage}}
+	}
+	if err := s.authz.AuthorizeSite(ctx, siteID, authz.UnitCreate); err != nil {
+		return nil, err
+	}
+	if ok, err := s.isValidClient(ctx, siteID, clientID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ValidationError{{Field: "client_id", Message: clientMessage}}
+	}
+
 	unit := &model.Unit{
 		ID:        uuid.New().String(),
 		Name:      name,
@@ -53,6 +90,7 @@ func (s *unitService) CreateUnit(ctx context.Context, name string, unitType stri
 	if err := s.repo.Create(ctx, unit); err != nil {
 		return nil, fmt.Errorf("failed to create unit: %w", err)
 	}
+	s.auditCreated(ctx, unit)
 	return unit, nil
 }
 
@@ -61,6 +99,40 @@ func (s *unitService) BatchCreateUnits(ctx context.Context, items []BatchCreateU
 		return []*model.Unit{}, nil
 	}
 
+	var invalid ValidationError
+	for i, item := range items {
+		if !model.IsValidUnitType(item.Type) {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("[%d].type", i), Message: unitTypeMessage})
+		}
+	}
+	if invalid != nil {
+		return nil, invalid
+	}
+
+	// Every target site has to be writable, a batch cannot smuggle units into another tenant
+	checked := make(map[string]bool)
+	for _, item := range items {
+		if checked[item.SiteID] {
+			continue
+		}
+		if err := s.authz.AuthorizeSite(ctx, item.SiteID, authz.UnitCreate); err != nil {
+			return nil, err
+		}
+		checked[item.SiteID] = true
+	}
+	for i, item := range items {
+		ok, err := s.isValidClient(ctx, item.SiteID, item.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("[%d].client_id", i), Message: clientMessage})
+		}
+	}
+	if invalid != nil {
+		return nil, invalid
+	}
+
 	units := make([]*model.Unit, len(items))
 	now := time.Now()
 
@@ -79,19 +151,53 @@ func (s *unitService) BatchCreateUnits(ctx context.Context, items []BatchCreateU
 	if err := s.repo.BatchCreate(ctx, units); err != nil {
 		return nil, fmt.Errorf("failed to batch create units: %w", err)
 	}
+	for _, unit := range units {
+		s.auditCreated(ctx, unit)
+	}
 
 	return units, nil
 }
 
-func (s *unitService) GetAllUnits(ctx context.Context, limit, offset int) ([]*model.Unit, int64, error) {
-	return s.repo.FindAll(ctx, limit, offset)
+func (s *unitService) GetAllUnits(ctx context.Context, query listquery.Query) ([]*model.Unit, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.UnitRead)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(ctx, query, scope)
 }
 
 func (s *unitService) GetUnitByID(ctx context.Context, id string) (*model.Unit, error) {
+	if err := s.authz.AuthorizeUnit(ctx, id, authz.UnitRead); err != nil {
+		return nil, err
+	}
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *unitService) UpdateUnit(ctx context.Context, id, name, unitType, siteID string, clientID *string) (*model.Unit, error) {
+func (s *unitService) UpdateUnit(ctx context.Context, id, name, unitType, siteID string, clientID *string, pre Precondition) (*model.Unit, error) {
+	return s.updateUnit(ctx, id, pre, func(unit *model.Unit) {
+		unit.Name = name
+		unit.Type = model.UnitType(unitType)
+		unit.SiteID = siteID
+		unit.ClientID = clientID
+	})
+}
+
+func (s *unitService) PatchUnit(ctx context.Context, id string, patch UnitPatch, pre Precondition) (*model.Unit, error) {
+	return s.updateUnit(ctx, id, pre, func(unit *model.Unit) {
+		patch.Name.Apply(&unit.Name)
+		if patch.Type.Set {
+			unit.Type = model.UnitType(patch.Type.Value)
+		}
+		patch.SiteID.Apply(&unit.SiteID)
+		patch.ClientID.ApplyPtr(&unit.ClientID)
+	})
+}
+
+// updateUnit saves the changes apply makes to the unit, nil when it is not found.
+func (s *unitService) updateUnit(ctx context.Context, id string, pre Precondition, apply func(unit *model.Unit)) (*model.Unit, error) {
+	if err := s.authz.AuthorizeUnit(ctx, id, authz.UnitUpdate); err != nil {
+		return nil, err
+	}
 	unit, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -99,20 +205,50 @@ func (s *unitService) UpdateUnit(ctx context.Context, id, name, unitType, siteID
 	if unit == nil {
 		return nil, nil // Not found
 	}
+	if err := pre.Check(unit.Version); err != nil {
+		return nil, err
+	}
 
-	unit.Name = name
-	unit.Type = model.UnitType(unitType)
-	unit.SiteID = siteID
-	unit.ClientID = clientID
+	before := *unit
+	apply(unit)
+	if unit.Type != before.Type && !model.IsValidUnitType(string(unit.Type)) {
+		return nil, ValidationError{{Field: "type", Message: unitTypeMessage}}
+	}
+	if unit.SiteID != before.SiteID {
+		// Moving the unit needs write access on the site it lands on as well
+		if err := s.authz.AuthorizeSite(ctx, unit.SiteID, authz.UnitUpdate); err != nil {
+			return nil, err
+		}
+	}
+	if !equalClient(unit.ClientID, before.ClientID) || (unit.SiteID != before.SiteID && unit.ClientID != nil) {
+		ok, err := s.isValidClient(ctx, unit.SiteID, unit.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ValidationError{{Field: "client_id", Message: clientMessage}}
+		}
+	}
 	unit.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, unit); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceUnit,
+		ResourceID:   unit.ID,
+		SiteID:       unit.SiteID,
+		Before:       &before,
+		After:        unit,
+	})
 	return unit, nil
 }
 
-func (s *unitService) DeleteUnit(ctx context.Context, id string) error {
+func (s *unitService) DeleteUnit(ctx context.Context, id string, pre Precondition) error {
+	if err := s.authz.AuthorizeUnit(ctx, id, authz.UnitDelete); err != nil {
+		return err
+	}
 	unit, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -120,5 +256,62 @@ func (s *unitService) DeleteUnit(ctx context.Context, id string) error {
 	if unit == nil {
 		return nil
 	}
-	return s.repo.Delete(ctx, id)
+	if err := pre.Check(unit.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, unit.Version); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceUnit,
+		ResourceID:   unit.ID,
+		SiteID:       unit.SiteID,
+		Before:       unit,
+	})
+	return nil
+}
+
+// isValidClient reports whether a unit on the site may be assigned to the client: a customer
+// of the company that owns the site, or one not attached to any company yet. No client is valid.
+func (s *unitService) isValidClient(ctx context.Context, siteID string, clientID *string) (bool, error) {
+	if clientID == nil {
+		return true, nil
+	}
+	if _, err := uuid.Parse(*clientID); err != nil {
+		return false, nil
+	}
+	client, err := s.users.FindByID(ctx, *clientID)
+	if err != nil {
+		return false, err
+	}
+	if client == nil || client.Role != string(model.RoleCustomer) {
+		return false, nil
+	}
+	if client.CompanyID == "" {
+		return true, nil
+	}
+	owner, err := s.tenants.SiteOwnership(ctx, siteID)
+	if err != nil {
+		return false, err
+	}
+	// A missing site is reported on site_id when the unit is saved
+	return owner == nil || owner.CompanyID == client.CompanyID, nil
+}
+
+func equalClient(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *unitService) auditCreated(ctx context.Context, unit *model.Unit) {
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceUnit,
+		ResourceID:   unit.ID,
+		SiteID:       unit.SiteID,
+		After:        unit,
+	})
 }
@@ -6,29 +6,52 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/hfleury/bk_globalshot/pkg/authz"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
+	"github.com/hfleury/bk_globalshot/pkg/mergepatch"
 	"github.com/hfleury/bk_globalshot/pkg/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UserPatch is a JSON merge patch of a user. Sending "company_id": null detaches the user
+// from its company.
+type UserPatch struct {
+	Email     mergepatch.Value[string]    `json:"email" binding:"omitnil,email"`
+	Role      mergepatch.Value[string]    `json:"role" binding:"omitnil,min=1"`
+	CompanyID mergepatch.Nullable[string] `json:"company_id"`
+}
+
 type UserService interface {
 	CreateUser(ctx context.Context, email, password, role string, companyID string) (*model.User, error)
-	GetAllUsers(ctx context.Context, limit, offset int) ([]*model.User, int64, error)
+	GetAllUsers(ctx context.Context, query listquery.Query) ([]*model.User, int64, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
-	UpdateUser(ctx context.Context, id, email, role string, companyID string) (*model.User, error)
-	DeleteUser(ctx context.Context, id string) error
+	UpdateUser(ctx context.Context, id, email, role string, companyID string, pre Precondition) (*model.User, error)
+	PatchUser(ctx context.Context, id string, patch UserPatch, pre Precondition) (*model.User, error)
+	DeleteUser(ctx context.Context, id string, pre Precondition) error
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo  repository.UserRepository
+	authz Authorizer
+	audit AuditService
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(repo repository.UserRepository, authz Authorizer, audit AuditService) UserService {
 	return &userService{
-		repo: repo,
+		repo:  repo,
+		authz: authz,
+		audit: audit,
 	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, email, password, role string, companyID string) (*model.User, error) {
+	if !model.IsValidRole(role) {
+		return nil, ValidationError{{Field: "role", Message: roleMessage}}
+	}
+	if err := s.authz.AuthorizeUser(ctx, &model.User{Role: role, CompanyID: companyID}, authz.UserCreate); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
@@ -45,19 +68,57 @@ func (s *userService) CreateUser(ctx context.Context, email, password, role stri
 	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionCreate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    user.CompanyID,
+		After:        user,
+	})
 
 	return user, nil
 }
 
-func (s *userService) GetAllUsers(ctx context.Context, limit, offset int) ([]*model.User, int64, error) {
-	return s.repo.FindAll(ctx, limit, offset)
+func (s *userService) GetAllUsers(ctx context.Context, query listquery.Query) ([]*model.User, int64, error) {
+	scope, err := s.authz.Scope(ctx, authz.UserRead)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if scope.CustomerID != "" {
+		return nil, 0, ErrForbidden // Customers only ever see themselves, through /me
+	}
+	return s.repo.FindAll(ctx, query, scope.CompanyID)
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
-	return s.repo.FindByID(ctx, id)
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeUser(ctx, user, authz.UserRead); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-func (s *userService) UpdateUser(ctx context.Context, id, email, role string, companyID string) (*model.User, error) {
+func (s *userService) UpdateUser(ctx context.Context, id, email, role string, companyID string, pre Precondition) (*model.User, error) {
+	return s.updateUser(ctx, id, pre, func(user *model.User) {
+		user.Email = email
+		user.Role = role
+		user.CompanyID = companyID
+	})
+}
+
+func (s *userService) PatchUser(ctx context.Context, id string, patch UserPatch, pre Precondition) (*model.User, error) {
+	return s.updateUser(ctx, id, pre, func(user *model.User) {
+		patch.Email.Apply(&user.Email)
+		patch.Role.Apply(&user.Role)
+		patch.CompanyID.Apply(&user.CompanyID)
+	})
+}
+
+func (s *userService) updateUser(ctx context.Context, id string, pre Precondition, apply func(user *model.User)) (*model.User, error) {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -65,18 +126,38 @@ func (s *userService) UpdateUser(ctx context.Context, id, email, role string, co
 	if user == nil {
 		return nil, nil // Not found
 	}
+	if err := s.authz.AuthorizeUser(ctx, user, authz.UserUpdate); err != nil {
+		return nil, err
+	}
+	if err := pre.Check(user.Version); err != nil {
+		return nil, err
+	}
 
-	user.Email = email
-	user.Role = role
-	user.CompanyID = companyID
+	before := *user
+	apply(user)
+	if user.Role != before.Role && !model.IsValidRole(user.Role) {
+		return nil, ValidationError{{Field: "role", Message: roleMessage}}
+	}
+	// The account must stay within reach after the change too, no moving it to another tenant
+	if err := s.authz.AuthorizeUser(ctx, user, authz.UserUpdate); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Update(ctx, user); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    before.CompanyID,
+		Before:       &before,
+		After:        user,
+	})
 	return user, nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id string) error {
+func (s *userService) DeleteUser(ctx context.Context, id string, pre Precondition) error {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -84,5 +165,21 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	if user == nil {
 		return nil
 	}
-	return s.repo.Delete(ctx, id)
+	if err := s.authz.AuthorizeUser(ctx, user, authz.UserDelete); err != nil {
+		return err
+	}
+	if err := pre.Check(user.Version); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, user.Version); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		Action:       model.AuditActionDelete,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+		CompanyID:    user.CompanyID,
+		Before:       user,
+	})
+	return nil
 }
@@ -0,0 +1,27 @@
+package service
+
+import "strings"
+
+// FieldError is an input breaking a domain rule, Field being the JSON member it was sent as,
+// such as "type" or "[2].type" for the third item of a batch.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError lists every domain rule an input breaks, so they are all reported at once.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, f := range e {
+		messages[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+const (
+	unitTypeMessage = "Type must be HOUSE or FLAT"
+	roleMessage     = "Role must be admin, company or customer"
+	clientMessage   = "Client must be a customer of the company building the site"
+)
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_media_room_taken_at;
+DROP TABLE IF EXISTS media;
@@ -0,0 +1,15 @@
+CREATE TABLE media (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    thumbnail_url TEXT,
+    storage_key TEXT NOT NULL,
+    file_name VARCHAR NOT NULL,
+    content_type VARCHAR NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    taken_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_room_taken_at ON media(room_id, taken_at DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_media_processing_status;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_error;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_status;
+ALTER TABLE media DROP COLUMN IF EXISTS preview_url;
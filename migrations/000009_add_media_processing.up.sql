@@ -0,0 +1,5 @@
+ALTER TABLE media ADD COLUMN preview_url TEXT;
+ALTER TABLE media ADD COLUMN processing_status VARCHAR(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE media ADD COLUMN processing_error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_media_processing_status ON media(processing_status);
@@ -0,0 +1,11 @@
+ALTER TABLE rooms DROP COLUMN IF EXISTS is_panoramic;
+
+ALTER TABLE media DROP COLUMN IF EXISTS height;
+ALTER TABLE media DROP COLUMN IF EXISTS width;
+ALTER TABLE media DROP COLUMN IF EXISTS pose_heading;
+ALTER TABLE media DROP COLUMN IF EXISTS projection_type;
+ALTER TABLE media DROP COLUMN IF EXISTS longitude;
+ALTER TABLE media DROP COLUMN IF EXISTS latitude;
+ALTER TABLE media DROP COLUMN IF EXISTS camera_model;
+ALTER TABLE media DROP COLUMN IF EXISTS camera_make;
+ALTER TABLE media DROP COLUMN IF EXISTS taken_at_source;
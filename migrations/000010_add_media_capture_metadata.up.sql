@@ -0,0 +1,11 @@
+ALTER TABLE media ADD COLUMN taken_at_source VARCHAR(20) NOT NULL DEFAULT 'upload';
+ALTER TABLE media ADD COLUMN camera_make VARCHAR;
+ALTER TABLE media ADD COLUMN camera_model VARCHAR;
+ALTER TABLE media ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE media ADD COLUMN longitude DOUBLE PRECISION;
+ALTER TABLE media ADD COLUMN projection_type VARCHAR(50);
+ALTER TABLE media ADD COLUMN pose_heading DOUBLE PRECISION;
+ALTER TABLE media ADD COLUMN width INTEGER;
+ALTER TABLE media ADD COLUMN height INTEGER;
+
+ALTER TABLE rooms ADD COLUMN is_panoramic BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_media_tiles_status;
+ALTER TABLE media DROP COLUMN IF EXISTS tile_face_size;
+ALTER TABLE media DROP COLUMN IF EXISTS tile_size;
+ALTER TABLE media DROP COLUMN IF EXISTS tiles_error;
+ALTER TABLE media DROP COLUMN IF EXISTS tiles_status;
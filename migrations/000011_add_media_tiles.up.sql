@@ -0,0 +1,10 @@
+-- NULL tiles_status means the media is not a panorama and has no tile pyramid
+ALTER TABLE media ADD COLUMN tiles_status VARCHAR(20);
+ALTER TABLE media ADD COLUMN tiles_error TEXT;
+ALTER TABLE media ADD COLUMN tile_size INTEGER;
+ALTER TABLE media ADD COLUMN tile_face_size INTEGER;
+
+-- Queue existing panoramas so they get tiled on the next start
+UPDATE media SET tiles_status = 'pending' WHERE projection_type = 'equirectangular';
+
+CREATE INDEX IF NOT EXISTS idx_media_tiles_status ON media(tiles_status);
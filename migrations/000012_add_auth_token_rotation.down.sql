@@ -0,0 +1,9 @@
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_user_id_fkey;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+DROP INDEX IF EXISTS idx_auth_tokens_previous_token;
+DROP INDEX IF EXISTS idx_auth_tokens_token;
+
+ALTER TABLE auth_tokens DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE auth_tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE auth_tokens DROP COLUMN IF EXISTS previous_token;
@@ -0,0 +1,12 @@
+-- token now holds the SHA-256 of the current refresh token, previous_token the one it replaced
+-- so a replayed refresh token can be detected and the session revoked
+ALTER TABLE auth_tokens ADD COLUMN previous_token TEXT;
+ALTER TABLE auth_tokens ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE auth_tokens ADD COLUMN revoked_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_tokens_token ON auth_tokens(token);
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_previous_token ON auth_tokens(previous_token);
+
+-- Sessions go away with their user
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_user_id_fkey;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
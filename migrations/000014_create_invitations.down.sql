@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_invitations_email;
+DROP INDEX IF EXISTS idx_invitations_company_id;
+
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS login_lockouts;
+
+DROP INDEX IF EXISTS idx_rate_limit_buckets_updated_at;
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
+
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
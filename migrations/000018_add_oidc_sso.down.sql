@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS oidc_login_states;
+
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP TABLE IF EXISTS user_identities;
+
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS oidc_client_secret,
+    DROP COLUMN IF EXISTS oidc_client_id,
+    DROP COLUMN IF EXISTS oidc_issuer;
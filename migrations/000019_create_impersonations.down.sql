@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_impersonations_user_id;
+DROP INDEX IF EXISTS idx_impersonations_admin_id;
+
+DROP TABLE IF EXISTS impersonations;
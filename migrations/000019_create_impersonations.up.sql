@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS impersonations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    session_id UUID REFERENCES auth_tokens(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonations_admin_id ON impersonations(admin_id);
+CREATE INDEX IF NOT EXISTS idx_impersonations_user_id ON impersonations(user_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_audit_events_occurred_at;
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+DROP INDEX IF EXISTS idx_audit_events_resource;
+DROP INDEX IF EXISTS idx_audit_events_company_id;
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- No foreign keys, the trail outlives the users and companies it mentions
+    actor_id UUID,
+    impersonator_id UUID,
+    company_id UUID,
+    action VARCHAR(16) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id TEXT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address TEXT,
+    user_agent TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_company_id ON audit_events(company_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit_events(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at DESC);
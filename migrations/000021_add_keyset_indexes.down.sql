@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_audit_events_occurred_at_id;
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at DESC);
+
+DROP INDEX IF EXISTS idx_media_room_created_at;
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_media_room_created_at ON media(room_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_audit_events_occurred_at;
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at_id ON audit_events(occurred_at DESC, id DESC);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_users_search;
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_rooms_search;
+ALTER TABLE rooms DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_units_search;
+ALTER TABLE units DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_construction_sites_search;
+ALTER TABLE construction_sites DROP COLUMN IF EXISTS search_vector;
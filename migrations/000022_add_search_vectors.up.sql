@@ -0,0 +1,22 @@
+-- 'simple' keeps words as they are, names and addresses are not English prose to stem
+ALTER TABLE construction_sites ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(address, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_construction_sites_search ON construction_sites USING GIN (search_vector);
+
+ALTER TABLE units ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_units_search ON units USING GIN (search_vector);
+
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_rooms_search ON rooms USING GIN (search_vector);
+
+-- The whole address plus its parts, so "jane" and "example" both find jane@example.com
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        to_tsvector('simple', email || ' ' || translate(email, '@.-_+', '     '))
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (search_vector);
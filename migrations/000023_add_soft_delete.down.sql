@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_rooms_deleted_at;
+DROP INDEX IF EXISTS idx_units_deleted_at;
+DROP INDEX IF EXISTS idx_construction_sites_deleted_at;
+DROP INDEX IF EXISTS idx_companies_deleted_at;
+
+-- What is still in the trash would come back to life, it is deleted for good instead
+DELETE FROM rooms WHERE deleted_at IS NOT NULL;
+DELETE FROM units WHERE deleted_at IS NOT NULL;
+DELETE FROM construction_sites WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE units DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE construction_sites DROP COLUMN IF EXISTS deleted_at;
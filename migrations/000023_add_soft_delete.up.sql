@@ -0,0 +1,19 @@
+-- Deletes go to the trash first. A delete cascades to everything inside with the same
+-- deleted_at, which is how a restore tells what went with it from what was deleted before.
+ALTER TABLE construction_sites ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE units ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Companies were soft deleted alone so far, their contents follow them into the trash
+UPDATE construction_sites s SET deleted_at = c.deleted_at
+FROM companies c WHERE c.id = s.company_id AND c.deleted_at IS NOT NULL;
+UPDATE units u SET deleted_at = s.deleted_at
+FROM construction_sites s WHERE s.id = u.site_id AND s.deleted_at IS NOT NULL;
+UPDATE rooms r SET deleted_at = u.deleted_at
+FROM units u WHERE u.id = r.unit_id AND u.deleted_at IS NOT NULL;
+
+-- Only the trash is indexed, for its listing and the purge
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_construction_sites_deleted_at ON construction_sites(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_units_deleted_at ON units(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rooms_deleted_at ON rooms(deleted_at) WHERE deleted_at IS NOT NULL;
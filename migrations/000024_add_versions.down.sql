@@ -0,0 +1,6 @@
+ALTER TABLE media DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE rooms DROP COLUMN IF EXISTS version;
+ALTER TABLE units DROP COLUMN IF EXISTS version;
+ALTER TABLE construction_sites DROP COLUMN IF EXISTS version;
+ALTER TABLE companies DROP COLUMN IF EXISTS version;
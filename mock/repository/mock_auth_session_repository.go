@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: auth_session_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+)
+
+// MockAuthSessionRepository is a mock of AuthSessionRepository interface.
+type MockAuthSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthSessionRepositoryMockRecorder
+}
+
+// MockAuthSessionRepositoryMockRecorder is the mock recorder for MockAuthSessionRepository.
+type MockAuthSessionRepositoryMockRecorder struct {
+	mock *MockAuthSessionRepository
+}
+
+// NewMockAuthSessionRepository creates a new mock instance.
+func NewMockAuthSessionRepository(ctrl *gomock.Controller) *MockAuthSessionRepository {
+	mock := &MockAuthSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthSessionRepository) EXPECT() *MockAuthSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuthSessionRepository) Create(ctx context.Context, session *model.AuthSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuthSessionRepositoryMockRecorder) Create(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuthSessionRepository)(nil).Create), ctx, session)
+}
+
+// FindActiveByUserID mocks base method.
+func (m *MockAuthSessionRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*model.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*model.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveByUserID indicates an expected call of FindActiveByUserID.
+func (mr *MockAuthSessionRepositoryMockRecorder) FindActiveByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveByUserID", reflect.TypeOf((*MockAuthSessionRepository)(nil).FindActiveByUserID), ctx, userID)
+}
+
+// FindByID mocks base method.
+func (m *MockAuthSessionRepository) FindByID(ctx context.Context, id string) (*model.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockAuthSessionRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockAuthSessionRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByPreviousTokenHash mocks base method.
+func (m *MockAuthSessionRepository) FindByPreviousTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPreviousTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPreviousTokenHash indicates an expected call of FindByPreviousTokenHash.
+func (mr *MockAuthSessionRepositoryMockRecorder) FindByPreviousTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPreviousTokenHash", reflect.TypeOf((*MockAuthSessionRepository)(nil).FindByPreviousTokenHash), ctx, tokenHash)
+}
+
+// FindByTokenHash mocks base method.
+func (m *MockAuthSessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTokenHash indicates an expected call of FindByTokenHash.
+func (mr *MockAuthSessionRepositoryMockRecorder) FindByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTokenHash", reflect.TypeOf((*MockAuthSessionRepository)(nil).FindByTokenHash), ctx, tokenHash)
+}
+
+// Revoke mocks base method.
+func (m *MockAuthSessionRepository) Revoke(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAuthSessionRepositoryMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAuthSessionRepository)(nil).Revoke), ctx, id)
+}
+
+// RevokeAllForUser mocks base method.
+func (m *MockAuthSessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllForUser indicates an expected call of RevokeAllForUser.
+func (mr *MockAuthSessionRepositoryMockRecorder) RevokeAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllForUser", reflect.TypeOf((*MockAuthSessionRepository)(nil).RevokeAllForUser), ctx, userID)
+}
+
+// RevokeOthersForUser mocks base method.
+func (m *MockAuthSessionRepository) RevokeOthersForUser(ctx context.Context, userID, keepSessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOthersForUser", ctx, userID, keepSessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOthersForUser indicates an expected call of RevokeOthersForUser.
+func (mr *MockAuthSessionRepositoryMockRecorder) RevokeOthersForUser(ctx, userID, keepSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOthersForUser", reflect.TypeOf((*MockAuthSessionRepository)(nil).RevokeOthersForUser), ctx, userID, keepSessionID)
+}
+
+// Rotate mocks base method.
+func (m *MockAuthSessionRepository) Rotate(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time, userAgent, ipAddress *string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, id, oldHash, newHash, expiresAt, userAgent, ipAddress)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockAuthSessionRepositoryMockRecorder) Rotate(ctx, id, oldHash, newHash, expiresAt, userAgent, ipAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockAuthSessionRepository)(nil).Rotate), ctx, id, oldHash, newHash, expiresAt, userAgent, ipAddress)
+}
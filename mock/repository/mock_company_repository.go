@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: company_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	db "github.com/hfleury/bk_globalshot/pkg/db"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockCompanyRepository is a mock of CompanyRepository interface.
+type MockCompanyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompanyRepositoryMockRecorder
+}
+
+// MockCompanyRepositoryMockRecorder is the mock recorder for MockCompanyRepository.
+type MockCompanyRepositoryMockRecorder struct {
+	mock *MockCompanyRepository
+}
+
+// NewMockCompanyRepository creates a new mock instance.
+func NewMockCompanyRepository(ctrl *gomock.Controller) *MockCompanyRepository {
+	mock := &MockCompanyRepository{ctrl: ctrl}
+	mock.recorder = &MockCompanyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompanyRepository) EXPECT() *MockCompanyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCompanyRepository) Create(ctx context.Context, company *model.Company) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, company)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCompanyRepositoryMockRecorder) Create(ctx, company interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCompanyRepository)(nil).Create), ctx, company)
+}
+
+// Delete mocks base method.
+func (m *MockCompanyRepository) Delete(ctx context.Context, id string, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCompanyRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCompanyRepository)(nil).Delete), ctx, id, version)
+}
+
+// FindAll mocks base method.
+func (m *MockCompanyRepository) FindAll(ctx context.Context, query listquery.Query) ([]*model.Company, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query)
+	ret0, _ := ret[0].([]*model.Company)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockCompanyRepositoryMockRecorder) FindAll(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockCompanyRepository)(nil).FindAll), ctx, query)
+}
+
+// FindByID mocks base method.
+func (m *MockCompanyRepository) FindByID(ctx context.Context, id string) (*model.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockCompanyRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockCompanyRepository)(nil).FindByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockCompanyRepository) Update(ctx context.Context, company *model.Company) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, company)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCompanyRepositoryMockRecorder) Update(ctx, company interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCompanyRepository)(nil).Update), ctx, company)
+}
+
+// UpdateSSO mocks base method.
+func (m *MockCompanyRepository) UpdateSSO(ctx context.Context, company *model.Company) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSSO", ctx, company)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSSO indicates an expected call of UpdateSSO.
+func (mr *MockCompanyRepositoryMockRecorder) UpdateSSO(ctx, company interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSSO", reflect.TypeOf((*MockCompanyRepository)(nil).UpdateSSO), ctx, company)
+}
+
+// WithTx mocks base method.
+func (m *MockCompanyRepository) WithTx(tx db.Db) repository.CompanyRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.CompanyRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockCompanyRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockCompanyRepository)(nil).WithTx), tx)
+}
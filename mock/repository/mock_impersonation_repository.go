@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: impersonation_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockImpersonationRepository is a mock of ImpersonationRepository interface.
+type MockImpersonationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImpersonationRepositoryMockRecorder
+}
+
+// MockImpersonationRepositoryMockRecorder is the mock recorder for MockImpersonationRepository.
+type MockImpersonationRepositoryMockRecorder struct {
+	mock *MockImpersonationRepository
+}
+
+// NewMockImpersonationRepository creates a new mock instance.
+func NewMockImpersonationRepository(ctrl *gomock.Controller) *MockImpersonationRepository {
+	mock := &MockImpersonationRepository{ctrl: ctrl}
+	mock.recorder = &MockImpersonationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImpersonationRepository) EXPECT() *MockImpersonationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockImpersonationRepository) Create(ctx context.Context, impersonation *model.Impersonation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, impersonation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockImpersonationRepositoryMockRecorder) Create(ctx, impersonation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockImpersonationRepository)(nil).Create), ctx, impersonation)
+}
+
+// End mocks base method.
+func (m *MockImpersonationRepository) End(ctx context.Context, id string, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "End", ctx, id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// End indicates an expected call of End.
+func (mr *MockImpersonationRepositoryMockRecorder) End(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "End", reflect.TypeOf((*MockImpersonationRepository)(nil).End), ctx, id, at)
+}
+
+// FindAll mocks base method.
+func (m *MockImpersonationRepository) FindAll(ctx context.Context, query listquery.Query) ([]*model.Impersonation, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query)
+	ret0, _ := ret[0].([]*model.Impersonation)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockImpersonationRepositoryMockRecorder) FindAll(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockImpersonationRepository)(nil).FindAll), ctx, query)
+}
+
+// FindByID mocks base method.
+func (m *MockImpersonationRepository) FindByID(ctx context.Context, id string) (*model.Impersonation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Impersonation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockImpersonationRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockImpersonationRepository)(nil).FindByID), ctx, id)
+}
@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invitation_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	db "github.com/hfleury/bk_globalshot/pkg/db"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockInvitationRepository is a mock of InvitationRepository interface.
+type MockInvitationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationRepositoryMockRecorder
+}
+
+// MockInvitationRepositoryMockRecorder is the mock recorder for MockInvitationRepository.
+type MockInvitationRepositoryMockRecorder struct {
+	mock *MockInvitationRepository
+}
+
+// NewMockInvitationRepository creates a new mock instance.
+func NewMockInvitationRepository(ctrl *gomock.Controller) *MockInvitationRepository {
+	mock := &MockInvitationRepository{ctrl: ctrl}
+	mock.recorder = &MockInvitationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationRepository) EXPECT() *MockInvitationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *model.Invitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockInvitationRepositoryMockRecorder) Create(ctx, invitation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInvitationRepository)(nil).Create), ctx, invitation)
+}
+
+// FindAll mocks base method.
+func (m *MockInvitationRepository) FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.Invitation, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query, companyID)
+	ret0, _ := ret[0].([]*model.Invitation)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockInvitationRepositoryMockRecorder) FindAll(ctx, query, companyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockInvitationRepository)(nil).FindAll), ctx, query, companyID)
+}
+
+// FindByID mocks base method.
+func (m *MockInvitationRepository) FindByID(ctx context.Context, id string) (*model.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockInvitationRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockInvitationRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByTokenHash mocks base method.
+func (m *MockInvitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTokenHash indicates an expected call of FindByTokenHash.
+func (mr *MockInvitationRepositoryMockRecorder) FindByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTokenHash", reflect.TypeOf((*MockInvitationRepository)(nil).FindByTokenHash), ctx, tokenHash)
+}
+
+// MarkAccepted mocks base method.
+func (m *MockInvitationRepository) MarkAccepted(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAccepted", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAccepted indicates an expected call of MarkAccepted.
+func (mr *MockInvitationRepositoryMockRecorder) MarkAccepted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAccepted", reflect.TypeOf((*MockInvitationRepository)(nil).MarkAccepted), ctx, id)
+}
+
+// RenewToken mocks base method.
+func (m *MockInvitationRepository) RenewToken(ctx context.Context, invitation *model.Invitation) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewToken", ctx, invitation)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewToken indicates an expected call of RenewToken.
+func (mr *MockInvitationRepositoryMockRecorder) RenewToken(ctx, invitation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewToken", reflect.TypeOf((*MockInvitationRepository)(nil).RenewToken), ctx, invitation)
+}
+
+// Revoke mocks base method.
+func (m *MockInvitationRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockInvitationRepositoryMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockInvitationRepository)(nil).Revoke), ctx, id)
+}
+
+// WithTx mocks base method.
+func (m *MockInvitationRepository) WithTx(tx db.Db) repository.InvitationRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.InvitationRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockInvitationRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockInvitationRepository)(nil).WithTx), tx)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login_lockout_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+)
+
+// MockLoginLockoutRepository is a mock of LoginLockoutRepository interface.
+type MockLoginLockoutRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLockoutRepositoryMockRecorder
+}
+
+// MockLoginLockoutRepositoryMockRecorder is the mock recorder for MockLoginLockoutRepository.
+type MockLoginLockoutRepositoryMockRecorder struct {
+	mock *MockLoginLockoutRepository
+}
+
+// NewMockLoginLockoutRepository creates a new mock instance.
+func NewMockLoginLockoutRepository(ctrl *gomock.Controller) *MockLoginLockoutRepository {
+	mock := &MockLoginLockoutRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginLockoutRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLockoutRepository) EXPECT() *MockLoginLockoutRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByUserID mocks base method.
+func (m *MockLoginLockoutRepository) FindByUserID(ctx context.Context, userID string) (*model.LoginLockout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].(*model.LoginLockout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockLoginLockoutRepositoryMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockLoginLockoutRepository)(nil).FindByUserID), ctx, userID)
+}
+
+// Lock mocks base method.
+func (m *MockLoginLockoutRepository) Lock(ctx context.Context, userID string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, userID, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginLockoutRepositoryMockRecorder) Lock(ctx, userID, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginLockoutRepository)(nil).Lock), ctx, userID, until)
+}
+
+// RecordFailure mocks base method.
+func (m *MockLoginLockoutRepository) RecordFailure(ctx context.Context, userID string, window time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, userID, window)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockLoginLockoutRepositoryMockRecorder) RecordFailure(ctx, userID, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLoginLockoutRepository)(nil).RecordFailure), ctx, userID, window)
+}
+
+// Reset mocks base method.
+func (m *MockLoginLockoutRepository) Reset(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginLockoutRepositoryMockRecorder) Reset(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginLockoutRepository)(nil).Reset), ctx, userID)
+}
@@ -0,0 +1,211 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: mfa_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+)
+
+// MockMFARepository is a mock of MFARepository interface.
+type MockMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARepositoryMockRecorder
+}
+
+// MockMFARepositoryMockRecorder is the mock recorder for MockMFARepository.
+type MockMFARepositoryMockRecorder struct {
+	mock *MockMFARepository
+}
+
+// NewMockMFARepository creates a new mock instance.
+func NewMockMFARepository(ctrl *gomock.Controller) *MockMFARepository {
+	mock := &MockMFARepository{ctrl: ctrl}
+	mock.recorder = &MockMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARepository) EXPECT() *MockMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountRecoveryCodes mocks base method.
+func (m *MockMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecoveryCodes indicates an expected call of CountRecoveryCodes.
+func (mr *MockMFARepositoryMockRecorder) CountRecoveryCodes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecoveryCodes", reflect.TypeOf((*MockMFARepository)(nil).CountRecoveryCodes), ctx, userID)
+}
+
+// CreateChallenge mocks base method.
+func (m *MockMFARepository) CreateChallenge(ctx context.Context, challenge *model.MFAChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChallenge", ctx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateChallenge indicates an expected call of CreateChallenge.
+func (mr *MockMFARepositoryMockRecorder) CreateChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChallenge", reflect.TypeOf((*MockMFARepository)(nil).CreateChallenge), ctx, challenge)
+}
+
+// Delete mocks base method.
+func (m *MockMFARepository) Delete(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMFARepositoryMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMFARepository)(nil).Delete), ctx, userID)
+}
+
+// Enable mocks base method.
+func (m *MockMFARepository) Enable(ctx context.Context, userID string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockMFARepositoryMockRecorder) Enable(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockMFARepository)(nil).Enable), ctx, userID, step)
+}
+
+// FindByUserID mocks base method.
+func (m *MockMFARepository) FindByUserID(ctx context.Context, userID string) (*model.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].(*model.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockMFARepositoryMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockMFARepository)(nil).FindByUserID), ctx, userID)
+}
+
+// FindChallengeByTokenHash mocks base method.
+func (m *MockMFARepository) FindChallengeByTokenHash(ctx context.Context, tokenHash string) (*model.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChallengeByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChallengeByTokenHash indicates an expected call of FindChallengeByTokenHash.
+func (mr *MockMFARepositoryMockRecorder) FindChallengeByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChallengeByTokenHash", reflect.TypeOf((*MockMFARepository)(nil).FindChallengeByTokenHash), ctx, tokenHash)
+}
+
+// MarkChallengeUsed mocks base method.
+func (m *MockMFARepository) MarkChallengeUsed(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkChallengeUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkChallengeUsed indicates an expected call of MarkChallengeUsed.
+func (mr *MockMFARepositoryMockRecorder) MarkChallengeUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkChallengeUsed", reflect.TypeOf((*MockMFARepository)(nil).MarkChallengeUsed), ctx, id)
+}
+
+// RecordChallengeAttempt mocks base method.
+func (m *MockMFARepository) RecordChallengeAttempt(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChallengeAttempt", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChallengeAttempt indicates an expected call of RecordChallengeAttempt.
+func (mr *MockMFARepositoryMockRecorder) RecordChallengeAttempt(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChallengeAttempt", reflect.TypeOf((*MockMFARepository)(nil).RecordChallengeAttempt), ctx, id)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockMFARepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockMFARepository)(nil).ReplaceRecoveryCodes), ctx, userID, codeHashes)
+}
+
+// SavePending mocks base method.
+func (m *MockMFARepository) SavePending(ctx context.Context, userID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePending", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePending indicates an expected call of SavePending.
+func (mr *MockMFARepositoryMockRecorder) SavePending(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePending", reflect.TypeOf((*MockMFARepository)(nil).SavePending), ctx, userID, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMFARepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMFARepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseStep mocks base method.
+func (m *MockMFARepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockMFARepositoryMockRecorder) UseStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockMFARepository)(nil).UseStep), ctx, userID, step)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: room_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockRoomRepository is a mock of RoomRepository interface.
+type MockRoomRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoomRepositoryMockRecorder
+}
+
+// MockRoomRepositoryMockRecorder is the mock recorder for MockRoomRepository.
+type MockRoomRepositoryMockRecorder struct {
+	mock *MockRoomRepository
+}
+
+// NewMockRoomRepository creates a new mock instance.
+func NewMockRoomRepository(ctrl *gomock.Controller) *MockRoomRepository {
+	mock := &MockRoomRepository{ctrl: ctrl}
+	mock.recorder = &MockRoomRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoomRepository) EXPECT() *MockRoomRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRoomRepository) Create(ctx context.Context, room *model.Room) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRoomRepositoryMockRecorder) Create(ctx, room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoomRepository)(nil).Create), ctx, room)
+}
+
+// Delete mocks base method.
+func (m *MockRoomRepository) Delete(ctx context.Context, id string, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRoomRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoomRepository)(nil).Delete), ctx, id, version)
+}
+
+// FindAll mocks base method.
+func (m *MockRoomRepository) FindAll(ctx context.Context, query listquery.Query, scope repository.TenantScope) ([]*model.Room, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query, scope)
+	ret0, _ := ret[0].([]*model.Room)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockRoomRepositoryMockRecorder) FindAll(ctx, query, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRoomRepository)(nil).FindAll), ctx, query, scope)
+}
+
+// FindByID mocks base method.
+func (m *MockRoomRepository) FindByID(ctx context.Context, id string) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRoomRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRoomRepository)(nil).FindByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockRoomRepository) Update(ctx context.Context, room *model.Room) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRoomRepositoryMockRecorder) Update(ctx, room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRoomRepository)(nil).Update), ctx, room)
+}
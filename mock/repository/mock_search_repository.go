@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: search_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+)
+
+// MockSearchRepository is a mock of SearchRepository interface.
+type MockSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchRepositoryMockRecorder
+}
+
+// MockSearchRepositoryMockRecorder is the mock recorder for MockSearchRepository.
+type MockSearchRepositoryMockRecorder struct {
+	mock *MockSearchRepository
+}
+
+// NewMockSearchRepository creates a new mock instance.
+func NewMockSearchRepository(ctrl *gomock.Controller) *MockSearchRepository {
+	mock := &MockSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchRepository) EXPECT() *MockSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockSearchRepository) Search(ctx context.Context, query repository.SearchQuery) ([]*model.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]*model.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockSearchRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSearchRepository)(nil).Search), ctx, query)
+}
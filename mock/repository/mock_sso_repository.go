@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sso_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	db "github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+// MockSSORepository is a mock of SSORepository interface.
+type MockSSORepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSSORepositoryMockRecorder
+}
+
+// MockSSORepositoryMockRecorder is the mock recorder for MockSSORepository.
+type MockSSORepositoryMockRecorder struct {
+	mock *MockSSORepository
+}
+
+// NewMockSSORepository creates a new mock instance.
+func NewMockSSORepository(ctrl *gomock.Controller) *MockSSORepository {
+	mock := &MockSSORepository{ctrl: ctrl}
+	mock.recorder = &MockSSORepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSSORepository) EXPECT() *MockSSORepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeState mocks base method.
+func (m *MockSSORepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeState", ctx, stateHash)
+	ret0, _ := ret[0].(*model.OIDCLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeState indicates an expected call of ConsumeState.
+func (mr *MockSSORepositoryMockRecorder) ConsumeState(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeState", reflect.TypeOf((*MockSSORepository)(nil).ConsumeState), ctx, stateHash)
+}
+
+// CreateIdentity mocks base method.
+func (m *MockSSORepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdentity", ctx, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdentity indicates an expected call of CreateIdentity.
+func (mr *MockSSORepositoryMockRecorder) CreateIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdentity", reflect.TypeOf((*MockSSORepository)(nil).CreateIdentity), ctx, identity)
+}
+
+// CreateState mocks base method.
+func (m *MockSSORepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateState", ctx, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateState indicates an expected call of CreateState.
+func (mr *MockSSORepositoryMockRecorder) CreateState(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateState", reflect.TypeOf((*MockSSORepository)(nil).CreateState), ctx, state)
+}
+
+// FindIdentity mocks base method.
+func (m *MockSSORepository) FindIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIdentity", ctx, issuer, subject)
+	ret0, _ := ret[0].(*model.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIdentity indicates an expected call of FindIdentity.
+func (mr *MockSSORepositoryMockRecorder) FindIdentity(ctx, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdentity", reflect.TypeOf((*MockSSORepository)(nil).FindIdentity), ctx, issuer, subject)
+}
+
+// WithTx mocks base method.
+func (m *MockSSORepository) WithTx(tx db.Db) repository.SSORepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.SSORepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockSSORepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockSSORepository)(nil).WithTx), tx)
+}
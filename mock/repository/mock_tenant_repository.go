@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tenant_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+)
+
+// MockTenantRepository is a mock of TenantRepository interface.
+type MockTenantRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantRepositoryMockRecorder
+}
+
+// MockTenantRepositoryMockRecorder is the mock recorder for MockTenantRepository.
+type MockTenantRepositoryMockRecorder struct {
+	mock *MockTenantRepository
+}
+
+// NewMockTenantRepository creates a new mock instance.
+func NewMockTenantRepository(ctrl *gomock.Controller) *MockTenantRepository {
+	mock := &MockTenantRepository{ctrl: ctrl}
+	mock.recorder = &MockTenantRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTenantRepository) EXPECT() *MockTenantRepositoryMockRecorder {
+	return m.recorder
+}
+
+// IsSiteCustomer mocks base method.
+func (m *MockTenantRepository) IsSiteCustomer(ctx context.Context, siteID, customerID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSiteCustomer", ctx, siteID, customerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSiteCustomer indicates an expected call of IsSiteCustomer.
+func (mr *MockTenantRepositoryMockRecorder) IsSiteCustomer(ctx, siteID, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSiteCustomer", reflect.TypeOf((*MockTenantRepository)(nil).IsSiteCustomer), ctx, siteID, customerID)
+}
+
+// RoomOwnership mocks base method.
+func (m *MockTenantRepository) RoomOwnership(ctx context.Context, roomID string) (*repository.Ownership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoomOwnership", ctx, roomID)
+	ret0, _ := ret[0].(*repository.Ownership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoomOwnership indicates an expected call of RoomOwnership.
+func (mr *MockTenantRepositoryMockRecorder) RoomOwnership(ctx, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomOwnership", reflect.TypeOf((*MockTenantRepository)(nil).RoomOwnership), ctx, roomID)
+}
+
+// SiteOwnership mocks base method.
+func (m *MockTenantRepository) SiteOwnership(ctx context.Context, siteID string) (*repository.Ownership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SiteOwnership", ctx, siteID)
+	ret0, _ := ret[0].(*repository.Ownership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SiteOwnership indicates an expected call of SiteOwnership.
+func (mr *MockTenantRepositoryMockRecorder) SiteOwnership(ctx, siteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SiteOwnership", reflect.TypeOf((*MockTenantRepository)(nil).SiteOwnership), ctx, siteID)
+}
+
+// UnitOwnership mocks base method.
+func (m *MockTenantRepository) UnitOwnership(ctx context.Context, unitID string) (*repository.Ownership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnitOwnership", ctx, unitID)
+	ret0, _ := ret[0].(*repository.Ownership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnitOwnership indicates an expected call of UnitOwnership.
+func (mr *MockTenantRepositoryMockRecorder) UnitOwnership(ctx, unitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnitOwnership", reflect.TypeOf((*MockTenantRepository)(nil).UnitOwnership), ctx, unitID)
+}
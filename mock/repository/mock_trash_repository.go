@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: trash_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockTrashRepository is a mock of TrashRepository interface.
+type MockTrashRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashRepositoryMockRecorder
+}
+
+// MockTrashRepositoryMockRecorder is the mock recorder for MockTrashRepository.
+type MockTrashRepositoryMockRecorder struct {
+	mock *MockTrashRepository
+}
+
+// NewMockTrashRepository creates a new mock instance.
+func NewMockTrashRepository(ctrl *gomock.Controller) *MockTrashRepository {
+	mock := &MockTrashRepository{ctrl: ctrl}
+	mock.recorder = &MockTrashRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrashRepository) EXPECT() *MockTrashRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method.
+func (m *MockTrashRepository) FindAll(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query)
+	ret0, _ := ret[0].([]*model.TrashItem)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockTrashRepositoryMockRecorder) FindAll(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockTrashRepository)(nil).FindAll), ctx, query)
+}
+
+// FindByID mocks base method.
+func (m *MockTrashRepository) FindByID(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, itemType, id)
+	ret0, _ := ret[0].(*model.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockTrashRepositoryMockRecorder) FindByID(ctx, itemType, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTrashRepository)(nil).FindByID), ctx, itemType, id)
+}
+
+// Purge mocks base method.
+func (m *MockTrashRepository) Purge(ctx context.Context, before time.Time) (*repository.PurgedTrash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, before)
+	ret0, _ := ret[0].(*repository.PurgedTrash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTrashRepositoryMockRecorder) Purge(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTrashRepository)(nil).Purge), ctx, before)
+}
+
+// Restore mocks base method.
+func (m *MockTrashRepository) Restore(ctx context.Context, itemType model.TrashItemType, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, itemType, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTrashRepositoryMockRecorder) Restore(ctx, itemType, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTrashRepository)(nil).Restore), ctx, itemType, id)
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: unit_repository.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	repository "github.com/hfleury/bk_globalshot/internal/repository"
+	db "github.com/hfleury/bk_globalshot/pkg/db"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockUnitRepository is a mock of UnitRepository interface.
+type MockUnitRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnitRepositoryMockRecorder
+}
+
+// MockUnitRepositoryMockRecorder is the mock recorder for MockUnitRepository.
+type MockUnitRepositoryMockRecorder struct {
+	mock *MockUnitRepository
+}
+
+// NewMockUnitRepository creates a new mock instance.
+func NewMockUnitRepository(ctrl *gomock.Controller) *MockUnitRepository {
+	mock := &MockUnitRepository{ctrl: ctrl}
+	mock.recorder = &MockUnitRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnitRepository) EXPECT() *MockUnitRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BatchCreate mocks base method.
+func (m *MockUnitRepository) BatchCreate(ctx context.Context, units []*model.Unit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreate", ctx, units)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchCreate indicates an expected call of BatchCreate.
+func (mr *MockUnitRepositoryMockRecorder) BatchCreate(ctx, units interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockUnitRepository)(nil).BatchCreate), ctx, units)
+}
+
+// Create mocks base method.
+func (m *MockUnitRepository) Create(ctx context.Context, unit *model.Unit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, unit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUnitRepositoryMockRecorder) Create(ctx, unit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUnitRepository)(nil).Create), ctx, unit)
+}
+
+// Delete mocks base method.
+func (m *MockUnitRepository) Delete(ctx context.Context, id string, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUnitRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUnitRepository)(nil).Delete), ctx, id, version)
+}
+
+// FindAll mocks base method.
+func (m *MockUnitRepository) FindAll(ctx context.Context, query listquery.Query, scope repository.TenantScope) ([]*model.Unit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx, query, scope)
+	ret0, _ := ret[0].([]*model.Unit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockUnitRepositoryMockRecorder) FindAll(ctx, query, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockUnitRepository)(nil).FindAll), ctx, query, scope)
+}
+
+// FindByID mocks base method.
+func (m *MockUnitRepository) FindByID(ctx context.Context, id string) (*model.Unit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Unit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockUnitRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockUnitRepository)(nil).FindByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockUnitRepository) Update(ctx context.Context, unit *model.Unit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, unit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUnitRepositoryMockRecorder) Update(ctx, unit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUnitRepository)(nil).Update), ctx, unit)
+}
+
+// WithTx mocks base method.
+func (m *MockUnitRepository) WithTx(tx db.Db) repository.UnitRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.UnitRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockUnitRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockUnitRepository)(nil).WithTx), tx)
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: account_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	authz "github.com/hfleury/bk_globalshot/pkg/authz"
+)
+
+// MockAccountService is a mock of AccountService interface.
+type MockAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceMockRecorder
+}
+
+// MockAccountServiceMockRecorder is the mock recorder for MockAccountService.
+type MockAccountServiceMockRecorder struct {
+	mock *MockAccountService
+}
+
+// NewMockAccountService creates a new mock instance.
+func NewMockAccountService(ctrl *gomock.Controller) *MockAccountService {
+	mock := &MockAccountService{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockAccountService) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, sessionID, currentPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAccountServiceMockRecorder) ChangePassword(ctx, userID, sessionID, currentPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAccountService)(nil).ChangePassword), ctx, userID, sessionID, currentPassword, newPassword)
+}
+
+// GetProfile mocks base method.
+func (m *MockAccountService) GetProfile(ctx context.Context, userID string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockAccountServiceMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockAccountService)(nil).GetProfile), ctx, userID)
+}
+
+// ListSessions mocks base method.
+func (m *MockAccountService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*model.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].([]*model.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockAccountServiceMockRecorder) ListSessions(ctx, userID, currentSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockAccountService)(nil).ListSessions), ctx, userID, currentSessionID)
+}
+
+// Permissions mocks base method.
+func (m *MockAccountService) Permissions(ctx context.Context) ([]authz.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Permissions", ctx)
+	ret0, _ := ret[0].([]authz.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Permissions indicates an expected call of Permissions.
+func (mr *MockAccountServiceMockRecorder) Permissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permissions", reflect.TypeOf((*MockAccountService)(nil).Permissions), ctx)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockAccountService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockAccountServiceMockRecorder) RevokeOtherSessions(ctx, userID, currentSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockAccountService)(nil).RevokeOtherSessions), ctx, userID, currentSessionID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockAccountService) RevokeSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockAccountServiceMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockAccountService)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockAccountService) UpdateEmail(ctx context.Context, userID, email, currentPassword string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, userID, email, currentPassword)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockAccountServiceMockRecorder) UpdateEmail(ctx, userID, email, currentPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockAccountService)(nil).UpdateEmail), ctx, userID, email, currentPassword)
+}
@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
 )
 
 // MockAuthService is a mock of AuthService interface.
@@ -34,19 +36,138 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// EnrollMFA mocks base method.
+func (m *MockAuthService) EnrollMFA(ctx context.Context, challengeToken string) (*service.MFAEnrollment, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", ctx, challengeToken)
+	ret0, _ := ret[0].(*service.MFAEnrollment)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockAuthServiceMockRecorder) EnrollMFA(ctx, challengeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockAuthService)(nil).EnrollMFA), ctx, challengeToken)
+}
+
+// IsSessionActive mocks base method.
+func (m *MockAuthService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSessionActive", ctx, sessionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSessionActive indicates an expected call of IsSessionActive.
+func (mr *MockAuthServiceMockRecorder) IsSessionActive(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSessionActive", reflect.TypeOf((*MockAuthService)(nil).IsSessionActive), ctx, sessionID)
+}
+
 // Login mocks base method.
-func (m *MockAuthService) Login(ctx context.Context, email, password string) (string, string, bool, error) {
+func (m *MockAuthService) Login(ctx context.Context, email, password string, client service.ClientInfo) (*service.LoginResult, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, email, password)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(bool)
-	ret3, _ := ret[3].(error)
-	return ret0, ret1, ret2, ret3
+	ret := m.ctrl.Call(m, "Login", ctx, email, password, client)
+	ret0, _ := ret[0].(*service.LoginResult)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockAuthServiceMockRecorder) Login(ctx, email, password interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) Login(ctx, email, password, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), ctx, email, password, client)
+}
+
+// LoginWithIdentity mocks base method.
+func (m *MockAuthService) LoginWithIdentity(ctx context.Context, user *model.User, client service.ClientInfo) (*service.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithIdentity", ctx, user, client)
+	ret0, _ := ret[0].(*service.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithIdentity indicates an expected call of LoginWithIdentity.
+func (mr *MockAuthServiceMockRecorder) LoginWithIdentity(ctx, user, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithIdentity", reflect.TypeOf((*MockAuthService)(nil).LoginWithIdentity), ctx, user, client)
+}
+
+// Logout mocks base method.
+func (m *MockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceMockRecorder) Logout(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthService)(nil).Logout), ctx, refreshToken)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string, client service.ClientInfo) (*service.AuthTokens, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken, client)
+	ret0, _ := ret[0].(*service.AuthTokens)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceMockRecorder) Refresh(ctx, refreshToken, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthService)(nil).Refresh), ctx, refreshToken, client)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockAuthServiceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockAuthService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthService) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, resetToken, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthServiceMockRecorder) ResetPassword(ctx, resetToken, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthService)(nil).ResetPassword), ctx, resetToken, newPassword)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockAuthService) VerifyMFA(ctx context.Context, challengeToken, code string, client service.ClientInfo) (*service.AuthTokens, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, challengeToken, code, client)
+	ret0, _ := ret[0].(*service.AuthTokens)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockAuthServiceMockRecorder) VerifyMFA(ctx, challengeToken, code, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockAuthService)(nil).VerifyMFA), ctx, challengeToken, code, client)
 }
@@ -10,6 +10,8 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 // MockCompanyService is a mock of CompanyService interface.
@@ -51,23 +53,23 @@ func (mr *MockCompanyServiceMockRecorder) CreateCompany(ctx, name, email, passwo
 }
 
 // DeleteCompany mocks base method.
-func (m *MockCompanyService) DeleteCompany(ctx context.Context, id string) error {
+func (m *MockCompanyService) DeleteCompany(ctx context.Context, id string, pre service.Precondition) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCompany", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteCompany", ctx, id, pre)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCompany indicates an expected call of DeleteCompany.
-func (mr *MockCompanyServiceMockRecorder) DeleteCompany(ctx, id interface{}) *gomock.Call {
+func (mr *MockCompanyServiceMockRecorder) DeleteCompany(ctx, id, pre interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockCompanyService)(nil).DeleteCompany), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockCompanyService)(nil).DeleteCompany), ctx, id, pre)
 }
 
 // GetAllCompanies mocks base method.
-func (m *MockCompanyService) GetAllCompanies(ctx context.Context, limit, offset int) ([]*model.Company, int64, error) {
+func (m *MockCompanyService) GetAllCompanies(ctx context.Context, query listquery.Query) ([]*model.Company, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllCompanies", ctx, limit, offset)
+	ret := m.ctrl.Call(m, "GetAllCompanies", ctx, query)
 	ret0, _ := ret[0].([]*model.Company)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -75,9 +77,9 @@ func (m *MockCompanyService) GetAllCompanies(ctx context.Context, limit, offset
 }
 
 // GetAllCompanies indicates an expected call of GetAllCompanies.
-func (mr *MockCompanyServiceMockRecorder) GetAllCompanies(ctx, limit, offset interface{}) *gomock.Call {
+func (mr *MockCompanyServiceMockRecorder) GetAllCompanies(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCompanies", reflect.TypeOf((*MockCompanyService)(nil).GetAllCompanies), ctx, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCompanies", reflect.TypeOf((*MockCompanyService)(nil).GetAllCompanies), ctx, query)
 }
 
 // GetCompanyByID mocks base method.
@@ -95,17 +97,32 @@ func (mr *MockCompanyServiceMockRecorder) GetCompanyByID(ctx, id interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByID", reflect.TypeOf((*MockCompanyService)(nil).GetCompanyByID), ctx, id)
 }
 
+// PatchCompany mocks base method.
+func (m *MockCompanyService) PatchCompany(ctx context.Context, id string, patch service.CompanyPatch, pre service.Precondition) (*model.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchCompany", ctx, id, patch, pre)
+	ret0, _ := ret[0].(*model.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchCompany indicates an expected call of PatchCompany.
+func (mr *MockCompanyServiceMockRecorder) PatchCompany(ctx, id, patch, pre interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCompany", reflect.TypeOf((*MockCompanyService)(nil).PatchCompany), ctx, id, patch, pre)
+}
+
 // UpdateCompany mocks base method.
-func (m *MockCompanyService) UpdateCompany(ctx context.Context, id, name string) (*model.Company, error) {
+func (m *MockCompanyService) UpdateCompany(ctx context.Context, id, name string, pre service.Precondition) (*model.Company, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCompany", ctx, id, name)
+	ret := m.ctrl.Call(m, "UpdateCompany", ctx, id, name, pre)
 	ret0, _ := ret[0].(*model.Company)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateCompany indicates an expected call of UpdateCompany.
-func (mr *MockCompanyServiceMockRecorder) UpdateCompany(ctx, id, name interface{}) *gomock.Call {
+func (mr *MockCompanyServiceMockRecorder) UpdateCompany(ctx, id, name, pre interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockCompanyService)(nil).UpdateCompany), ctx, id, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockCompanyService)(nil).UpdateCompany), ctx, id, name, pre)
 }
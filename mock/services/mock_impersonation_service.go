@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: impersonation_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockImpersonationService is a mock of ImpersonationService interface.
+type MockImpersonationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImpersonationServiceMockRecorder
+}
+
+// MockImpersonationServiceMockRecorder is the mock recorder for MockImpersonationService.
+type MockImpersonationServiceMockRecorder struct {
+	mock *MockImpersonationService
+}
+
+// NewMockImpersonationService creates a new mock instance.
+func NewMockImpersonationService(ctrl *gomock.Controller) *MockImpersonationService {
+	mock := &MockImpersonationService{ctrl: ctrl}
+	mock.recorder = &MockImpersonationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImpersonationService) EXPECT() *MockImpersonationServiceMockRecorder {
+	return m.recorder
+}
+
+// EndImpersonation mocks base method.
+func (m *MockImpersonationService) EndImpersonation(ctx context.Context, id string) (*model.Impersonation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndImpersonation", ctx, id)
+	ret0, _ := ret[0].(*model.Impersonation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndImpersonation indicates an expected call of EndImpersonation.
+func (mr *MockImpersonationServiceMockRecorder) EndImpersonation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndImpersonation", reflect.TypeOf((*MockImpersonationService)(nil).EndImpersonation), ctx, id)
+}
+
+// GetAllImpersonations mocks base method.
+func (m *MockImpersonationService) GetAllImpersonations(ctx context.Context, query listquery.Query) ([]*model.Impersonation, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllImpersonations", ctx, query)
+	ret0, _ := ret[0].([]*model.Impersonation)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllImpersonations indicates an expected call of GetAllImpersonations.
+func (mr *MockImpersonationServiceMockRecorder) GetAllImpersonations(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllImpersonations", reflect.TypeOf((*MockImpersonationService)(nil).GetAllImpersonations), ctx, query)
+}
+
+// Impersonate mocks base method.
+func (m *MockImpersonationService) Impersonate(ctx context.Context, userID, reason string, client service.ClientInfo) (*service.ImpersonationTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", ctx, userID, reason, client)
+	ret0, _ := ret[0].(*service.ImpersonationTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockImpersonationServiceMockRecorder) Impersonate(ctx, userID, reason, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockImpersonationService)(nil).Impersonate), ctx, userID, reason, client)
+}
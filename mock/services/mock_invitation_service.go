@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invitation_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockInvitationService is a mock of InvitationService interface.
+type MockInvitationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationServiceMockRecorder
+}
+
+// MockInvitationServiceMockRecorder is the mock recorder for MockInvitationService.
+type MockInvitationServiceMockRecorder struct {
+	mock *MockInvitationService
+}
+
+// NewMockInvitationService creates a new mock instance.
+func NewMockInvitationService(ctrl *gomock.Controller) *MockInvitationService {
+	mock := &MockInvitationService{ctrl: ctrl}
+	mock.recorder = &MockInvitationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationService) EXPECT() *MockInvitationServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockInvitationService) AcceptInvitation(ctx context.Context, invitationToken, password string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, invitationToken, password)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockInvitationServiceMockRecorder) AcceptInvitation(ctx, invitationToken, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockInvitationService)(nil).AcceptInvitation), ctx, invitationToken, password)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockInvitationService) CreateInvitation(ctx context.Context, email, role, companyID string, unitID *string) (*model.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, email, role, companyID, unitID)
+	ret0, _ := ret[0].(*model.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockInvitationServiceMockRecorder) CreateInvitation(ctx, email, role, companyID, unitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockInvitationService)(nil).CreateInvitation), ctx, email, role, companyID, unitID)
+}
+
+// GetAllInvitations mocks base method.
+func (m *MockInvitationService) GetAllInvitations(ctx context.Context, query listquery.Query) ([]*model.Invitation, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllInvitations", ctx, query)
+	ret0, _ := ret[0].([]*model.Invitation)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllInvitations indicates an expected call of GetAllInvitations.
+func (mr *MockInvitationServiceMockRecorder) GetAllInvitations(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllInvitations", reflect.TypeOf((*MockInvitationService)(nil).GetAllInvitations), ctx, query)
+}
+
+// ResendInvitation mocks base method.
+func (m *MockInvitationService) ResendInvitation(ctx context.Context, id string) (*model.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendInvitation", ctx, id)
+	ret0, _ := ret[0].(*model.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResendInvitation indicates an expected call of ResendInvitation.
+func (mr *MockInvitationServiceMockRecorder) ResendInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendInvitation", reflect.TypeOf((*MockInvitationService)(nil).ResendInvitation), ctx, id)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockInvitationService) RevokeInvitation(ctx context.Context, id string) (*model.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, id)
+	ret0, _ := ret[0].(*model.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockInvitationServiceMockRecorder) RevokeInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockInvitationService)(nil).RevokeInvitation), ctx, id)
+}
@@ -0,0 +1,159 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: media_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+	imaging "github.com/hfleury/bk_globalshot/pkg/imaging"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockMediaService is a mock of MediaService interface.
+type MockMediaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaServiceMockRecorder
+}
+
+// MockMediaServiceMockRecorder is the mock recorder for MockMediaService.
+type MockMediaServiceMockRecorder struct {
+	mock *MockMediaService
+}
+
+// NewMockMediaService creates a new mock instance.
+func NewMockMediaService(ctrl *gomock.Controller) *MockMediaService {
+	mock := &MockMediaService{ctrl: ctrl}
+	mock.recorder = &MockMediaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaService) EXPECT() *MockMediaServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteMedia mocks base method.
+func (m *MockMediaService) DeleteMedia(ctx context.Context, id string, pre service.Precondition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMedia", ctx, id, pre)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMedia indicates an expected call of DeleteMedia.
+func (mr *MockMediaServiceMockRecorder) DeleteMedia(ctx, id, pre interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMedia", reflect.TypeOf((*MockMediaService)(nil).DeleteMedia), ctx, id, pre)
+}
+
+// GetMediaByID mocks base method.
+func (m *MockMediaService) GetMediaByID(ctx context.Context, id string) (*model.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByID", ctx, id)
+	ret0, _ := ret[0].(*model.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByID indicates an expected call of GetMediaByID.
+func (mr *MockMediaServiceMockRecorder) GetMediaByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByID", reflect.TypeOf((*MockMediaService)(nil).GetMediaByID), ctx, id)
+}
+
+// GetRoomMedia mocks base method.
+func (m *MockMediaService) GetRoomMedia(ctx context.Context, roomID string, query listquery.Query) ([]*model.Media, listquery.PageInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomMedia", ctx, roomID, query)
+	ret0, _ := ret[0].([]*model.Media)
+	ret1, _ := ret[1].(listquery.PageInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRoomMedia indicates an expected call of GetRoomMedia.
+func (mr *MockMediaServiceMockRecorder) GetRoomMedia(ctx, roomID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomMedia", reflect.TypeOf((*MockMediaService)(nil).GetRoomMedia), ctx, roomID, query)
+}
+
+// GetTileManifest mocks base method.
+func (m *MockMediaService) GetTileManifest(ctx context.Context, id string) (*model.TileManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTileManifest", ctx, id)
+	ret0, _ := ret[0].(*model.TileManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTileManifest indicates an expected call of GetTileManifest.
+func (mr *MockMediaServiceMockRecorder) GetTileManifest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTileManifest", reflect.TypeOf((*MockMediaService)(nil).GetTileManifest), ctx, id)
+}
+
+// OpenMediaFile mocks base method.
+func (m *MockMediaService) OpenMediaFile(ctx context.Context, id string, variant model.MediaVariant) (*service.MediaFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenMediaFile", ctx, id, variant)
+	ret0, _ := ret[0].(*service.MediaFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenMediaFile indicates an expected call of OpenMediaFile.
+func (mr *MockMediaServiceMockRecorder) OpenMediaFile(ctx, id, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenMediaFile", reflect.TypeOf((*MockMediaService)(nil).OpenMediaFile), ctx, id, variant)
+}
+
+// OpenTile mocks base method.
+func (m *MockMediaService) OpenTile(ctx context.Context, id string, level int, face imaging.CubeFace, x, y int) (*service.MediaFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenTile", ctx, id, level, face, x, y)
+	ret0, _ := ret[0].(*service.MediaFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenTile indicates an expected call of OpenTile.
+func (mr *MockMediaServiceMockRecorder) OpenTile(ctx, id, level, face, x, y interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenTile", reflect.TypeOf((*MockMediaService)(nil).OpenTile), ctx, id, level, face, x, y)
+}
+
+// RegenerateRenditions mocks base method.
+func (m *MockMediaService) RegenerateRenditions(ctx context.Context, id string) (*model.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRenditions", ctx, id)
+	ret0, _ := ret[0].(*model.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRenditions indicates an expected call of RegenerateRenditions.
+func (mr *MockMediaServiceMockRecorder) RegenerateRenditions(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRenditions", reflect.TypeOf((*MockMediaService)(nil).RegenerateRenditions), ctx, id)
+}
+
+// UploadMedia mocks base method.
+func (m *MockMediaService) UploadMedia(ctx context.Context, input service.UploadMediaInput) (*model.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMedia", ctx, input)
+	ret0, _ := ret[0].(*model.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMedia indicates an expected call of UploadMedia.
+func (mr *MockMediaServiceMockRecorder) UploadMedia(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMedia", reflect.TypeOf((*MockMediaService)(nil).UploadMedia), ctx, input)
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: mfa_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+)
+
+// MockMFAService is a mock of MFAService interface.
+type MockMFAService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAServiceMockRecorder
+}
+
+// MockMFAServiceMockRecorder is the mock recorder for MockMFAService.
+type MockMFAServiceMockRecorder struct {
+	mock *MockMFAService
+}
+
+// NewMockMFAService creates a new mock instance.
+func NewMockMFAService(ctrl *gomock.Controller) *MockMFAService {
+	mock := &MockMFAService{ctrl: ctrl}
+	mock.recorder = &MockMFAServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAService) EXPECT() *MockMFAServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginEnrollment mocks base method.
+func (m *MockMFAService) BeginEnrollment(ctx context.Context, userID string) (*service.MFAEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginEnrollment", ctx, userID)
+	ret0, _ := ret[0].(*service.MFAEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginEnrollment indicates an expected call of BeginEnrollment.
+func (mr *MockMFAServiceMockRecorder) BeginEnrollment(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginEnrollment", reflect.TypeOf((*MockMFAService)(nil).BeginEnrollment), ctx, userID)
+}
+
+// ConfirmEnrollment mocks base method.
+func (m *MockMFAService) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEnrollment", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEnrollment indicates an expected call of ConfirmEnrollment.
+func (mr *MockMFAServiceMockRecorder) ConfirmEnrollment(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEnrollment", reflect.TypeOf((*MockMFAService)(nil).ConfirmEnrollment), ctx, userID, code)
+}
+
+// Disable mocks base method.
+func (m *MockMFAService) Disable(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockMFAServiceMockRecorder) Disable(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockMFAService)(nil).Disable), ctx, userID, code)
+}
+
+// IsEnabled mocks base method.
+func (m *MockMFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEnabled indicates an expected call of IsEnabled.
+func (mr *MockMFAServiceMockRecorder) IsEnabled(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockMFAService)(nil).IsEnabled), ctx, userID)
+}
+
+// IsRequired mocks base method.
+func (m *MockMFAService) IsRequired(role string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRequired", role)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsRequired indicates an expected call of IsRequired.
+func (mr *MockMFAServiceMockRecorder) IsRequired(role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRequired", reflect.TypeOf((*MockMFAService)(nil).IsRequired), role)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockMFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockMFAServiceMockRecorder) RegenerateRecoveryCodes(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockMFAService)(nil).RegenerateRecoveryCodes), ctx, userID, code)
+}
+
+// Status mocks base method.
+func (m *MockMFAService) Status(ctx context.Context, userID string) (*service.MFAStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx, userID)
+	ret0, _ := ret[0].(*service.MFAStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockMFAServiceMockRecorder) Status(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockMFAService)(nil).Status), ctx, userID)
+}
+
+// Verify mocks base method.
+func (m *MockMFAService) Verify(ctx context.Context, userID, code string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, userID, code)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockMFAServiceMockRecorder) Verify(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockMFAService)(nil).Verify), ctx, userID, code)
+}
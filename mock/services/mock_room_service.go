@@ -10,6 +10,8 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 // MockRoomService is a mock of RoomService interface.
@@ -36,38 +38,38 @@ func (m *MockRoomService) EXPECT() *MockRoomServiceMockRecorder {
 }
 
 // CreateRoom mocks base method.
-func (m *MockRoomService) CreateRoom(ctx context.Context, name, unitID string) (*model.Room, error) {
+func (m *MockRoomService) CreateRoom(ctx context.Context, name, unitID string, panoramic *bool) (*model.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRoom", ctx, name, unitID)
+	ret := m.ctrl.Call(m, "CreateRoom", ctx, name, unitID, panoramic)
 	ret0, _ := ret[0].(*model.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateRoom indicates an expected call of CreateRoom.
-func (mr *MockRoomServiceMockRecorder) CreateRoom(ctx, name, unitID interface{}) *gomock.Call {
+func (mr *MockRoomServiceMockRecorder) CreateRoom(ctx, name, unitID, panoramic interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomService)(nil).CreateRoom), ctx, name, unitID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomService)(nil).CreateRoom), ctx, name, unitID, panoramic)
 }
 
 // DeleteRoom mocks base method.
-func (m *MockRoomService) DeleteRoom(ctx context.Context, id string) error {
+func (m *MockRoomService) DeleteRoom(ctx context.Context, id string, pre service.Precondition) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRoom", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteRoom", ctx, id, pre)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteRoom indicates an expected call of DeleteRoom.
-func (mr *MockRoomServiceMockRecorder) DeleteRoom(ctx, id interface{}) *gomock.Call {
+func (mr *MockRoomServiceMockRecorder) DeleteRoom(ctx, id, pre interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockRoomService)(nil).DeleteRoom), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockRoomService)(nil).DeleteRoom), ctx, id, pre)
 }
 
 // GetAllRooms mocks base method.
-func (m *MockRoomService) GetAllRooms(ctx context.Context, limit, offset int, unitID string) ([]*model.Room, int64, error) {
+func (m *MockRoomService) GetAllRooms(ctx context.Context, query listquery.Query) ([]*model.Room, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllRooms", ctx, limit, offset, unitID)
+	ret := m.ctrl.Call(m, "GetAllRooms", ctx, query)
 	ret0, _ := ret[0].([]*model.Room)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -75,9 +77,9 @@ func (m *MockRoomService) GetAllRooms(ctx context.Context, limit, offset int, un
 }
 
 // GetAllRooms indicates an expected call of GetAllRooms.
-func (mr *MockRoomServiceMockRecorder) GetAllRooms(ctx, limit, offset, unitID interface{}) *gomock.Call {
+func (mr *MockRoomServiceMockRecorder) GetAllRooms(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRooms", reflect.TypeOf((*MockRoomService)(nil).GetAllRooms), ctx, limit, offset, unitID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRooms", reflect.TypeOf((*MockRoomService)(nil).GetAllRooms), ctx, query)
 }
 
 // GetRoomByID mocks base method.
@@ -95,17 +97,32 @@ func (mr *MockRoomServiceMockRecorder) GetRoomByID(ctx, id interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockRoomService)(nil).GetRoomByID), ctx, id)
 }
 
+// PatchRoom mocks base method.
+func (m *MockRoomService) PatchRoom(ctx context.Context, id string, patch service.RoomPatch, pre service.Precondition) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchRoom", ctx, id, patch, pre)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchRoom indicates an expected call of PatchRoom.
+func (mr *MockRoomServiceMockRecorder) PatchRoom(ctx, id, patch, pre interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchRoom", reflect.TypeOf((*MockRoomService)(nil).PatchRoom), ctx, id, patch, pre)
+}
+
 // UpdateRoom mocks base method.
-func (m *MockRoomService) UpdateRoom(ctx context.Context, id, name, unitID string) (*model.Room, error) {
+func (m *MockRoomService) UpdateRoom(ctx context.Context, id, name, unitID string, panoramic *bool, pre service.Precondition) (*model.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRoom", ctx, id, name, unitID)
+	ret := m.ctrl.Call(m, "UpdateRoom", ctx, id, name, unitID, panoramic, pre)
 	ret0, _ := ret[0].(*model.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateRoom indicates an expected call of UpdateRoom.
-func (mr *MockRoomServiceMockRecorder) UpdateRoom(ctx, id, name, unitID interface{}) *gomock.Call {
+func (mr *MockRoomServiceMockRecorder) UpdateRoom(ctx, id, name, unitID, panoramic, pre interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoom", reflect.TypeOf((*MockRoomService)(nil).UpdateRoom), ctx, id, name, unitID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoom", reflect.TypeOf((*MockRoomService)(nil).UpdateRoom), ctx, id, name, unitID, panoramic, pre)
 }
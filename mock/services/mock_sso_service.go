@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sso_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	service "github.com/hfleury/bk_globalshot/internal/service"
+)
+
+// MockSSOService is a mock of SSOService interface.
+type MockSSOService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSSOServiceMockRecorder
+}
+
+// MockSSOServiceMockRecorder is the mock recorder for MockSSOService.
+type MockSSOServiceMockRecorder struct {
+	mock *MockSSOService
+}
+
+// NewMockSSOService creates a new mock instance.
+func NewMockSSOService(ctrl *gomock.Controller) *MockSSOService {
+	mock := &MockSSOService{ctrl: ctrl}
+	mock.recorder = &MockSSOServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSSOService) EXPECT() *MockSSOServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginLogin mocks base method.
+func (m *MockSSOService) BeginLogin(ctx context.Context, companyID string) (*service.SSOLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", ctx, companyID)
+	ret0, _ := ret[0].(*service.SSOLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginLogin indicates an expected call of BeginLogin.
+func (mr *MockSSOServiceMockRecorder) BeginLogin(ctx, companyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockSSOService)(nil).BeginLogin), ctx, companyID)
+}
+
+// CompleteLogin mocks base method.
+func (m *MockSSOService) CompleteLogin(ctx context.Context, state, code string, client service.ClientInfo) (*service.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, state, code, client)
+	ret0, _ := ret[0].(*service.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockSSOServiceMockRecorder) CompleteLogin(ctx, state, code, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockSSOService)(nil).CompleteLogin), ctx, state, code, client)
+}
+
+// ConfigureCompany mocks base method.
+func (m *MockSSOService) ConfigureCompany(ctx context.Context, companyID, issuer, clientID, clientSecret string) (*model.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureCompany", ctx, companyID, issuer, clientID, clientSecret)
+	ret0, _ := ret[0].(*model.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfigureCompany indicates an expected call of ConfigureCompany.
+func (mr *MockSSOServiceMockRecorder) ConfigureCompany(ctx, companyID, issuer, clientID, clientSecret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureCompany", reflect.TypeOf((*MockSSOService)(nil).ConfigureCompany), ctx, companyID, issuer, clientID, clientSecret)
+}
+
+// DisableCompany mocks base method.
+func (m *MockSSOService) DisableCompany(ctx context.Context, companyID string) (*model.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableCompany", ctx, companyID)
+	ret0, _ := ret[0].(*model.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableCompany indicates an expected call of DisableCompany.
+func (mr *MockSSOServiceMockRecorder) DisableCompany(ctx, companyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableCompany", reflect.TypeOf((*MockSSOService)(nil).DisableCompany), ctx, companyID)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: trash_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/hfleury/bk_globalshot/internal/model"
+	listquery "github.com/hfleury/bk_globalshot/pkg/listquery"
+)
+
+// MockTrashService is a mock of TrashService interface.
+type MockTrashService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashServiceMockRecorder
+}
+
+// MockTrashServiceMockRecorder is the mock recorder for MockTrashService.
+type MockTrashServiceMockRecorder struct {
+	mock *MockTrashService
+}
+
+// NewMockTrashService creates a new mock instance.
+func NewMockTrashService(ctrl *gomock.Controller) *MockTrashService {
+	mock := &MockTrashService{ctrl: ctrl}
+	mock.recorder = &MockTrashServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrashService) EXPECT() *MockTrashServiceMockRecorder {
+	return m.recorder
+}
+
+// GetTrash mocks base method.
+func (m *MockTrashService) GetTrash(ctx context.Context, query listquery.Query) ([]*model.TrashItem, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", ctx, query)
+	ret0, _ := ret[0].([]*model.TrashItem)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTrashServiceMockRecorder) GetTrash(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTrashService)(nil).GetTrash), ctx, query)
+}
+
+// Purge mocks base method.
+func (m *MockTrashService) Purge(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTrashServiceMockRecorder) Purge(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTrashService)(nil).Purge), ctx)
+}
+
+// Restore mocks base method.
+func (m *MockTrashService) Restore(ctx context.Context, itemType model.TrashItemType, id string) (*model.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, itemType, id)
+	ret0, _ := ret[0].(*model.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTrashServiceMockRecorder) Restore(ctx, itemType, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTrashService)(nil).Restore), ctx, itemType, id)
+}
+
+// Start mocks base method.
+func (m *MockTrashService) Start(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start", ctx)
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockTrashServiceMockRecorder) Start(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockTrashService)(nil).Start), ctx)
+}
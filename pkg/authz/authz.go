@@ -0,0 +1,156 @@
+// Package authz decides what a signed in user may do. Permissions are granted to roles in one
+// declarative table, each with a scope saying how far the grant reaches, and evaluated
+// against the tenant of the resource at hand.
+package authz
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+)
+
+var ErrForbidden = errors.New("access to this resource is forbidden")
+
+// Permission names an action on a kind of resource, written "<resource>:<action>".
+type Permission string
+
+// Scope says how far a granted permission reaches.
+type Scope int
+
+const (
+	// ScopeNone means the permission is not granted.
+	ScopeNone Scope = iota
+	// ScopeOwn reaches only resources assigned to the subject: a customer's units and
+	// everything inside them, or the subject's own account.
+	ScopeOwn
+	// ScopeCompany reaches every resource of the subject's company.
+	ScopeCompany
+	// ScopeAll reaches every tenant.
+	ScopeAll
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeOwn:
+		return "own"
+	case ScopeCompany:
+		return "company"
+	case ScopeAll:
+		return "all"
+	default:
+		return "none"
+	}
+}
+
+func (s Scope) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Subject is who is asking.
+type Subject struct {
+	ID        string
+	Role      string
+	CompanyID string
+	// Scopes narrows the grants of the role to these permissions, as API keys do.
+	// Nil leaves the role's grants whole.
+	Scopes []Permission
+}
+
+// SubjectFromUser builds the subject of an authenticated user.
+func SubjectFromUser(user *model.User) Subject {
+	sub := Subject{ID: user.ID, Role: user.Role, CompanyID: user.CompanyID}
+	if user.Scopes != nil {
+		sub.Scopes = make([]Permission, len(user.Scopes))
+		for i, scope := range user.Scopes {
+			sub.Scopes[i] = Permission(scope)
+		}
+	}
+	return sub
+}
+
+// inScope reports whether the subject's scopes, if any, include the permission.
+func (s Subject) inScope(perm Permission) bool {
+	if s.Scopes == nil {
+		return true
+	}
+	for _, scope := range s.Scopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource is what the subject wants to act on, described by who it belongs to.
+type Resource struct {
+	// CompanyID is the tenant owning the resource.
+	CompanyID string
+	// OwnerID is the user the resource is assigned to, if any: the customer of a unit,
+	// or the account itself for users.
+	OwnerID string
+	// Restricted resources are out of reach of scoped grants, only ScopeAll may act on them.
+	// Admin accounts are restricted so company users cannot touch or create them.
+	Restricted bool
+}
+
+// Grants maps each role to the permissions it holds and their scope.
+type Grants map[model.Role]map[Permission]Scope
+
+// Grant is one permission held by a subject, as listed to the frontend.
+type Grant struct {
+	Permission Permission `json:"permission"`
+	Scope      Scope      `json:"scope"`
+}
+
+type Policy struct {
+	grants Grants
+}
+
+func NewPolicy(grants Grants) *Policy {
+	return &Policy{grants: grants}
+}
+
+// Scope returns how far the subject holds the permission.
+func (p *Policy) Scope(sub Subject, perm Permission) Scope {
+	if !sub.inScope(perm) {
+		return ScopeNone
+	}
+	return p.grants[model.Role(sub.Role)][perm]
+}
+
+// Can reports whether the subject holds the permission on at least some resources.
+// It is the coarse check done before the resource is known.
+func (p *Policy) Can(sub Subject, perm Permission) bool {
+	return p.Scope(sub, perm) != ScopeNone
+}
+
+// Authorize returns ErrForbidden unless the subject may use the permission on the resource.
+func (p *Policy) Authorize(sub Subject, perm Permission, res Resource) error {
+	switch p.Scope(sub, perm) {
+	case ScopeAll:
+		return nil
+	case ScopeCompany:
+		if !res.Restricted && sub.CompanyID != "" && res.CompanyID == sub.CompanyID {
+			return nil
+		}
+	case ScopeOwn:
+		if !res.Restricted && sub.ID != "" && res.OwnerID == sub.ID {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// Grants lists every permission the subject holds, sorted by name.
+func (p *Policy) Grants(sub Subject) []Grant {
+	held := p.grants[model.Role(sub.Role)]
+	grants := make([]Grant, 0, len(held))
+	for perm, scope := range held {
+		if scope != ScopeNone && sub.inScope(perm) {
+			grants = append(grants, Grant{Permission: perm, Scope: scope})
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Permission < grants[j].Permission })
+	return grants
+}
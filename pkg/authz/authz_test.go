@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/hfleury/bk_globalshot/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	admin := Subject{ID: "admin-1", Role: string(model.RoleAdmin)}
+	company := Subject{ID: "user-1", Role: string(model.RoleCompany), CompanyID: "company-123"}
+	homeless := Subject{ID: "user-2", Role: string(model.RoleCompany)}
+	customer := Subject{ID: "customer-1", Role: string(model.RoleCustomer), CompanyID: "company-123"}
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		perm     Permission
+		resource Resource
+		allowed  bool
+	}{
+		{"Admin reaches every tenant", admin, SiteDelete, Resource{CompanyID: "company-456"}, true},
+		{"Admin reaches restricted resources", admin, UserUpdate, Resource{CompanyID: "company-123", Restricted: true}, true},
+		{"Company reaches own tenant", company, SiteUpdate, Resource{CompanyID: "company-123"}, true},
+		{"Company cannot reach other tenant", company, SiteUpdate, Resource{CompanyID: "company-456"}, false},
+		{"Company cannot reach restricted resources", company, UserUpdate, Resource{CompanyID: "company-123", Restricted: true}, false},
+		{"Company without company reaches nothing", homeless, SiteRead, Resource{}, false},
+		{"Company cannot regenerate media", company, MediaRegenerate, Resource{CompanyID: "company-123"}, false},
+		{"Company cannot delete its company", company, CompanyDelete, Resource{CompanyID: "company-123"}, false},
+		{"Customer reads what is assigned to them", customer, UnitRead, Resource{CompanyID: "company-123", OwnerID: "customer-1"}, true},
+		{"Customer cannot read the rest of the company", customer, UnitRead, Resource{CompanyID: "company-123"}, false},
+		{"Customer cannot change what is assigned to them", customer, UnitUpdate, Resource{CompanyID: "company-123", OwnerID: "customer-1"}, false},
+		{"Unknown role reaches nothing", Subject{ID: "x", Role: "guest"}, SiteRead, Resource{OwnerID: "x"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Default.Authorize(tt.subject, tt.perm, tt.resource)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestGrants(t *testing.T) {
+	grants := Default.Grants(Subject{ID: "customer-1", Role: string(model.RoleCustomer)})
+
+	assert.Equal(t, []Grant{
+		{Permission: MediaRead, Scope: ScopeOwn},
+		{Permission: RoomRead, Scope: ScopeOwn},
+		{Permission: SiteRead, Scope: ScopeOwn},
+		{Permission: UnitRead, Scope: ScopeOwn},
+		{Permission: UserRead, Scope: ScopeOwn},
+	}, grants)
+	assert.Len(t, Default.Grants(Subject{Role: string(model.RoleAdmin)}), len(Permissions))
+}
@@ -0,0 +1,118 @@
+package authz
+
+import "github.com/hfleury/bk_globalshot/internal/model"
+
+const (
+	CompanyRead   Permission = "company:read"
+	CompanyCreate Permission = "company:create"
+	CompanyUpdate Permission = "company:update"
+	CompanyDelete Permission = "company:delete"
+
+	SiteRead   Permission = "site:read"
+	SiteCreate Permission = "site:create"
+	SiteUpdate Permission = "site:update"
+	SiteDelete Permission = "site:delete"
+
+	UnitRead   Permission = "unit:read"
+	UnitCreate Permission = "unit:create"
+	UnitUpdate Permission = "unit:update"
+	UnitDelete Permission = "unit:delete"
+
+	RoomRead   Permission = "room:read"
+	RoomCreate Permission = "room:create"
+	RoomUpdate Permission = "room:update"
+	RoomDelete Permission = "room:delete"
+
+	MediaRead       Permission = "media:read"
+	MediaUpload     Permission = "media:upload"
+	MediaDelete     Permission = "media:delete"
+	MediaRegenerate Permission = "media:regenerate"
+
+	UserRead   Permission = "user:read"
+	UserCreate Permission = "user:create"
+	UserUpdate Permission = "user:update"
+	UserDelete Permission = "user:delete"
+	// UserImpersonate lets support sign in as someone else, admins only
+	UserImpersonate Permission = "user:impersonate"
+
+	APIKeyRead   Permission = "api_key:read"
+	APIKeyCreate Permission = "api_key:create"
+	APIKeyDelete Permission = "api_key:delete"
+
+	AuditRead Permission = "audit:read"
+
+	// TrashRead lists what was deleted, admins only. Restoring takes the delete permission
+	// of the resource instead.
+	TrashRead Permission = "trash:read"
+)
+
+// Permissions lists every permission the API knows about.
+var Permissions = []Permission{
+	CompanyRead, CompanyCreate, CompanyUpdate, CompanyDelete,
+	SiteRead, SiteCreate, SiteUpdate, SiteDelete,
+	UnitRead, UnitCreate, UnitUpdate, UnitDelete,
+	RoomRead, RoomCreate, RoomUpdate, RoomDelete,
+	MediaRead, MediaUpload, MediaDelete, MediaRegenerate,
+	UserRead, UserCreate, UserUpdate, UserDelete, UserImpersonate,
+	APIKeyRead, APIKeyCreate, APIKeyDelete,
+	AuditRead,
+	TrashRead,
+}
+
+// DefaultGrants is who may do what. Admins run the platform, company users manage everything
+// under their own construction sites, customers follow the progress of the units they bought.
+var DefaultGrants = Grants{
+	model.RoleAdmin: all(ScopeAll),
+	model.RoleCompany: {
+		CompanyRead:   ScopeCompany,
+		CompanyUpdate: ScopeCompany,
+
+		SiteRead:   ScopeCompany,
+		SiteCreate: ScopeCompany,
+		SiteUpdate: ScopeCompany,
+		SiteDelete: ScopeCompany,
+
+		UnitRead:   ScopeCompany,
+		UnitCreate: ScopeCompany,
+		UnitUpdate: ScopeCompany,
+		UnitDelete: ScopeCompany,
+
+		RoomRead:   ScopeCompany,
+		RoomCreate: ScopeCompany,
+		RoomUpdate: ScopeCompany,
+		RoomDelete: ScopeCompany,
+
+		MediaRead:   ScopeCompany,
+		MediaUpload: ScopeCompany,
+		MediaDelete: ScopeCompany,
+
+		UserRead:   ScopeCompany,
+		UserCreate: ScopeCompany,
+		UserUpdate: ScopeCompany,
+		UserDelete: ScopeCompany,
+
+		APIKeyRead:   ScopeCompany,
+		APIKeyCreate: ScopeCompany,
+		APIKeyDelete: ScopeCompany,
+
+		AuditRead: ScopeCompany,
+	},
+	model.RoleCustomer: {
+		SiteRead:  ScopeOwn,
+		UnitRead:  ScopeOwn,
+		RoomRead:  ScopeOwn,
+		MediaRead: ScopeOwn,
+		UserRead:  ScopeOwn,
+	},
+}
+
+// Default is the policy the API enforces.
+var Default = NewPolicy(DefaultGrants)
+
+func all(scope Scope) map[Permission]Scope {
+	grants := make(map[Permission]Scope, len(Permissions))
+	for _, perm := range Permissions {
+		grants[perm] = scope
+	}
+	return grants
+}
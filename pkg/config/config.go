@@ -3,32 +3,206 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	DbDsn     string
-	ServerPort string
-	CfgToken  ConfigToken
+	DbDsn        string
+	ServerPort   string
+	CfgToken     ConfigToken
+	CfgStorage   ConfigStorage
+	CfgMedia     ConfigMedia
+	CfgMailer    ConfigMailer
+	CfgMFA       ConfigMFA
+	CfgRateLimit ConfigRateLimit
+	CfgOIDC      ConfigOIDC
+	CfgTrash     ConfigTrash
+
+	// Addresses or CIDRs of the proxies allowed to tell the client IP through X-Forwarded-For,
+	// none by default so the address the request comes from is used
+	TrustedProxies []string
 }
 
 type ConfigToken struct {
-	TokenKey    string
-	TokenExpiry time.Duration
+	TokenKey            string        // Single signing key, used when no key ring file is configured
+	KeyRingFile         string        // JSON key ring managed with cli/paseto, allows rotating keys
+	TokenExpiry         time.Duration // Lifetime of access tokens, keep it short, sessions are renewed with refresh tokens
+	RefreshTokenExpiry  time.Duration
+	ResetTokenExpiry    time.Duration
+	ResetURL            string // Frontend page the reset link points to, the token is added as ?token=
+	InviteTokenExpiry   time.Duration
+	InviteURL           string        // Frontend page the invitation link points to, the token is added as ?token=
+	ImpersonationExpiry time.Duration // Lifetime of the token an admin gets to act as another user, it is not renewable
+}
+
+type ConfigMFA struct {
+	Issuer          string   // Name authenticator apps show next to the account
+	RequiredRoles   []string // Roles that cannot sign in without a second factor
+	ChallengeExpiry time.Duration
+}
+
+type ConfigOIDC struct {
+	RedirectURL string // Frontend page providers send users back to, it posts the code to /auth/oidc/callback
+	StateExpiry time.Duration
+	HTTPTimeout time.Duration // For discovery, key and token requests to identity providers
+	SecretKey   string        // Hex encoded 32 byte key the client secrets of providers are sealed with
+}
+
+type ConfigTrash struct {
+	Retention     time.Duration // How long deleted companies, sites, units and rooms can be restored
+	PurgeInterval time.Duration // How often what outlived the retention is deleted for good
+}
+
+type ConfigRateLimit struct {
+	Driver         string // "memory" or "postgres", the latter when running several replicas
+	IPPerMinute    int    // Auth requests a client address may make, after the burst
+	IPBurst        int
+	EmailPerMinute int // Login attempts against a single email, whoever makes them
+	EmailBurst     int
+	// Failed logins in a row before an account is locked, each further failure doubles
+	// the lock from LockoutBase up to LockoutMax. Failures older than LockoutWindow are forgotten.
+	LockoutThreshold int
+	LockoutBase      time.Duration
+	LockoutMax       time.Duration
+	LockoutWindow    time.Duration
+}
+
+type ConfigStorage struct {
+	Driver   string // "local" or "s3"
+	LocalDir string
+	S3       ConfigS3
+}
+
+type ConfigMedia struct {
+	ProcessingWorkers   int
+	ProcessingQueueSize int
+	TileSize            int // Edge of a cube map tile in pixels
+	TileMaxFaceSize     int // Largest cube face rendered for the tile pyramid
+
+	MaxUploadSize  int64 // Bytes accepted in one upload request
+	MaxImagePixels int   // Width × height beyond which images are rejected before decoding
+}
+
+type ConfigMailer struct {
+	Driver    string // "outbox" or "smtp"
+	From      string
+	OutboxDir string // Where the outbox driver writes messages, empty only logs them
+	SMTP      ConfigSMTP
+}
+
+type ConfigSMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+type ConfigS3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool // Required by most self-hosted stand-ins such as MinIO
 }
 
 func LoadConfig() Config {
 	log.Println("Loading config from environment")
 
 	cfgToken := ConfigToken{
-		TokenKey:    getEnv("TOKEN_PRIVATE_KEY", "8a23b8605a2b0a753cc84e3e8154833d3d82039b97bc124d2f4ca17d1590df88e881b06f9cc476dbbb3ba97337dd6e4626d53b6c36b2178da1824ea4ee61e6d8"),
-		TokenExpiry: time.Hour * 72,
+		TokenKey:            getEnv("TOKEN_PRIVATE_KEY", "8a23b8605a2b0a753cc84e3e8154833d3d82039b97bc124d2f4ca17d1590df88e881b06f9cc476dbbb3ba97337dd6e4626d53b6c36b2178da1824ea4ee61e6d8"),
+		KeyRingFile:         getEnv("TOKEN_KEYRING_FILE", ""),
+		TokenExpiry:         getEnvDuration("TOKEN_EXPIRY", 15*time.Minute),
+		RefreshTokenExpiry:  getEnvDuration("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour),
+		ResetTokenExpiry:    getEnvDuration("RESET_TOKEN_EXPIRY", time.Hour),
+		ResetURL:            getEnv("RESET_PASSWORD_URL", "http://localhost:5173/reset-password"),
+		InviteTokenExpiry:   getEnvDuration("INVITE_TOKEN_EXPIRY", 7*24*time.Hour),
+		InviteURL:           getEnv("INVITE_URL", "http://localhost:5173/accept-invitation"),
+		ImpersonationExpiry: getEnvDuration("IMPERSONATION_EXPIRY", 30*time.Minute),
+	}
+
+	cfgStorage := ConfigStorage{
+		Driver:   getEnv("STORAGE_DRIVER", "local"),
+		LocalDir: getEnv("STORAGE_LOCAL_DIR", "./data/media"),
+		S3: ConfigS3{
+			Endpoint:  getEnv("S3_ENDPOINT", ""),
+			Region:    getEnv("S3_REGION", ""),
+			Bucket:    getEnv("S3_BUCKET", ""),
+			AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("S3_SECRET_KEY", ""),
+			UseSSL:    getEnvBool("S3_USE_SSL", true),
+			PathStyle: getEnvBool("S3_PATH_STYLE", false),
+		},
+	}
+
+	cfgMedia := ConfigMedia{
+		ProcessingWorkers:   getEnvInt("MEDIA_PROCESSING_WORKERS", 2),
+		ProcessingQueueSize: getEnvInt("MEDIA_PROCESSING_QUEUE_SIZE", 100),
+		TileSize:            getEnvInt("MEDIA_TILE_SIZE", 512),
+		TileMaxFaceSize:     getEnvInt("MEDIA_TILE_MAX_FACE_SIZE", 4096),
+
+		MaxUploadSize:  int64(getEnvInt("MEDIA_MAX_UPLOAD_SIZE", 100<<20)),
+		MaxImagePixels: getEnvInt("MEDIA_MAX_IMAGE_PIXELS", 16384*8192),
+	}
+
+	cfgMailer := ConfigMailer{
+		Driver:    getEnv("MAILER_DRIVER", "outbox"),
+		From:      getEnv("MAILER_FROM", "GlobalShot <no-reply@globalshot.local>"),
+		OutboxDir: getEnv("MAILER_OUTBOX_DIR", "./data/outbox"),
+		SMTP: ConfigSMTP{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+		},
+	}
+
+	cfgOIDC := ConfigOIDC{
+		RedirectURL: getEnv("OIDC_REDIRECT_URL", "http://localhost:5173/sso/callback"),
+		StateExpiry: getEnvDuration("OIDC_STATE_EXPIRY", 10*time.Minute),
+		HTTPTimeout: getEnvDuration("OIDC_HTTP_TIMEOUT", 10*time.Second),
+		SecretKey:   getEnv("OIDC_SECRET_KEY", "6d1f3a0c9b8e47d2a5f0c3e1b7d9a4f28c6e0b3d5a7f9e1c2b4d6f8a0c3e5b7d"),
+	}
+
+	cfgMFA := ConfigMFA{
+		Issuer:          getEnv("MFA_ISSUER", "GlobalShot"),
+		RequiredRoles:   getEnvList("MFA_REQUIRED_ROLES", nil),
+		ChallengeExpiry: getEnvDuration("MFA_CHALLENGE_EXPIRY", 5*time.Minute),
+	}
+
+	cfgTrash := ConfigTrash{
+		Retention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+	}
+
+	cfgRateLimit := ConfigRateLimit{
+		Driver:           getEnv("RATE_LIMIT_DRIVER", "memory"),
+		IPPerMinute:      getEnvInt("RATE_LIMIT_IP_PER_MINUTE", 20),
+		IPBurst:          getEnvInt("RATE_LIMIT_IP_BURST", 20),
+		EmailPerMinute:   getEnvInt("RATE_LIMIT_EMAIL_PER_MINUTE", 5),
+		EmailBurst:       getEnvInt("RATE_LIMIT_EMAIL_BURST", 5),
+		LockoutThreshold: getEnvInt("LOCKOUT_THRESHOLD", 5),
+		LockoutBase:      getEnvDuration("LOCKOUT_BASE", time.Minute),
+		LockoutMax:       getEnvDuration("LOCKOUT_MAX", time.Hour),
+		LockoutWindow:    getEnvDuration("LOCKOUT_WINDOW", 24*time.Hour),
 	}
 
 	return Config{
-		DbDsn:      getEnv("DB_DSN", "user=globalshotuser password=globalshotsecret dbname=globalshotdb sslmode=disable host=127.0.0.1 port=5432"),
-		ServerPort: getEnv("PORT", "8080"),
-		CfgToken:   cfgToken,
+		DbDsn:        getEnv("DB_DSN", "user=globalshotuser password=globalshotsecret dbname=globalshotdb sslmode=disable host=127.0.0.1 port=5432"),
+		ServerPort:   getEnv("PORT", "8080"),
+		CfgToken:     cfgToken,
+		CfgStorage:   cfgStorage,
+		CfgMedia:     cfgMedia,
+		CfgMailer:    cfgMailer,
+		CfgMFA:       cfgMFA,
+		CfgRateLimit: cfgRateLimit,
+		CfgOIDC:      cfgOIDC,
+		CfgTrash:     cfgTrash,
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
 	}
 }
 
@@ -38,3 +212,57 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList reads a comma separated list, blank entries are dropped.
+func getEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid boolean for %s, using default %t", key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid integer for %s, using default %d", key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid duration for %s, using default %s", key, fallback)
+		return fallback
+	}
+	return parsed
+}
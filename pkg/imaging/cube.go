@@ -0,0 +1,105 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// CubeFace names one side of a cube map, using the single letter ids 360° viewers expect.
+type CubeFace string
+
+const (
+	CubeFront CubeFace = "f"
+	CubeRight CubeFace = "r"
+	CubeBack  CubeFace = "b"
+	CubeLeft  CubeFace = "l"
+	CubeUp    CubeFace = "u"
+	CubeDown  CubeFace = "d"
+)
+
+// CubeFaces lists the faces in the order tiles are generated and advertised.
+var CubeFaces = []CubeFace{CubeFront, CubeRight, CubeBack, CubeLeft, CubeUp, CubeDown}
+
+// View returns the 90° camera that renders the face. The up face has its bottom edge against
+// the front face and the down face its top edge, as viewers lay out cube maps.
+func (f CubeFace) View() View {
+	switch f {
+	case CubeRight:
+		return View{Yaw: 90, FOV: 90}
+	case CubeBack:
+		return View{Yaw: 180, FOV: 90}
+	case CubeLeft:
+		return View{Yaw: -90, FOV: 90}
+	case CubeUp:
+		return View{Pitch: 90, FOV: 90}
+	case CubeDown:
+		return View{Pitch: -90, FOV: 90}
+	default:
+		return View{FOV: 90}
+	}
+}
+
+func IsCubeFace(value string) bool {
+	for _, face := range CubeFaces {
+		if string(face) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CubeLevel is one zoom level of a cube map pyramid, each face is Size pixels square
+// and split into square tiles of TileSize pixels.
+type CubeLevel struct {
+	Size     int
+	TileSize int
+}
+
+// Tiles returns how many tiles make up one side of a face.
+func (l CubeLevel) Tiles() int {
+	return (l.Size + l.TileSize - 1) / l.TileSize
+}
+
+// TileRect returns the pixel bounds of the tile at column x, row y of a face.
+func (l CubeLevel) TileRect(x, y int) image.Rectangle {
+	return image.Rect(x*l.TileSize, y*l.TileSize, (x+1)*l.TileSize, (y+1)*l.TileSize).
+		Intersect(image.Rect(0, 0, l.Size, l.Size))
+}
+
+// CubeLevels plans the pyramid for an equirectangular image, smallest level first.
+// A cube face covers a quarter of the panorama's width, so that is the most detail
+// worth keeping; it is capped at maxFaceSize and snapped to whole tiles. Each level
+// below halves the face until a single tile holds it.
+func CubeLevels(equirectWidth, tileSize, maxFaceSize int) []CubeLevel {
+	natural := equirectWidth / 4
+	if maxFaceSize > 0 && natural > maxFaceSize {
+		natural = maxFaceSize
+	}
+	if natural < 1 {
+		return nil
+	}
+	if natural <= tileSize {
+		return []CubeLevel{{Size: natural, TileSize: natural}}
+	}
+
+	var levels []CubeLevel
+	for size := natural / tileSize * tileSize; ; size = size / 2 / tileSize * tileSize {
+		levels = append([]CubeLevel{{Size: size, TileSize: tileSize}}, levels...)
+		if size <= tileSize {
+			return levels
+		}
+	}
+}
+
+// RenderCubeFace renders one face of the cube map at the given size.
+func RenderCubeFace(src *image.RGBA, face CubeFace, size int) *image.RGBA {
+	return Perspective(src, face.View(), size, size)
+}
+
+// ResizeSquare scales a face down to the given size for the lower levels of the pyramid.
+func ResizeSquare(src image.Image, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
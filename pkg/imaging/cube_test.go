@@ -0,0 +1,54 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCubeLevels(t *testing.T) {
+	tests := []struct {
+		name          string
+		width         int
+		maxFaceSize   int
+		expectedSizes []int
+	}{
+		{"Halves down to a single tile", 16000, 0, []int{512, 1536, 3584}},
+		{"Capped at the largest face", 16000, 2048, []int{512, 1024, 2048}},
+		{"Small panorama fits one tile", 1200, 4096, []int{300}},
+		{"Nothing to tile", 2, 4096, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := CubeLevels(tt.width, 512, tt.maxFaceSize)
+
+			var sizes []int
+			for _, level := range levels {
+				sizes = append(sizes, level.Size)
+				assert.Zero(t, level.Size%level.TileSize, "faces are made of whole tiles")
+			}
+			assert.Equal(t, tt.expectedSizes, sizes)
+		})
+	}
+}
+
+func TestCubeLevelTiles(t *testing.T) {
+	level := CubeLevel{Size: 1536, TileSize: 512}
+	assert.Equal(t, 3, level.Tiles())
+	assert.Equal(t, image.Rect(1024, 512, 1536, 1024), level.TileRect(2, 1))
+}
+
+func TestRenderCubeFace(t *testing.T) {
+	pano := quadrantPanorama(400)
+
+	// The right face looks at yaw 90°, the boundary between blue and white, so its left half is blue
+	face := RenderCubeFace(pano, CubeRight, 64)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), face.Bounds())
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, face.RGBAAt(16, 32))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, face.RGBAAt(48, 32))
+
+	assert.Equal(t, image.Rect(0, 0, 16, 16), ResizeSquare(face, 16).Bounds())
+}
@@ -0,0 +1,176 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"math"
+
+	// Register decoders for the formats accepted on upload
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const DefaultJPEGQuality = 85
+
+// ErrImageTooLarge is returned for images over the pixel limit given to CheckSize.
+var ErrImageTooLarge = errors.New("image exceeds the pixel limit")
+
+// View describes a virtual pinhole camera looking into an equirectangular panorama.
+// Yaw and pitch are in degrees, yaw 0 being the centre of the panorama and positive pitch looking up.
+type View struct {
+	Yaw   float64
+	Pitch float64
+	FOV   float64 // Horizontal field of view in degrees
+}
+
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// CheckSize reads only the header of the image and rejects it when width × height exceeds
+// maxPixels, 0 disables the limit. Decoding allocates the whole bitmap up front, so a small
+// file declaring huge dimensions has to be caught before that.
+func CheckSize(r io.Reader, maxPixels int) error {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return fmt.Errorf("failed to decode image header: %w", err)
+	}
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+// DecodeLimited decodes the image once CheckSize accepted it.
+func DecodeLimited(r io.Reader, maxPixels int) (image.Image, error) {
+	var header bytes.Buffer
+	if err := CheckSize(io.TeeReader(r, &header), maxPixels); err != nil {
+		return nil, err
+	}
+	return Decode(io.MultiReader(&header, r))
+}
+
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// ResizeToWidth scales src to the given width keeping its aspect ratio. Images that are
+// already narrower are returned untouched, we never upscale.
+func ResizeToWidth(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= width {
+		return src
+	}
+	height := int(math.Round(float64(b.Dy()) * float64(width) / float64(b.Dx())))
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// ToRGBA returns src as *image.RGBA, converting it only when needed.
+func ToRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// Perspective renders a flat, rectilinear view of an equirectangular panorama.
+func Perspective(src *image.RGBA, view View, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	yaw := view.Yaw * math.Pi / 180
+	pitch := view.Pitch * math.Pi / 180
+	halfW := math.Tan(view.FOV * math.Pi / 360)
+	halfH := halfW * float64(height) / float64(width)
+
+	sinYaw, cosYaw := math.Sin(yaw), math.Cos(yaw)
+	sinPitch, cosPitch := math.Sin(pitch), math.Cos(pitch)
+
+	for y := 0; y < height; y++ {
+		ny := (1 - 2*(float64(y)+0.5)/float64(height)) * halfH
+		for x := 0; x < width; x++ {
+			nx := (2*(float64(x)+0.5)/float64(width) - 1) * halfW
+
+			// Camera ray, tilted by pitch around the x axis then turned by yaw around the y axis
+			dy := ny*cosPitch + sinPitch
+			dz := -ny*sinPitch + cosPitch
+			dx := nx*cosYaw + dz*sinYaw
+			dz = -nx*sinYaw + dz*cosYaw
+
+			lon := math.Atan2(dx, dz)
+			lat := math.Atan2(dy, math.Hypot(dx, dz))
+			r, g, b, a := sampleEquirect(src, lon, lat)
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = r
+			dst.Pix[i+1] = g
+			dst.Pix[i+2] = b
+			dst.Pix[i+3] = a
+		}
+	}
+	return dst
+}
+
+// sampleEquirect bilinearly samples the panorama at the given longitude/latitude in radians.
+// Longitude wraps around the seam, latitude is clamped at the poles.
+func sampleEquirect(src *image.RGBA, lon, lat float64) (uint8, uint8, uint8, uint8) {
+	w := src.Rect.Dx()
+	h := src.Rect.Dy()
+
+	u := (lon/(2*math.Pi)+0.5)*float64(w) - 0.5
+	v := (0.5-lat/math.Pi)*float64(h) - 0.5
+
+	x0 := int(math.Floor(u))
+	y0 := int(math.Floor(v))
+	fx := u - float64(x0)
+	fy := v - float64(y0)
+
+	wrap := func(x int) int {
+		x %= w
+		if x < 0 {
+			x += w
+		}
+		return x
+	}
+	clamp := func(y int) int {
+		if y < 0 {
+			return 0
+		}
+		if y >= h {
+			return h - 1
+		}
+		return y
+	}
+
+	xa, xb := wrap(x0), wrap(x0+1)
+	ya, yb := clamp(y0), clamp(y0+1)
+
+	p00 := src.PixOffset(xa, ya)
+	p10 := src.PixOffset(xb, ya)
+	p01 := src.PixOffset(xa, yb)
+	p11 := src.PixOffset(xb, yb)
+
+	var out [4]uint8
+	for c := 0; c < 4; c++ {
+		top := float64(src.Pix[p00+c])*(1-fx) + float64(src.Pix[p10+c])*fx
+		bottom := float64(src.Pix[p01+c])*(1-fx) + float64(src.Pix[p11+c])*fx
+		out[c] = uint8(math.Round(top*(1-fy) + bottom*fy))
+	}
+	return out[0], out[1], out[2], out[3]
+}
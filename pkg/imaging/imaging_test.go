@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quadrantPanorama paints each 90° of longitude a different colour:
+// [-180,-90) red, [-90,0) green, [0,90) blue, [90,180) white.
+func quadrantPanorama(width int) *image.RGBA {
+	colors := []color.RGBA{
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 255, 255, 255},
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, width/2))
+	for y := 0; y < width/2; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, colors[x*4/width])
+		}
+	}
+	return img
+}
+
+func TestPerspective(t *testing.T) {
+	pano := quadrantPanorama(400)
+
+	tests := []struct {
+		name     string
+		yaw      float64
+		expected color.RGBA
+	}{
+		{"Looking ahead-right lands in the blue quadrant", 45, color.RGBA{0, 0, 255, 255}},
+		{"Looking ahead-left lands in the green quadrant", -45, color.RGBA{0, 255, 0, 255}},
+		{"Looking behind-right lands in the white quadrant", 135, color.RGBA{255, 255, 255, 255}},
+		{"Looking behind-left wraps across the seam into red", -135, color.RGBA{255, 0, 0, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := Perspective(pano, View{Yaw: tt.yaw, FOV: 60}, 32, 24)
+			assert.Equal(t, image.Rect(0, 0, 32, 24), view.Bounds())
+			assert.Equal(t, tt.expected, view.RGBAAt(16, 12))
+		})
+	}
+}
+
+func TestResizeToWidth(t *testing.T) {
+	pano := quadrantPanorama(400)
+
+	resized := ResizeToWidth(pano, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), resized.Bounds())
+
+	// Never upscale
+	assert.Same(t, pano, ResizeToWidth(pano, 800))
+}
+
+func TestEncodeDecodeJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, quadrantPanorama(64), DefaultJPEGQuality))
+
+	img, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 32), img.Bounds())
+}
+
+func TestCheckSize(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, quadrantPanorama(64), DefaultJPEGQuality))
+	encoded := buf.Bytes()
+
+	assert.NoError(t, CheckSize(bytes.NewReader(encoded), 64*32))
+	assert.NoError(t, CheckSize(bytes.NewReader(encoded), 0), "No limit")
+	assert.ErrorIs(t, CheckSize(bytes.NewReader(encoded), 64*32-1), ErrImageTooLarge)
+
+	_, err := DecodeLimited(bytes.NewReader(encoded), 1000)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	// The header read by the check is replayed to the decoder
+	img, err := DecodeLimited(bytes.NewReader(encoded), 64*32)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 32), img.Bounds())
+}
@@ -0,0 +1,165 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+const ProjectionEquirectangular = "equirectangular"
+
+// MetadataHeaderSize is how many leading bytes of a file ReadMetadata needs. EXIF and XMP
+// live in APP segments at the start of a JPEG, each capped at 64KB.
+const MetadataHeaderSize = 512 * 1024
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+	// GPano properties may be serialized as attributes or as child elements
+	xmpAttr = func(name string) *regexp.Regexp {
+		return regexp.MustCompile(`GPano:` + name + `\s*=\s*["']([^"']*)["']|<GPano:` + name + `>([^<]*)</GPano:` + name + `>`)
+	}
+	xmpProjectionType = xmpAttr("ProjectionType")
+	xmpPoseHeading    = xmpAttr("PoseHeadingDegrees")
+)
+
+// Metadata is what we could learn about a capture from its embedded EXIF/XMP.
+// Every field is optional, cameras and editing tools vary wildly in what they keep.
+type Metadata struct {
+	TakenAt        *time.Time
+	Latitude       *float64
+	Longitude      *float64
+	CameraMake     string
+	CameraModel    string
+	ProjectionType string   // GPano:ProjectionType, e.g. "equirectangular"
+	PoseHeading    *float64 // GPano:PoseHeadingDegrees, compass heading of the image centre
+	Width          int
+	Height         int
+}
+
+// IsEquirectangular reports whether the capture is a full 360° panorama. The GPano projection
+// wins when present, otherwise we fall back to the 2:1 aspect ratio every equirectangular image has.
+func (m *Metadata) IsEquirectangular() bool {
+	if m.ProjectionType != "" {
+		return strings.EqualFold(m.ProjectionType, ProjectionEquirectangular)
+	}
+	return m.Width > 0 && m.Width == 2*m.Height
+}
+
+// ReadMetadata extracts EXIF and GPano XMP metadata from the first MetadataHeaderSize bytes of an image.
+// It never fails on missing or malformed metadata, it simply leaves the fields empty.
+func ReadMetadata(head []byte) *Metadata {
+	meta := &Metadata{}
+
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+		meta.Width = cfg.Width
+		meta.Height = cfg.Height
+	}
+
+	for _, segment := range jpegAppSegments(head) {
+		switch {
+		case bytes.HasPrefix(segment, exifHeader):
+			readExif(meta, segment[len(exifHeader):])
+		case bytes.HasPrefix(segment, xmpHeader):
+			readXMP(meta, segment[len(xmpHeader):])
+		}
+	}
+	return meta
+}
+
+// jpegAppSegments walks the JPEG markers up to the start of scan and returns the APP1 payloads.
+func jpegAppSegments(data []byte) [][]byte {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	var segments [][]byte
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return segments
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // Fill byte
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // Start of scan or end of image, no more metadata
+			return segments
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return segments
+		}
+		if marker == 0xE1 {
+			segments = append(segments, data[pos+4:end])
+		}
+		pos = end
+	}
+	return segments
+}
+
+func readExif(meta *Metadata, tiff []byte) {
+	x, err := exif.Decode(bytes.NewReader(tiff))
+	if err != nil && x == nil {
+		return
+	}
+
+	if tag, err := x.Get(exif.DateTimeOriginal); err == nil {
+		if value, err := tag.StringVal(); err == nil {
+			// EXIF has no time zone, treat it as UTC so the timeline does not shift with the server
+			if t, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(value)); err == nil {
+				meta.TakenAt = &t
+			}
+		}
+	}
+
+	if lat, long, err := x.LatLong(); err == nil {
+		meta.Latitude = &lat
+		meta.Longitude = &long
+	}
+
+	if tag, err := x.Get(exif.Make); err == nil {
+		if value, err := tag.StringVal(); err == nil {
+			meta.CameraMake = strings.TrimSpace(value)
+		}
+	}
+	if tag, err := x.Get(exif.Model); err == nil {
+		if value, err := tag.StringVal(); err == nil {
+			meta.CameraModel = strings.TrimSpace(value)
+		}
+	}
+}
+
+func readXMP(meta *Metadata, packet []byte) {
+	if value := xmpValue(xmpProjectionType, packet); value != "" {
+		meta.ProjectionType = strings.ToLower(value)
+	}
+	if value := xmpValue(xmpPoseHeading, packet); value != "" {
+		if heading, err := strconv.ParseFloat(value, 64); err == nil {
+			meta.PoseHeading = &heading
+		}
+	}
+}
+
+func xmpValue(re *regexp.Regexp, packet []byte) string {
+	match := re.FindSubmatch(packet)
+	if match == nil {
+		return ""
+	}
+	for _, group := range match[1:] {
+		if len(group) > 0 {
+			return strings.TrimSpace(string(group))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withAPP1 splices an APP1 segment right after the SOI marker of a JPEG.
+func withAPP1(t *testing.T, jpg []byte, payload []byte) []byte {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(jpg, []byte{0xFF, 0xD8}))
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{0xFF, 0xD8}, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func encodeTestJPEG(t *testing.T, width int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, quadrantPanorama(width), DefaultJPEGQuality))
+	return buf.Bytes()
+}
+
+func TestReadMetadata(t *testing.T) {
+	t.Run("Reads GPano XMP attributes", func(t *testing.T) {
+		xmp := append([]byte(nil), xmpHeader...)
+		xmp = append(xmp, `<x:xmpmeta><rdf:Description GPano:ProjectionType="equirectangular" GPano:PoseHeadingDegrees="87.5"/></x:xmpmeta>`...)
+
+		meta := ReadMetadata(withAPP1(t, encodeTestJPEG(t, 64), xmp))
+		assert.Equal(t, 64, meta.Width)
+		assert.Equal(t, 32, meta.Height)
+		assert.Equal(t, ProjectionEquirectangular, meta.ProjectionType)
+		require.NotNil(t, meta.PoseHeading)
+		assert.Equal(t, 87.5, *meta.PoseHeading)
+		assert.True(t, meta.IsEquirectangular())
+	})
+
+	t.Run("XMP projection wins over aspect ratio", func(t *testing.T) {
+		xmp := append([]byte(nil), xmpHeader...)
+		xmp = append(xmp, `<GPano:ProjectionType>cylindrical</GPano:ProjectionType>`...)
+
+		meta := ReadMetadata(withAPP1(t, encodeTestJPEG(t, 64), xmp))
+		assert.Equal(t, "cylindrical", meta.ProjectionType)
+		assert.False(t, meta.IsEquirectangular())
+	})
+
+	t.Run("Falls back to the 2:1 aspect ratio without metadata", func(t *testing.T) {
+		meta := ReadMetadata(encodeTestJPEG(t, 64))
+		assert.Nil(t, meta.TakenAt)
+		assert.Empty(t, meta.ProjectionType)
+		assert.True(t, meta.IsEquirectangular())
+	})
+
+	t.Run("Garbage yields empty metadata", func(t *testing.T) {
+		meta := ReadMetadata([]byte("not an image"))
+		assert.Zero(t, meta.Width)
+		assert.False(t, meta.IsEquirectangular())
+	})
+}
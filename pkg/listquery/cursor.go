@@ -0,0 +1,103 @@
+package listquery
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Keyset is the order of a listing paged with cursors, newest first: a time column with the
+// primary key to break ties, both covered by an index.
+type Keyset struct {
+	Time string
+	ID   string
+}
+
+// Cursor is where a keyset page ends, the time and id of its last item.
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+// Encode makes the cursor opaque, clients only ever send it back.
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// DecodeCursor reads a cursor made by Encode. Keysets break ties on UUID primary keys, an id
+// that is not one was tampered with.
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	at, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return &Cursor{Time: t.UTC(), ID: id}, nil
+}
+
+// PageInfo is what a listing tells about itself next to its items. Total is nil when it was
+// not counted, Next is nil on the last page of a keyset listing.
+type PageInfo struct {
+	Total *int64
+	Next  *Cursor
+}
+
+// WithCursor switches the query to keyset paging, starting after cursor unless it is empty.
+// Keyset pages come in a fixed order and are only counted when count is set, counting is
+// what gets slow on large tables.
+func (q Query) WithCursor(cursor string, count bool) (Query, error) {
+	if q.Sort != "" || q.Offset != 0 {
+		return q, fmt.Errorf("%w: a cursor cannot be combined with sort or a range offset", ErrInvalidQuery)
+	}
+	q.Keyset = true
+	q.Count = count
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return q, err
+		}
+		q.After = after
+	}
+	return q, nil
+}
+
+// Counted reports whether the listing must count its items: always with offsets, on demand
+// with cursors.
+func (q Query) Counted() bool {
+	return !q.Keyset || q.Count
+}
+
+// Seek returns the condition, starting with " AND ", of a keyset page after the query's
+// cursor and args with its values appended. It is empty for offset pages and first pages.
+func (q Query) Seek(fields Fields, args []interface{}) (string, []interface{}, error) {
+	if !q.Keyset {
+		return "", args, nil
+	}
+	if fields.Keyset == nil {
+		return "", nil, fmt.Errorf("%w: this listing does not page with cursors", ErrInvalidQuery)
+	}
+	if q.After == nil {
+		return "", args, nil
+	}
+	args = append(args, q.After.Time, q.After.ID)
+	return fmt.Sprintf(" AND (%s, %s) < ($%d, $%d)", fields.Keyset.Time, fields.Keyset.ID, len(args)-1, len(args)), args, nil
+}
+
+// HasMore reports whether a keyset page fetched more rows than its limit, meaning there is a
+// page after it. The extra row is not part of the page.
+func (q Query) HasMore(fetched int) bool {
+	return q.Keyset && fetched > q.limit()
+}
@@ -0,0 +1,83 @@
+package listquery
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mediaFields = Fields{
+	Columns:     map[string]string{"type": "type"},
+	DefaultSort: "taken_at DESC",
+	Tiebreaker:  "id",
+	Keyset:      &Keyset{Time: "created_at", ID: "id"},
+}
+
+const mediaID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: mediaID}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	for _, encoded := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fG1lZGlhLTE"} {
+		_, err := DecodeCursor(encoded)
+		assert.True(t, errors.Is(err, ErrInvalidQuery), "%q is rejected", encoded)
+	}
+
+	// Re-encoded by the client with something else than a primary key
+	for _, id := range []string{"", "media-1", "' OR 1=1 --"} {
+		tampered := Cursor{Time: cursor.Time, ID: id}.Encode()
+		_, err := DecodeCursor(tampered)
+		assert.True(t, errors.Is(err, ErrInvalidQuery), "%q is rejected", id)
+	}
+}
+
+func TestWithCursor(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: mediaID}
+
+	q, err := New(5).WithCursor("", false)
+	require.NoError(t, err)
+	assert.Equal(t, Query{Limit: 5, Keyset: true}, q)
+	assert.False(t, q.Counted(), "keyset pages are not counted unless asked")
+
+	q, err = New(5).WithCursor(cursor.Encode(), true)
+	require.NoError(t, err)
+	assert.Equal(t, &cursor, q.After)
+	assert.True(t, q.Counted())
+
+	_, err = Query{Limit: 5, Sort: "type"}.WithCursor("", false)
+	assert.True(t, errors.Is(err, ErrInvalidQuery), "cursors keep their own order")
+
+	_, err = Query{Offset: 10, Limit: 5}.WithCursor("", false)
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func TestKeysetPage(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "media-1"}
+	q := Query{Limit: 5, Keyset: true, After: &cursor}
+
+	seek, args, err := q.Seek(mediaFields, []interface{}{"room-1"})
+	require.NoError(t, err)
+	assert.Equal(t, " AND (created_at, id) < ($2, $3)", seek)
+	assert.Equal(t, []interface{}{"room-1", cursor.Time, "media-1"}, args)
+
+	orderBy, err := q.OrderBy(mediaFields)
+	require.NoError(t, err)
+	assert.Equal(t, " ORDER BY created_at DESC, id DESC", orderBy)
+
+	page, args := q.Page(args)
+	assert.Equal(t, " LIMIT $4", page)
+	assert.Equal(t, 6, args[3], "one row more tells whether a next page exists")
+
+	assert.True(t, q.HasMore(6))
+	assert.False(t, q.HasMore(5))
+
+	_, _, err = q.Seek(siteFields, nil)
+	assert.True(t, errors.Is(err, ErrInvalidQuery), "listings without a keyset refuse cursors")
+}
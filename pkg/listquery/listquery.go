@@ -0,0 +1,242 @@
+// Package listquery reads the range, sort and filter parameters of React Admin's simple REST
+// data provider and turns them into SQL clauses. Only fields a listing whitelists can be
+// sorted or filtered on, values always go through placeholders.
+package listquery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+
+	// SearchField is the filter React Admin sends from its search input.
+	SearchField = "q"
+)
+
+// ErrInvalidQuery is returned for malformed parameters and for fields a listing does not allow.
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// Query is one page of a listing, sorted and filtered. Pages are found by offset, or after
+// a cursor once the query is switched to keyset paging with WithCursor.
+type Query struct {
+	Offset int
+	Limit  int
+	Sort   string // Field name as the API shows it, empty for the listing's default order
+	Desc   bool
+	Filter map[string]interface{}
+
+	Keyset bool
+	After  *Cursor // Nil for the first keyset page
+	Count  bool    // Whether a keyset listing is counted too
+}
+
+// New returns the first page of limit items in the default order, without filter.
+func New(limit int) Query {
+	return Query{Limit: limit}
+}
+
+// With returns a copy of the query also filtered on field, replacing any filter the caller
+// sent on it. Used to pin a listing to what the caller may see.
+func (q Query) With(field string, value interface{}) Query {
+	filter := make(map[string]interface{}, len(q.Filter)+1)
+	for name, v := range q.Filter {
+		filter[name] = v
+	}
+	filter[field] = value
+	q.Filter = filter
+	return q
+}
+
+// Parse reads the JSON encoded parameters, each of which may be empty:
+// range=[0,24], sort=["name","ASC"] and filter={"q":"tower","company_id":"..."}.
+func Parse(rangeParam, sortParam, filterParam string) (Query, error) {
+	q := New(DefaultLimit)
+
+	if rangeParam != "" {
+		var bounds []int
+		if err := json.Unmarshal([]byte(rangeParam), &bounds); err != nil || len(bounds) != 2 {
+			return q, fmt.Errorf("%w: range must be [start, end]", ErrInvalidQuery)
+		}
+		if bounds[0] < 0 || bounds[1] < bounds[0] {
+			return q, fmt.Errorf("%w: range end must not be before its start", ErrInvalidQuery)
+		}
+		q.Offset = bounds[0]
+		q.Limit = bounds[1] - bounds[0] + 1
+		if q.Limit > MaxLimit {
+			q.Limit = MaxLimit
+		}
+	}
+
+	if sortParam != "" {
+		var order []string
+		if err := json.Unmarshal([]byte(sortParam), &order); err != nil || len(order) != 2 {
+			return q, fmt.Errorf("%w: sort must be [field, order]", ErrInvalidQuery)
+		}
+		switch strings.ToUpper(order[1]) {
+		case "ASC":
+		case "DESC":
+			q.Desc = true
+		default:
+			return q, fmt.Errorf("%w: sort order must be ASC or DESC", ErrInvalidQuery)
+		}
+		q.Sort = order[0]
+	}
+
+	if filterParam != "" {
+		if err := json.Unmarshal([]byte(filterParam), &q.Filter); err != nil {
+			return q, fmt.Errorf("%w: filter must be a JSON object", ErrInvalidQuery)
+		}
+	}
+	return q, nil
+}
+
+// ContentRange is the Content-Range header React Admin reads the total from, such as
+// "sites 0-9/42". An empty page is "sites */42".
+func (q Query) ContentRange(resource string, count int, total int64) string {
+	if count == 0 {
+		return fmt.Sprintf("%s */%d", resource, total)
+	}
+	return fmt.Sprintf("%s %d-%d/%d", resource, q.Offset, q.Offset+count-1, total)
+}
+
+// Fields whitelists what a listing can be sorted and filtered on.
+type Fields struct {
+	// Columns maps the field names of the API to the SQL expressions they stand for.
+	Columns map[string]string
+	// Search are the columns the free text filter looks into, case insensitively.
+	Search []string
+	// DefaultSort orders the listing when the query does not, such as "created_at DESC".
+	DefaultSort string
+	// Tiebreaker is appended to every order so pages do not overlap, usually the primary key.
+	Tiebreaker string
+	// Keyset is the order of cursor pages, nil when the listing only pages by offset.
+	Keyset *Keyset
+}
+
+// Where returns the filter conditions, each starting with " AND ", and args with their
+// values appended. A list value matches any of its items, null matches NULL.
+func (q Query) Where(fields Fields, args []interface{}) (string, []interface{}, error) {
+	// Sorted so the same filter always gives the same SQL and placeholders
+	names := make([]string, 0, len(q.Filter))
+	for name := range q.Filter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var where strings.Builder
+	for _, name := range names {
+		value := q.Filter[name]
+
+		if name == SearchField && len(fields.Search) > 0 {
+			text, ok := value.(string)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %s must be a string", ErrInvalidQuery, name)
+			}
+			if text == "" {
+				continue
+			}
+			args = append(args, "%"+escapeLike(text)+"%")
+			matches := make([]string, len(fields.Search))
+			for i, column := range fields.Search {
+				matches[i] = fmt.Sprintf("%s ILIKE $%d", column, len(args))
+			}
+			where.WriteString(" AND (" + strings.Join(matches, " OR ") + ")")
+			continue
+		}
+
+		column, ok := fields.Columns[name]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: cannot filter on %s", ErrInvalidQuery, name)
+		}
+		switch v := value.(type) {
+		case nil:
+			fmt.Fprintf(&where, " AND %s IS NULL", column)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				scalar, err := scalarText(name, item)
+				if err != nil {
+					return "", nil, err
+				}
+				items[i] = scalar
+			}
+			args = append(args, items)
+			fmt.Fprintf(&where, " AND %s::text = ANY($%d)", column, len(args))
+		case string:
+			// Compared as text so a malformed id matches nothing instead of failing the query
+			args = append(args, v)
+			fmt.Fprintf(&where, " AND %s::text = $%d", column, len(args))
+		case float64, bool:
+			args = append(args, v)
+			fmt.Fprintf(&where, " AND %s = $%d", column, len(args))
+		default:
+			return "", nil, fmt.Errorf("%w: unsupported value for %s", ErrInvalidQuery, name)
+		}
+	}
+	return where.String(), args, nil
+}
+
+// OrderBy returns the ORDER BY clause, with a leading space.
+func (q Query) OrderBy(fields Fields) (string, error) {
+	if q.Keyset {
+		if fields.Keyset == nil {
+			return "", fmt.Errorf("%w: this listing does not page with cursors", ErrInvalidQuery)
+		}
+		return fmt.Sprintf(" ORDER BY %s DESC, %s DESC", fields.Keyset.Time, fields.Keyset.ID), nil
+	}
+
+	order := fields.DefaultSort
+	if q.Sort != "" {
+		column, ok := fields.Columns[q.Sort]
+		if !ok {
+			return "", fmt.Errorf("%w: cannot sort by %s", ErrInvalidQuery, q.Sort)
+		}
+		order = column + " ASC"
+		if q.Desc {
+			order = column + " DESC"
+		}
+	}
+	if fields.Tiebreaker != "" {
+		order += ", " + fields.Tiebreaker
+	}
+	return " ORDER BY " + order, nil
+}
+
+// Page returns the LIMIT and OFFSET clause, with a leading space, and args with its values
+// appended. Keyset pages fetch one row more than their limit, see HasMore.
+func (q Query) Page(args []interface{}) (string, []interface{}) {
+	if q.Keyset {
+		args = append(args, q.limit()+1)
+		return fmt.Sprintf(" LIMIT $%d", len(args)), args
+	}
+	args = append(args, q.limit(), q.Offset)
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	return q.Limit
+}
+
+func scalarText(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("%w: unsupported value in %s", ErrInvalidQuery, name)
+}
+
+// escapeLike keeps the wildcards of LIKE literal in searched text.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
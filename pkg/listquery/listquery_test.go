@@ -0,0 +1,89 @@
+package listquery
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var siteFields = Fields{
+	Columns: map[string]string{
+		"id":         "s.id",
+		"name":       "s.name",
+		"company_id": "s.company_id",
+		"floors":     "s.floors",
+	},
+	Search:      []string{"s.name", "s.address"},
+	DefaultSort: "s.created_at DESC",
+	Tiebreaker:  "s.id",
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`[10,34]`, `["name","desc"]`, `{"q":"tower"}`)
+	require.NoError(t, err)
+	assert.Equal(t, Query{Offset: 10, Limit: 25, Sort: "name", Desc: true, Filter: map[string]interface{}{"q": "tower"}}, q)
+
+	q, err = Parse("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, New(DefaultLimit), q)
+
+	q, err = Parse(`[0,999]`, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, MaxLimit, q.Limit, "pages are capped")
+
+	for _, params := range [][3]string{
+		{`[0]`, "", ""},
+		{`[5,4]`, "", ""},
+		{`[-1,4]`, "", ""},
+		{"", `["name"]`, ""},
+		{"", `["name","SIDEWAYS"]`, ""},
+		{"", "", `["not","an","object"]`},
+	} {
+		_, err := Parse(params[0], params[1], params[2])
+		assert.True(t, errors.Is(err, ErrInvalidQuery), "%v is rejected", params)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	q := New(DefaultLimit).With("q", "50%_off").With("company_id", []interface{}{"c-1", "c-2"}).With("floors", float64(3))
+	where, args, err := q.Where(siteFields, []interface{}{"scope"})
+	require.NoError(t, err)
+	assert.Equal(t, " AND s.company_id::text = ANY($2) AND s.floors = $3 AND (s.name ILIKE $4 OR s.address ILIKE $4)", where)
+	assert.Equal(t, []interface{}{"scope", []string{"c-1", "c-2"}, float64(3), `%50\%\_off%`}, args)
+
+	where, args, err = New(DefaultLimit).With("name", "Tower").With("company_id", nil).Where(siteFields, nil)
+	require.NoError(t, err)
+	assert.Equal(t, " AND s.company_id IS NULL AND s.name::text = $1", where)
+	assert.Equal(t, []interface{}{"Tower"}, args)
+
+	_, _, err = New(DefaultLimit).With("password", "x").Where(siteFields, nil)
+	assert.True(t, errors.Is(err, ErrInvalidQuery), "fields outside the whitelist are refused")
+
+	_, _, err = New(DefaultLimit).With("name", map[string]interface{}{"$ne": ""}).Where(siteFields, nil)
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func TestOrderByAndPage(t *testing.T) {
+	orderBy, err := New(DefaultLimit).OrderBy(siteFields)
+	require.NoError(t, err)
+	assert.Equal(t, " ORDER BY s.created_at DESC, s.id", orderBy)
+
+	orderBy, err = Query{Sort: "name"}.OrderBy(siteFields)
+	require.NoError(t, err)
+	assert.Equal(t, " ORDER BY s.name ASC, s.id", orderBy)
+
+	_, err = Query{Sort: "name; DROP TABLE users"}.OrderBy(siteFields)
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+
+	page, args := Query{Offset: 20, Limit: 10}.Page([]interface{}{"scope"})
+	assert.Equal(t, " LIMIT $2 OFFSET $3", page)
+	assert.Equal(t, []interface{}{"scope", 10, 20}, args)
+}
+
+func TestContentRange(t *testing.T) {
+	q := Query{Offset: 10, Limit: 10}
+	assert.Equal(t, "sites 10-19/42", q.ContentRange("sites", 10, 42))
+	assert.Equal(t, "sites */42", Query{Offset: 50, Limit: 10}.ContentRange("sites", 0, 42))
+}
@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hfleury/bk_globalshot/pkg/config"
+)
+
+const (
+	DriverOutbox = "outbox"
+	DriverSMTP   = "smtp"
+)
+
+var ErrNoRecipient = errors.New("mailer: message has no recipient")
+
+// Message is a plain text email.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email. Drivers must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New builds the driver selected by cfg.Driver.
+func New(cfg config.ConfigMailer) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverOutbox, "":
+		return NewOutboxMailer(cfg.From, cfg.OutboxDir)
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.From, cfg.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", cfg.Driver)
+	}
+}
+
+// render serializes msg as an RFC 5322 message, the same bytes whichever driver sends it.
+func render(from string, msg Message, now time.Time) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, ErrNoRecipient
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid sender %q: %w", from, err)
+	}
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("mailer: invalid recipient %q: %w", to, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		// Strip line breaks so user supplied values cannot inject headers
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", sender.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.New().String(), domainOf(sender.Address)))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
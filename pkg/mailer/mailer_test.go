@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSender = "GlobalShot <no-reply@globalshot.test>"
+
+func TestOutboxMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(config.ConfigMailer{Driver: DriverOutbox, From: testSender, OutboxDir: dir})
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{
+		To:      []string{"jane@example.com"},
+		Subject: "Reset your password",
+		Body:    "Follow https://app.example.com/reset-password?token=abc to continue.",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	raw, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "To: jane@example.com\r\n")
+	assert.Contains(t, string(raw), "Subject: Reset your password\r\n")
+	assert.Contains(t, string(raw), "token=3Dabc") // Quoted-printable "="
+}
+
+func TestRender(t *testing.T) {
+	t.Run("Line breaks cannot inject headers", func(t *testing.T) {
+		raw, err := render(testSender, Message{To: []string{"jane@example.com"}, Subject: "Hi\r\nBcc: evil@example.com"}, time.Time{})
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "\r\nBcc:")
+	})
+
+	t.Run("Recipient is required", func(t *testing.T) {
+		_, err := render(testSender, Message{Subject: "Hi"}, time.Time{})
+		assert.ErrorIs(t, err, ErrNoRecipient)
+	})
+
+	t.Run("Invalid recipient is rejected", func(t *testing.T) {
+		_, err := render(testSender, Message{To: []string{"not an address"}, Subject: "Hi"}, time.Time{})
+		assert.Error(t, err)
+	})
+}
+
+func TestSMTPMailer(t *testing.T) {
+	received := make(chan string, 1)
+	addr := fakeSMTPServer(t, received)
+
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	m, err := New(config.ConfigMailer{Driver: DriverSMTP, From: testSender, SMTP: config.ConfigSMTP{Host: host, Port: portNum}})
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{To: []string{"Jane <jane@example.com>"}, Subject: "Hello", Body: "Hi Jane"})
+	require.NoError(t, err)
+
+	data := <-received
+	assert.Contains(t, data, "RCPT TO:<jane@example.com>")
+	assert.Contains(t, data, "Subject: Hello")
+	assert.Contains(t, data, "Hi Jane")
+}
+
+// fakeSMTPServer accepts a single plain text SMTP session and reports everything the client sent.
+func fakeSMTPServer(t *testing.T, received chan<- string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var transcript strings.Builder
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 fake ESMTP")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			transcript.WriteString(line)
+			switch {
+			case inData && line == ".\r\n":
+				inData = false
+				reply("250 OK")
+			case inData:
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				reply("354 Go ahead")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 Bye")
+				received <- transcript.String()
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+		received <- transcript.String()
+	}()
+
+	return ln.Addr().String()
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMailer never delivers anything, it drops each message as an .eml file in a folder
+// (or only logs it when no folder is set) so flows that send email work offline.
+type OutboxMailer struct {
+	from string
+	dir  string
+}
+
+func NewOutboxMailer(from, dir string) (*OutboxMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("mailer: failed to create outbox %s: %w", dir, err)
+		}
+	}
+	return &OutboxMailer{from: from, dir: dir}, nil
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	raw, err := render(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		log.Printf("mailer outbox: to=%v subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405"), uuid.New().String())
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("mailer: failed to write %s: %w", path, err)
+	}
+	log.Printf("mailer outbox: %q to %v written to %s", msg.Subject, msg.To, path)
+	return nil
+}
@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+)
+
+// SMTPMailer delivers through a relay, upgrading to TLS with STARTTLS whenever the server offers it.
+type SMTPMailer struct {
+	from string
+	addr string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(from string, cfg config.ConfigSMTP) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mailer: SMTP host is required")
+	}
+	m := &SMTPMailer{
+		from: from,
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := render(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	recipients := make([]string, len(msg.To))
+	for i, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return err
+		}
+		recipients[i] = addr.Address
+	}
+
+	// smtp.SendMail has no context support, run it aside so a hung relay cannot hold the request
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, sender.Address, recipients, raw)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("mailer: failed to send via %s: %w", m.addr, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+// Package mergepatch reads JSON merge patches (RFC 7396): a member left out of the patch
+// keeps its value, null removes it and anything else replaces it. Patch documents are structs
+// of Value and Nullable fields, which remember whether they were sent at all.
+package mergepatch
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ContentType is the media type of merge patches, plain application/json is taken too.
+const ContentType = "application/merge-patch+json"
+
+// Value is a member that can be changed but not removed. null decodes, so the error can name
+// the member, and is rejected by validating the field with "omitnil".
+type Value[T any] struct {
+	Set   bool // Sent in the patch, null or not
+	Null  bool
+	Value T
+}
+
+func (f *Value[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Apply replaces *target when the member was sent with a value.
+func (f Value[T]) Apply(target *T) {
+	if f.Set && !f.Null {
+		*target = f.Value
+	}
+}
+
+func (f Value[T]) validationValue() interface{} {
+	switch {
+	case !f.Set:
+		return (*T)(nil)
+	case f.Null:
+		return nil
+	}
+	return f.Value
+}
+
+// Nullable is a member that can be removed by sending null.
+type Nullable[T any] struct {
+	Set   bool // Sent in the patch, null or not
+	Null  bool
+	Value T
+}
+
+func (f *Nullable[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Apply replaces *target when the member was sent, null leaving the zero value.
+func (f Nullable[T]) Apply(target *T) {
+	if f.Set {
+		*target = f.Value
+	}
+}
+
+// ApplyPtr replaces *target when the member was sent, null leaving nil.
+func (f Nullable[T]) ApplyPtr(target **T) {
+	if !f.Set {
+		return
+	}
+	if f.Null {
+		*target = nil
+		return
+	}
+	value := f.Value
+	*target = &value
+}
+
+func (f Nullable[T]) validationValue() interface{} {
+	if !f.Set || f.Null {
+		return (*T)(nil)
+	}
+	return f.Value
+}
+
+// ValidationValue is a custom type func for go-playground/validator, to register for every
+// field type a patch uses. Members the patch left out or removed come out as a nil pointer,
+// which "omitnil" skips, so only values that were sent are validated. A null sent for a Value
+// comes out as nil, which "omitnil" fails.
+func ValidationValue(field reflect.Value) interface{} {
+	if f, ok := field.Interface().(interface{ validationValue() interface{} }); ok {
+		return f.validationValue()
+	}
+	return nil
+}
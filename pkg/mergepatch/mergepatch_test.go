@@ -0,0 +1,89 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPatch struct {
+	Name     Value[string]    `json:"name" validate:"omitnil,min=1"`
+	Email    Value[string]    `json:"email" validate:"omitnil,email"`
+	ClientID Nullable[string] `json:"client_id" validate:"omitnil,min=1"`
+}
+
+func TestDecode(t *testing.T) {
+	var patch testPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"Flat 2B","client_id":null}`), &patch))
+
+	assert.Equal(t, Value[string]{Set: true, Value: "Flat 2B"}, patch.Name)
+	assert.False(t, patch.Email.Set)
+	assert.Equal(t, Nullable[string]{Set: true, Null: true}, patch.ClientID)
+}
+
+func TestDecodeNullValue(t *testing.T) {
+	var patch testPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"name":null}`), &patch))
+
+	name := "Flat 2A"
+	patch.Name.Apply(&name)
+	assert.Equal(t, "Flat 2A", name, "null is never applied to a value")
+}
+
+func TestApply(t *testing.T) {
+	name, companyID := "Flat 2A", "company-123"
+	client := "customer-1"
+	clientID := &client
+
+	Value[string]{}.Apply(&name)
+	assert.Equal(t, "Flat 2A", name, "absent keeps the value")
+	Value[string]{Set: true, Value: "Flat 2B"}.Apply(&name)
+	assert.Equal(t, "Flat 2B", name)
+
+	Nullable[string]{Set: true, Null: true}.Apply(&companyID)
+	assert.Equal(t, "", companyID, "null leaves the zero value")
+
+	Nullable[string]{}.ApplyPtr(&clientID)
+	assert.Equal(t, "customer-1", *clientID, "absent keeps the value")
+	Nullable[string]{Set: true, Value: "customer-2"}.ApplyPtr(&clientID)
+	assert.Equal(t, "customer-2", *clientID)
+	Nullable[string]{Set: true, Null: true}.ApplyPtr(&clientID)
+	assert.Nil(t, clientID, "null removes the value")
+}
+
+func TestValidationValue(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterCustomTypeFunc(ValidationValue, Value[string]{}, Nullable[string]{})
+
+	tests := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"Empty patch", `{}`, true},
+		{"Valid members", `{"name":"Flat 2B","email":"jane@example.com"}`, true},
+		{"Removed nullable member", `{"client_id":null}`, true},
+		{"Removed value member", `{"name":null}`, false},
+		{"Empty name", `{"name":""}`, false},
+		{"Malformed email", `{"email":"jane"}`, false},
+		{"Empty client", `{"client_id":""}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch testPatch
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &patch))
+
+			err := validate.Struct(patch)
+
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package oidc is the relying party side of OpenID Connect: discovery, the authorization code
+// flow with PKCE, and verification of the ID token the identity provider returns.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDiscovery         = errors.New("oidc discovery failed")
+	ErrExchange          = errors.New("oidc code exchange failed")
+	ErrInvalidIDToken    = errors.New("invalid oidc id token")
+	ErrUnknownSigningKey = errors.New("id token signed with an unknown key")
+)
+
+// DefaultScopes are requested on every login, email identifies the user to provision.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// maxResponseBody caps what is read from the identity provider.
+const maxResponseBody = 1 << 20
+
+// Provider is what discovery tells about an identity provider.
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover reads the provider metadata published under the issuer. The issuer it reports
+// must be the one asked for, or tokens could be minted by someone else. The issuer and its
+// endpoints must all be served over https.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*Provider, error) {
+	if !isHTTPS(issuer) {
+		return nil, fmt.Errorf("%w: issuer must use https", ErrDiscovery)
+	}
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	provider := &Provider{}
+	if err := getJSON(ctx, client, wellKnown, provider); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if provider.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, provider.Issuer, issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+	if !isHTTPS(provider.AuthorizationEndpoint) || !isHTTPS(provider.TokenEndpoint) || !isHTTPS(provider.JWKSURI) {
+		return nil, fmt.Errorf("%w: provider endpoints must use https", ErrDiscovery)
+	}
+	return provider, nil
+}
+
+// Client is this API registered as a relying party of one provider.
+type Client struct {
+	Provider     *Provider
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	HTTPClient   *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*jsonWebKey
+}
+
+// Claims are the parts of the ID token used to recognise the user.
+type Claims struct {
+	Issuer        string `json:"iss"`
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"` // Nil when the provider does not say
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+}
+
+// AuthCodeURL is where the browser is sent to sign in. The state is echoed back to the
+// redirect URL, the nonce ends up in the ID token, the challenge binds the code to the verifier.
+func (c *Client) AuthCodeURL(state, nonce, codeChallenge string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", c.RedirectURL)
+	q.Set("scope", strings.Join(DefaultScopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(c.Provider.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return c.Provider.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Exchange trades the authorization code for tokens and returns the verified ID token claims.
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token endpoint answered %d: %s", ErrExchange, resp.StatusCode, body)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil || tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id token in response", ErrExchange)
+	}
+	return c.VerifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce of an ID token.
+func (c *Client) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*Claims, error) {
+	payload, err := c.verifySignature(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var registered struct {
+		Audience  audience `json:"aud"`
+		AuthParty string   `json:"azp"`
+		Expiry    int64    `json:"exp"`
+		IssuedAt  int64    `json:"iat"`
+	}
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != c.Provider.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case !registered.Audience.contains(c.ClientID):
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	case len(registered.Audience) > 1 && registered.AuthParty != c.ClientID:
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	case registered.Expiry == 0 || !now.Before(time.Unix(registered.Expiry, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	case registered.IssuedAt > now.Add(clockSkew).Unix():
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// RandomString returns a URL safe random value, for states, nonces and PKCE verifiers.
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge is the S256 PKCE challenge of a verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// audience accepts "aud" both as a single string and as a list, as the spec allows.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPS(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && u.Host != "" && u.User == nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(v)
+}
@@ -0,0 +1,150 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, idp *oidctest.IdP) *Client {
+	provider, err := Discover(context.Background(), idp.HTTPClient(), idp.Issuer)
+	require.NoError(t, err)
+	return &Client{
+		Provider:     provider,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost:5173/sso/callback",
+		HTTPClient:   idp.HTTPClient(),
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+	idp.SignIn(oidctest.Identity{Subject: "idp-user-1", Email: "jane@builder.example", EmailVerified: true, Name: "Jane"})
+
+	client := newTestClient(t, idp)
+	verifier, err := RandomString()
+	require.NoError(t, err)
+
+	authURL := client.AuthCodeURL("state-1", "nonce-1", CodeChallenge(verifier))
+	code, state, err := idp.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := client.Exchange(context.Background(), code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, idp.Issuer, claims.Issuer)
+	assert.Equal(t, "idp-user-1", claims.Subject)
+	assert.Equal(t, "jane@builder.example", claims.Email)
+	require.NotNil(t, claims.EmailVerified)
+	assert.True(t, *claims.EmailVerified)
+
+	// Codes are single use
+	_, err = client.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.ErrorIs(t, err, ErrExchange)
+}
+
+func TestAuthorizationCodeFlowRejections(t *testing.T) {
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+	idp.SignIn(oidctest.Identity{Subject: "idp-user-1", Email: "jane@builder.example"})
+
+	tests := []struct {
+		name        string
+		exchange    func(client *Client, code, verifier string) error
+		expectedErr error
+	}{
+		{
+			name: "Wrong PKCE verifier",
+			exchange: func(client *Client, code, verifier string) error {
+				_, err := client.Exchange(context.Background(), code, "someone-elses-verifier", "nonce-1")
+				return err
+			},
+			expectedErr: ErrExchange,
+		},
+		{
+			name: "Replayed nonce",
+			exchange: func(client *Client, code, verifier string) error {
+				_, err := client.Exchange(context.Background(), code, verifier, "other-nonce")
+				return err
+			},
+			expectedErr: ErrInvalidIDToken,
+		},
+		{
+			name: "Wrong client secret",
+			exchange: func(client *Client, code, verifier string) error {
+				client.ClientSecret = "guessed"
+				_, err := client.Exchange(context.Background(), code, verifier, "nonce-1")
+				return err
+			},
+			expectedErr: ErrExchange,
+		},
+		{
+			name: "Unsigned token",
+			exchange: func(client *Client, code, verifier string) error {
+				_, err := client.VerifyIDToken(context.Background(), forgeUnsigned(idp.Issuer, client.ClientID), "nonce-1")
+				return err
+			},
+			expectedErr: ErrInvalidIDToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, idp)
+			verifier, err := RandomString()
+			require.NoError(t, err)
+
+			code, _, err := idp.Authorize(client.AuthCodeURL("state-1", "nonce-1", CodeChallenge(verifier)))
+			require.NoError(t, err)
+
+			assert.ErrorIs(t, tt.exchange(client, code, verifier), tt.expectedErr)
+		})
+	}
+}
+
+func TestDiscoverRejectsMismatchedIssuer(t *testing.T) {
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+
+	_, err := Discover(context.Background(), idp.HTTPClient(), idp.Issuer+"/")
+	assert.ErrorIs(t, err, ErrDiscovery)
+}
+
+func TestDiscoverRejectsPlainHTTP(t *testing.T) {
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+
+	_, err := Discover(context.Background(), idp.HTTPClient(), strings.Replace(idp.Issuer, "https://", "http://", 1))
+	assert.ErrorIs(t, err, ErrDiscovery)
+}
+
+func TestHTTPClientRefusesInternalAddresses(t *testing.T) {
+	idp := oidctest.NewIdP("globalshot", "client-secret")
+	defer idp.Close()
+
+	// The test provider listens on loopback, like a service next to the API would
+	_, err := Discover(context.Background(), NewHTTPClient(time.Second), idp.Issuer)
+	assert.ErrorIs(t, err, ErrDiscovery)
+	assert.ErrorContains(t, err, ErrForbiddenAddress.Error())
+
+	for _, ip := range []string{"10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "::1", "fe80::1", "fd00::1", "0.0.0.0"} {
+		assert.False(t, isPublic(net.ParseIP(ip)), ip)
+	}
+	assert.True(t, isPublic(net.ParseIP("93.184.216.34")))
+}
+
+// forgeUnsigned builds a token with "alg":"none", which must never be accepted.
+func forgeUnsigned(issuer, clientID string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"` + issuer + `","sub":"x","aud":"` + clientID + `","exp":9999999999,"nonce":"nonce-1"}`))
+	return strings.Join([]string{header, payload, ""}, ".")
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a provider resolves to an address of the network the
+// API runs in.
+var ErrForbiddenAddress = errors.New("identity provider address is not public")
+
+// NewHTTPClient returns the client to reach identity providers with. Issuers are entered by
+// company users, so it only connects to public addresses: the check runs on the address
+// actually dialed, after DNS resolution and on every redirect.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublic(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy from the environment, the dialer would only see the proxy's address
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// isPublic reports whether the address is routable on the internet.
+func isPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || isSharedAddress(ip))
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598, which IsPrivate leaves out.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isSharedAddress(ip net.IP) bool {
+	return sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that refilled completely are dropped, they hold
+// nothing a fresh bucket would not.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	rule    Rule
+}
+
+// MemoryLimiter keeps buckets in the process, each replica counts on its own.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rule Rule) (Decision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.rule = rule
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return Decision{RetryAfter: retryAfter(b.tokens, rule)}, nil
+	}
+	b.tokens--
+	return Decision{Allowed: true}, nil
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.rule.Burst), b.tokens+elapsed*b.rule.Rate)
+	}
+	b.updated = now
+}
+
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
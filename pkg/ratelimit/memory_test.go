@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	rule := PerMinute(6, 3) // A token every 10 seconds
+
+	for i := 0; i < 3; i++ {
+		decision, err := limiter.Allow(ctx, "ip:1.2.3.4", rule)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed, "request %d is within the burst", i+1)
+	}
+
+	decision, err := limiter.Allow(ctx, "ip:1.2.3.4", rule)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 10*time.Second, decision.RetryAfter)
+
+	decision, err = limiter.Allow(ctx, "ip:5.6.7.8", rule)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "keys have their own bucket")
+
+	now = now.Add(4 * time.Second)
+	decision, err = limiter.Allow(ctx, "ip:1.2.3.4", rule)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 6*time.Second, decision.RetryAfter)
+
+	now = now.Add(6 * time.Second)
+	decision, err = limiter.Allow(ctx, "ip:1.2.3.4", rule)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "a token came back")
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	rule := PerMinute(60, 1)
+
+	_, err := limiter.Allow(ctx, "email:jane@example.com", rule)
+	require.NoError(t, err)
+	assert.Len(t, limiter.buckets, 1)
+
+	now = now.Add(2 * sweepInterval)
+	_, err = limiter.Allow(ctx, "email:john@example.com", rule)
+	require.NoError(t, err)
+	assert.Len(t, limiter.buckets, 1, "the refilled bucket was dropped")
+}
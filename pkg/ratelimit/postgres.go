@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+// PostgresLimiter keeps buckets in rate_limit_buckets so every replica draws from the same ones.
+type PostgresLimiter struct {
+	db db.Db
+}
+
+func NewPostgresLimiter(db db.Db) *PostgresLimiter {
+	return &PostgresLimiter{db: db}
+}
+
+func (l *PostgresLimiter) Allow(ctx context.Context, key string, rule Rule) (Decision, error) {
+	// Refill and take a token in one statement, concurrent requests queue on the row lock.
+	// Every SET expression sees the row as it was, so refilled means the same thing in each.
+	// The database clock is the only one all replicas agree on.
+	const refilled = `LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at))::float8 * $3::float8)`
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, TRUE, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = ` + refilled + ` - CASE WHEN ` + refilled + ` >= 1 THEN 1 ELSE 0 END,
+			allowed = ` + refilled + ` >= 1,
+			updated_at = NOW()
+		RETURNING tokens, allowed
+	`
+	var tokens float64
+	var allowed bool
+	err := l.db.GetDb().QueryRowContext(ctx, query, key, float64(rule.Burst), rule.Rate).Scan(&tokens, &allowed)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !allowed {
+		return Decision{RetryAfter: retryAfter(tokens, rule)}, nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// Start drops idle buckets in the background until ctx is done.
+func (l *PostgresLimiter) Start(ctx context.Context, idle time.Duration) {
+	go func() {
+		ticker := time.NewTicker(idle)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				query := `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => $1)`
+				if _, err := l.db.GetDb().ExecContext(ctx, query, idle.Seconds()); err != nil {
+					log.Printf("failed to purge rate limit buckets: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+// Package ratelimit throttles requests with token buckets: a key holds up to Burst tokens,
+// each request takes one and they come back at Rate per second.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/hfleury/bk_globalshot/pkg/db"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverPostgres = "postgres"
+)
+
+// Rule is the size of a bucket and how fast it refills.
+type Rule struct {
+	Burst int
+	Rate  float64 // Tokens per second
+}
+
+// PerMinute is a rule allowing burst requests at once and n a minute after that.
+func PerMinute(n, burst int) Rule {
+	return Rule{Burst: burst, Rate: float64(n) / 60}
+}
+
+// Decision is the outcome of a request against a bucket.
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration // When the next token is due, only set when not allowed
+}
+
+// Limiter takes a token from the bucket of a key. Drivers must be safe for concurrent use.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Decision, error)
+}
+
+// New builds the driver selected by cfg.Driver. The Postgres driver shares buckets between
+// replicas, the memory one is enough for a single instance.
+func New(cfg config.ConfigRateLimit, conn db.Db) (Limiter, error) {
+	switch cfg.Driver {
+	case DriverMemory, "":
+		return NewMemoryLimiter(), nil
+	case DriverPostgres:
+		return NewPostgresLimiter(conn), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit driver %q", cfg.Driver)
+	}
+}
+
+// retryAfter is how long a bucket holding tokens takes to get back to one.
+func retryAfter(tokens float64, rule Rule) time.Duration {
+	if rule.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	seconds := (1 - tokens) / rule.Rate
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -4,4 +4,16 @@ import "errors"
 
 var (
 	ErrEmailAlreadyExists = errors.New("email already exists")
+	// ErrVersionConflict is returned by versioned updates when the row changed since it was read.
+	ErrVersionConflict = errors.New("resource was modified since it was read")
 )
+
+// ReferenceError is returned when a record points to one that does not exist, Field being the
+// column pointing to it, such as site_id.
+type ReferenceError struct {
+	Field string
+}
+
+func (e *ReferenceError) Error() string {
+	return e.Field + " does not reference an existing record"
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hfleury/bk_globalshot/internal/model"
 	"github.com/hfleury/bk_globalshot/pkg/db"
+	"github.com/hfleury/bk_globalshot/pkg/listquery"
 )
 
 //go:generate mockgen -source=user_repo.go -destination=../../mock/repository/mock_user_repo.go -package=mock_repository
@@ -12,9 +13,15 @@ import (
 type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	Create(ctx context.Context, user *model.User) error
-	FindAll(ctx context.Context, limit, offset int) ([]*model.User, int64, error)
+	// FindAll lists users, of one company when companyID is set.
+	FindAll(ctx context.Context, query listquery.Query, companyID string) ([]*model.User, int64, error)
 	FindByID(ctx context.Context, id string) (*model.User, error)
+	// Update saves the email, role and company of the user if it is still at user.Version
+	// and bumps the version, ErrVersionConflict otherwise.
 	Update(ctx context.Context, user *model.User) error
-	Delete(ctx context.Context, id string) error
+	UpdateEmail(ctx context.Context, id, email string) error
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+	// Delete removes the user if it is still at version, ErrVersionConflict otherwise.
+	Delete(ctx context.Context, id string, version int64) error
 	WithTx(tx db.Db) UserRepository
 }
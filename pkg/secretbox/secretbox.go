@@ -0,0 +1,76 @@
+// Package secretbox seals secrets the API has to store and read back, such as the client
+// secrets of identity providers, with AES-256-GCM under a key from the configuration.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks sealed values, and the version of the format they are in.
+const prefix = "sb1:"
+
+var ErrOpen = errors.New("sealed value is corrupt or was sealed with another key")
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a box for the hex encoded 32 byte key.
+func New(hexKey string) (*Box, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid secret key: %d bytes, 32 expected", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts the value with a fresh nonce.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal. Values stored before sealing was introduced carry
+// no prefix, they are returned as they are until they are saved again.
+func (b *Box) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", ErrOpen
+	}
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrOpen
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether the value was returned by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,68 @@
+package secretbox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(testKey)
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("client-secret")
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "client-secret")
+
+	// Every seal has its own nonce
+	again, err := box.Seal("client-secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	opened, err := box.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "client-secret", opened)
+}
+
+func TestOpenRejections(t *testing.T) {
+	box, err := New(testKey)
+	require.NoError(t, err)
+	other, err := New(strings.Repeat("ff", 32))
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("client-secret")
+	require.NoError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrOpen, "Other key")
+
+	// Flip a character inside the ciphertext, the last one may only hold padding bits
+	i := len(prefix) + 20
+	flipped := byte('A')
+	if sealed[i] == 'A' {
+		flipped = 'B'
+	}
+	tampered := sealed[:i] + string(flipped) + sealed[i+1:]
+	_, err = box.Open(tampered)
+	assert.ErrorIs(t, err, ErrOpen, "Tampered")
+
+	_, err = box.Open(prefix + "!")
+	assert.ErrorIs(t, err, ErrOpen, "Not base64")
+
+	// Stored before sealing was introduced
+	opened, err := box.Open("legacy-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-secret", opened)
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	for _, key := range []string{"", "not-hex", strings.Repeat("ab", 16)} {
+		_, err := New(key)
+		assert.Error(t, err, key)
+	}
+}
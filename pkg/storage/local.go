@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LocalStorage keeps blobs as plain files under a root directory. Intended for development and tests.
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if root == "" {
+		return nil, errors.New("local storage root directory is required")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	dst := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return nil, err
+	}
+
+	// Write to a temp file first so a failed upload never leaves a partial object behind
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(tmp, &ctxReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	return s.Stat(ctx, key)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(s.path(info.Key))
+	if err != nil {
+		return nil, nil, mapLocalError(err)
+	}
+	return file, info, nil
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(s.path(key))
+	if err != nil {
+		return nil, mapLocalError(err)
+	}
+	if fi.IsDir() {
+		return nil, ErrNotFound
+	}
+	return s.objectInfo(key, fi), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	objects := make([]*ObjectInfo, 0)
+
+	// Only walk the directory the prefix points into
+	start := s.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir, err := cleanKey(prefix[:i])
+		if err != nil {
+			return nil, err
+		}
+		start = s.path(dir)
+	}
+	if _, err := os.Stat(start); errors.Is(err, fs.ErrNotExist) {
+		return objects, nil
+	}
+
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, s.objectInfo(key, fi))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func (s *LocalStorage) objectInfo(key string, fi fs.FileInfo) *ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         fi.Size(),
+		ContentType:  contentType,
+		LastModified: fi.ModTime(),
+	}
+}
+
+func mapLocalError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ctxReader stops a long copy as soon as the request context is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
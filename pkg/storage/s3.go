@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage talks to any S3-compatible object store (AWS S3, MinIO, R2, ...).
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Storage(cfg config.ConfigS3) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3 storage requires an endpoint and a bucket")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return &S3Storage{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	size := opts.Size
+	if size == 0 {
+		size = -1
+	}
+
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: opts.ContentType,
+	})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  opts.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, mapS3Error(err)
+	}
+
+	// GetObject is lazy, Stat forces the request so missing keys surface here
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, mapS3Error(err)
+	}
+	return obj, toObjectInfo(stat), nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	return toObjectInfo(stat), nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	// S3 deletes are idempotent, removing a missing key is not an error
+	return mapS3Error(s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}))
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	objects := make([]*ObjectInfo, 0)
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, mapS3Error(obj.Err)
+		}
+		objects = append(objects, toObjectInfo(obj))
+	}
+	return objects, nil
+}
+
+func toObjectInfo(obj minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          obj.Key,
+		Size:         obj.Size,
+		ContentType:  obj.ContentType,
+		LastModified: obj.LastModified,
+	}
+}
+
+func mapS3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// ObjectInfo describes a stored blob without its content.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+type PutOptions struct {
+	ContentType string
+	// Size of the content in bytes, or -1 when unknown and the driver must stream it.
+	Size int64
+}
+
+// Storage is a blob store addressed by slash separated keys (e.g. "rooms/<id>/<media>.jpg").
+// Readers returned by Get must be closed by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]*ObjectInfo, error)
+}
+
+// New builds the driver selected by cfg.Driver.
+func New(cfg config.ConfigStorage) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.LocalDir)
+	case DriverS3:
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// cleanKey normalizes a key and rejects anything that could escape the storage root.
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver runs the behaviour every driver must share.
+func testDriver(t *testing.T, s Storage) {
+	ctx := context.Background()
+	content := []byte("equirectangular-bytes")
+
+	t.Run("Put and Get round trip", func(t *testing.T) {
+		info, err := s.Put(ctx, "rooms/room-1/a.jpg", bytes.NewReader(content), PutOptions{ContentType: "image/jpeg", Size: -1})
+		require.NoError(t, err)
+		assert.Equal(t, "rooms/room-1/a.jpg", info.Key)
+		assert.Equal(t, int64(len(content)), info.Size)
+
+		r, info, err := s.Get(ctx, "rooms/room-1/a.jpg")
+		require.NoError(t, err)
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+	})
+
+	t.Run("Stat missing key", func(t *testing.T) {
+		_, err := s.Stat(ctx, "rooms/room-1/missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, _, err = s.Get(ctx, "rooms/room-1/missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("List by prefix", func(t *testing.T) {
+		_, err := s.Put(ctx, "rooms/room-1/b.jpg", bytes.NewReader(content), PutOptions{ContentType: "image/jpeg", Size: int64(len(content))})
+		require.NoError(t, err)
+		_, err = s.Put(ctx, "rooms/room-2/c.jpg", bytes.NewReader(content), PutOptions{ContentType: "image/jpeg", Size: int64(len(content))})
+		require.NoError(t, err)
+
+		objects, err := s.List(ctx, "rooms/room-1/")
+		require.NoError(t, err)
+		keys := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, []string{"rooms/room-1/a.jpg", "rooms/room-1/b.jpg"}, keys)
+
+		objects, err = s.List(ctx, "rooms/room-404/")
+		require.NoError(t, err)
+		assert.Empty(t, objects)
+	})
+
+	t.Run("Delete is idempotent", func(t *testing.T) {
+		require.NoError(t, s.Delete(ctx, "rooms/room-1/a.jpg"))
+		require.NoError(t, s.Delete(ctx, "rooms/room-1/a.jpg"))
+
+		_, err := s.Stat(ctx, "rooms/room-1/a.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Rejects keys escaping the root", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../secret", "rooms/../../secret"} {
+			_, err := s.Put(ctx, key, strings.NewReader("x"), PutOptions{Size: 1})
+			assert.ErrorIs(t, err, ErrInvalidKey, key)
+		}
+	})
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	testDriver(t, s)
+}
+
+// TestS3Storage runs against a local S3 stand-in (e.g. the minio service in docker-compose.yml)
+// when STORAGE_TEST_S3_ENDPOINT is set. The bucket must already exist.
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("STORAGE_TEST_S3_ENDPOINT not set")
+	}
+
+	s, err := NewS3Storage(config.ConfigS3{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("STORAGE_TEST_S3_BUCKET"),
+		AccessKey: os.Getenv("STORAGE_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("STORAGE_TEST_S3_SECRET_KEY"),
+		PathStyle: true,
+	})
+	require.NoError(t, err)
+
+	testDriver(t, s)
+}
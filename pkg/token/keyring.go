@@ -0,0 +1,180 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	paseto "aidanwoods.dev/go-paseto"
+)
+
+var (
+	ErrKeyNotFound     = errors.New("signing key not found")
+	ErrKeyActive       = errors.New("the active signing key cannot be retired")
+	ErrKeyRetired      = errors.New("signing key is retired")
+	ErrNoActiveKey     = errors.New("key ring has no active signing key")
+	ErrUnknownKeyID    = errors.New("token signed with an unknown key")
+	ErrMalformedFooter = errors.New("malformed token footer")
+)
+
+// Key is a PASETO v4 signing key of a key ring. Retired keys are kept for the record
+// but no longer verify tokens.
+type Key struct {
+	ID         string     `json:"kid"`
+	PrivateKey string     `json:"private_key"` // Hex, the public half is derived from it
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+func (k Key) IsRetired() bool {
+	return k.RetiredAt != nil
+}
+
+// KeyRing is the set of keys tokens are verified with and the one new tokens are signed with.
+// Rotating means adding a key, making it active, and retiring the previous one once the tokens
+// it signed have expired, nobody gets signed out on the way.
+type KeyRing struct {
+	ActiveID string `json:"active_kid"`
+	Keys     []Key  `json:"keys"`
+}
+
+// KeyID derives the id of a key from its public half, so the same key always gets the same id.
+func KeyID(privateKey paseto.V4AsymmetricSecretKey) string {
+	sum := sha256.Sum256(privateKey.Public().ExportBytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// SingleKeyRing wraps a lone hex private key, as configured before key rings existed.
+func SingleKeyRing(privateKeyHex string) (*KeyRing, error) {
+	privateKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	kid := KeyID(privateKey)
+	return &KeyRing{ActiveID: kid, Keys: []Key{{ID: kid, PrivateKey: privateKeyHex}}}, nil
+}
+
+// LoadKeyRing reads a key ring file. A missing file is an empty ring.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &KeyRing{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key ring: %w", err)
+	}
+
+	ring := &KeyRing{}
+	if err := json.Unmarshal(data, ring); err != nil {
+		return nil, fmt.Errorf("failed to parse key ring: %w", err)
+	}
+	return ring, nil
+}
+
+// Save writes the ring readable by its owner only. The file is replaced atomically so a
+// server starting meanwhile never reads half of it.
+func (r *KeyRing) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keyring-*")
+	if err != nil {
+		return fmt.Errorf("failed to write key ring: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write key ring: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Generate adds a new key and returns it, activate makes it the signing key straight away.
+// Leave it inactive when several instances share the ring: once all of them accept the key
+// it can be activated without tokens failing on instances that have not reloaded yet.
+func (r *KeyRing) Generate(activate bool) Key {
+	privateKey := paseto.NewV4AsymmetricSecretKey()
+	key := Key{
+		ID:         KeyID(privateKey),
+		PrivateKey: privateKey.ExportHex(),
+		CreatedAt:  time.Now().UTC(),
+	}
+	r.Keys = append(r.Keys, key)
+	if activate {
+		r.ActiveID = key.ID
+	}
+	return key
+}
+
+// Import adds an existing hex private key, such as a TOKEN_PRIVATE_KEY being moved into a ring
+// so the tokens it signed stay valid. It becomes active if the ring had no active key.
+func (r *KeyRing) Import(privateKeyHex string) (Key, error) {
+	privateKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(privateKeyHex)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	kid := KeyID(privateKey)
+	if existing := r.find(kid); existing != nil {
+		return *existing, nil
+	}
+
+	key := Key{ID: kid, PrivateKey: privateKeyHex, CreatedAt: time.Now().UTC()}
+	r.Keys = append(r.Keys, key)
+	if r.ActiveID == "" {
+		r.ActiveID = kid
+	}
+	return key, nil
+}
+
+// Activate makes a key the one new tokens are signed with.
+func (r *KeyRing) Activate(kid string) error {
+	key := r.find(kid)
+	if key == nil {
+		return ErrKeyNotFound
+	}
+	if key.IsRetired() {
+		return ErrKeyRetired
+	}
+	r.ActiveID = kid
+	return nil
+}
+
+// Retire stops a key from verifying tokens, every token it signed is rejected from then on.
+func (r *KeyRing) Retire(kid string) error {
+	key := r.find(kid)
+	if key == nil {
+		return ErrKeyNotFound
+	}
+	if kid == r.ActiveID {
+		return ErrKeyActive
+	}
+	if !key.IsRetired() {
+		now := time.Now().UTC()
+		key.RetiredAt = &now
+	}
+	return nil
+}
+
+func (r *KeyRing) find(kid string) *Key {
+	for i := range r.Keys {
+		if r.Keys[i].ID == kid {
+			return &r.Keys[i]
+		}
+	}
+	return nil
+}
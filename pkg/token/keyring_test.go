@@ -0,0 +1,95 @@
+package token
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	paseto "aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRingRotation(t *testing.T) {
+	ring := &KeyRing{}
+	oldKey := ring.Generate(true)
+
+	oldMaker, err := NewPasetoMakerFromKeyRing(ring)
+	require.NoError(t, err)
+	oldToken, err := oldMaker.CreateToken(&Payload{UserID: "user-1", Email: "a@b.c", Role: "admin"}, time.Minute)
+	require.NoError(t, err)
+
+	footer, err := paseto.NewParser().UnsafeParseFooter(paseto.V4Public, oldToken)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kid":"`+oldKey.ID+`"}`, string(footer))
+
+	// A new active key still accepts what the previous one signed
+	newKey := ring.Generate(true)
+	rotated, err := NewPasetoMakerFromKeyRing(ring)
+	require.NoError(t, err)
+
+	payload, err := rotated.VerifyToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", payload.UserID)
+
+	newToken, err := rotated.CreateToken(&Payload{UserID: "user-2", Email: "a@b.c", Role: "admin"}, time.Minute)
+	require.NoError(t, err)
+	footer, err = paseto.NewParser().UnsafeParseFooter(paseto.V4Public, newToken)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kid":"`+newKey.ID+`"}`, string(footer))
+
+	// Once retired the previous key signs nobody in
+	assert.ErrorIs(t, ring.Retire(newKey.ID), ErrKeyActive)
+	require.NoError(t, ring.Retire(oldKey.ID))
+	retired, err := NewPasetoMakerFromKeyRing(ring)
+	require.NoError(t, err)
+
+	_, err = retired.VerifyToken(oldToken)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+	_, err = retired.VerifyToken(newToken)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, ring.Activate(oldKey.ID), ErrKeyRetired)
+}
+
+func TestKeyRingAcceptsTokensWithoutKeyID(t *testing.T) {
+	privateKey := paseto.NewV4AsymmetricSecretKey()
+
+	// Signed the way tokens were before key ids
+	legacy := paseto.NewToken()
+	legacy.SetExpiration(time.Now().Add(time.Minute))
+	legacy.SetIssuedAt(time.Now())
+	legacy.SetString("user_id", "user-1")
+	legacy.SetString("email", "a@b.c")
+	legacy.SetString("role", "admin")
+	signed := legacy.V4Sign(privateKey, nil)
+
+	ring := &KeyRing{}
+	_, err := ring.Import(privateKey.ExportHex())
+	require.NoError(t, err)
+	ring.Generate(true)
+
+	maker, err := NewPasetoMakerFromKeyRing(ring)
+	require.NoError(t, err)
+	payload, err := maker.VerifyToken(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", payload.UserID)
+}
+
+func TestKeyRingSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+
+	empty, err := LoadKeyRing(path)
+	require.NoError(t, err)
+	_, err = NewPasetoMakerFromKeyRing(empty)
+	assert.ErrorIs(t, err, ErrNoActiveKey)
+
+	ring := &KeyRing{}
+	key := ring.Generate(true)
+	ring.Generate(false)
+	require.NoError(t, ring.Save(path))
+
+	loaded, err := LoadKeyRing(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, loaded.ActiveID)
+	assert.Len(t, loaded.Keys, 2)
+}
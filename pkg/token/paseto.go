@@ -1,30 +1,60 @@
 package token
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	paseto "aidanwoods.dev/go-paseto"
 )
 
+// PasetoMaker signs with the active key of a key ring and verifies with any key of the ring
+// that is not retired. The key id travels in the token footer.
 type PasetoMaker struct {
-	privateKey   paseto.V4AsymmetricSecretKey
-	PublicKeyHex paseto.V4AsymmetricPublicKey
+	kid        string
+	privateKey paseto.V4AsymmetricSecretKey
+	publicKeys map[string]paseto.V4AsymmetricPublicKey
 }
 
+// footer is the unencrypted, but signed, part of a token naming the key it was signed with.
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
+// NewPasetoMaker signs and verifies with a single hex private key.
 func NewPasetoMaker(privateKeyHex string) (Maker, error) {
-	privateKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(privateKeyHex)
+	ring, err := SingleKeyRing(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
+	}
+	return NewPasetoMakerFromKeyRing(ring)
+}
+
+func NewPasetoMakerFromKeyRing(ring *KeyRing) (Maker, error) {
+	maker := &PasetoMaker{publicKeys: make(map[string]paseto.V4AsymmetricPublicKey)}
+
+	for _, key := range ring.Keys {
+		if key.IsRetired() {
+			continue
+		}
+		privateKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(key.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key %s: %w", key.ID, err)
+		}
+		maker.publicKeys[key.ID] = privateKey.Public()
+		if key.ID == ring.ActiveID {
+			maker.kid = key.ID
+			maker.privateKey = privateKey
+		}
 	}
 
-	return &PasetoMaker{
-		privateKey:   privateKey,
-		PublicKeyHex: privateKey.Public(),
-	}, nil
+	if maker.kid == "" {
+		return nil, ErrNoActiveKey
+	}
+	return maker, nil
 }
 
-func (m *PasetoMaker) CreateToken(userID string, email string, role string, companyID string, duration time.Duration) (string, error) {
+func (m *PasetoMaker) CreateToken(payload *Payload, duration time.Duration) (string, error) {
 	now := time.Now()
 	exp := now.Add(duration)
 
@@ -32,10 +62,25 @@ func (m *PasetoMaker) CreateToken(userID string, email string, role string, comp
 	token.SetIssuedAt(now)
 	token.SetNotBefore(now)
 	token.SetExpiration(exp)
-	token.SetString("user_id", userID)
-	token.SetString("email", email)
-	token.SetString("role", role)
-	token.SetString("company_id", companyID)
+	token.SetString("user_id", payload.UserID)
+	token.SetString("email", payload.Email)
+	token.SetString("role", payload.Role)
+	token.SetString("company_id", payload.CompanyID)
+	token.SetString("session_id", payload.SessionID)
+	if payload.ImpersonatorID != "" {
+		token.SetString("impersonator_id", payload.ImpersonatorID)
+		token.SetString("impersonator_email", payload.ImpersonatorEmail)
+		token.SetString("impersonation_id", payload.ImpersonationID)
+	}
+
+	payload.IssuedAt = now
+	payload.ExpiresAt = exp
+
+	footer, err := json.Marshal(footer{KeyID: m.kid})
+	if err != nil {
+		return "", err
+	}
+	token.SetFooter(footer)
 
 	signedToken := token.V4Sign(m.privateKey, nil)
 	return signedToken, nil
@@ -45,7 +90,7 @@ func (m *PasetoMaker) VerifyToken(tokenString string) (*Payload, error) {
 	parser := paseto.NewParser()
 	parser.AddRule(paseto.NotExpired())
 
-	parsedToken, err := parser.ParseV4Public(m.PublicKeyHex, tokenString, nil)
+	parsedToken, err := m.parse(parser, tokenString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
@@ -66,6 +111,11 @@ func (m *PasetoMaker) VerifyToken(tokenString string) (*Payload, error) {
 	}
 
 	companyID, _ := parsedToken.GetString("company_id") // Optional
+	sessionID, _ := parsedToken.GetString("session_id") // Absent on tokens minted before sessions existed
+	// Only on impersonation tokens
+	impersonatorID, _ := parsedToken.GetString("impersonator_id")
+	impersonatorEmail, _ := parsedToken.GetString("impersonator_email")
+	impersonationID, _ := parsedToken.GetString("impersonation_id")
 
 	issuedAt, err := parsedToken.GetIssuedAt()
 	if err != nil {
@@ -82,9 +132,44 @@ func (m *PasetoMaker) VerifyToken(tokenString string) (*Payload, error) {
 		Email:     email,
 		Role:      role,
 		CompanyID: companyID,
+		SessionID: sessionID,
 		IssuedAt:  issuedAt,
 		ExpiresAt: expiration,
+
+		ImpersonatorID:    impersonatorID,
+		ImpersonatorEmail: impersonatorEmail,
+		ImpersonationID:   impersonationID,
 	}
 
 	return payload, nil
 }
+
+// parse verifies the token with the key its footer names. Tokens without a footer predate
+// key ids and are tried against every key.
+func (m *PasetoMaker) parse(parser paseto.Parser, tokenString string) (*paseto.Token, error) {
+	raw, err := parser.UnsafeParseFooter(paseto.V4Public, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) == 0 {
+		err = ErrUnknownKeyID
+		for _, publicKey := range m.publicKeys {
+			var parsedToken *paseto.Token
+			if parsedToken, err = parser.ParseV4Public(publicKey, tokenString, nil); err == nil {
+				return parsedToken, nil
+			}
+		}
+		return nil, err
+	}
+
+	var f footer
+	if err := json.Unmarshal(raw, &f); err != nil || f.KeyID == "" {
+		return nil, ErrMalformedFooter
+	}
+	publicKey, ok := m.publicKeys[f.KeyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return parser.ParseV4Public(publicKey, tokenString, nil)
+}
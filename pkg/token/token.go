@@ -1,19 +1,52 @@
 package token
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/hfleury/bk_globalshot/pkg/config"
 )
 
 type Maker interface {
-	CreateToken(userID string, email string, role string, companyID string, duration time.Duration) (string, error)
+	// CreateToken signs the identity in payload for the given duration. IssuedAt and ExpiresAt
+	// are set on payload so callers can report the expiry.
+	CreateToken(payload *Payload, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
 
 type Payload struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CompanyID string    `json:"company_id"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	CompanyID string `json:"company_id"`
+	SessionID string `json:"session_id"` // auth_tokens row the token was issued for
+	// APIKeyID and Scopes are set instead of SessionID when the caller used an API key,
+	// such payloads are never signed into tokens.
+	APIKeyID string   `json:"api_key_id,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// Impersonator* are set when an admin acts as UserID for support, the token then belongs
+	// to the session of the admin and the impersonation it was issued for.
+	ImpersonatorID    string    `json:"impersonator_id,omitempty"`
+	ImpersonatorEmail string    `json:"impersonator_email,omitempty"`
+	ImpersonationID   string    `json:"impersonation_id,omitempty"`
+	IssuedAt          time.Time `json:"issued_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// New builds the maker from the key ring file when one is configured, from the single
+// TokenKey otherwise. The ring is read once, restart after changing it.
+func New(cfg config.ConfigToken) (Maker, error) {
+	if cfg.KeyRingFile == "" {
+		return NewPasetoMaker(cfg.TokenKey)
+	}
+
+	ring, err := LoadKeyRing(cfg.KeyRingFile)
+	if err != nil {
+		return nil, err
+	}
+	maker, err := NewPasetoMakerFromKeyRing(ring)
+	if err != nil {
+		return nil, fmt.Errorf("key ring %s: %w", cfg.KeyRingFile, err)
+	}
+	return maker, nil
 }
@@ -0,0 +1,69 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SHA1 secret of the RFC 6238 test vectors, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to the 6 digits authenticator apps show
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "at %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok = Validate(secret, code, now.Add(Period))
+	assert.True(t, ok, "a code from the previous step is still accepted")
+
+	_, ok = Validate(secret, code, now.Add(3*Period))
+	assert.False(t, ok, "a code older than the skew is rejected")
+
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok)
+
+	_, ok = Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("GlobalShot", "admin@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/GlobalShot:admin@example.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "GlobalShot", u.Query().Get("issuer"))
+}